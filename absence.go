@@ -0,0 +1,521 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+type AbsenceType string
+
+const (
+	AbsenceVacation AbsenceType = "vacation"
+	AbsenceSick     AbsenceType = "sick"
+	AbsenceHoliday  AbsenceType = "public_holiday"
+)
+
+// Absence is a day, or part of a day, off work. Absences reduce the expected time of the work schedule
+type Absence struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	OrganizationID uint           `gorm:"index" json:"organization_id"` // 0 applies to every organization
+	Date           string         `gorm:"index" json:"date"`
+	Type           AbsenceType    `json:"type"`
+	Name           string         `json:"name"`    // e.g. the name of a holiday
+	Minutes        int            `json:"minutes"` // 0 for a full day
+	Source         string         `json:"source"`  // calendar file a holiday was imported from
+}
+
+func (a Absence) auditInfo() (string, uint, uint, string) {
+	return "absence", a.ID, 0, a.Date
+}
+
+// LeaveAllowance is the number of days of an absence type an organization grants in a year
+type LeaveAllowance struct {
+	ID             uint        `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	OrganizationID uint        `gorm:"uniqueIndex:idx_leave_allowance" json:"organization_id"`
+	Year           int         `gorm:"uniqueIndex:idx_leave_allowance" json:"year"`
+	Type           AbsenceType `gorm:"uniqueIndex:idx_leave_allowance" json:"type"`
+	Days           float64     `json:"days"`
+	CarryOverDays  float64     `json:"carry_over_days"` // days left over from the previous year
+}
+
+// LeaveBalance is the allowance of an absence type in a year against the days taken
+type LeaveBalance struct {
+	Type          AbsenceType `json:"type"`
+	Year          int         `json:"year"`
+	AllowanceDays float64     `json:"allowance_days"`
+	CarryOverDays float64     `json:"carry_over_days"`
+	UsedDays      float64     `json:"used_days"`
+	RemainingDays float64     `json:"remaining_days"`
+}
+
+func validateAbsence(absence Absence) error {
+	switch absence.Type {
+	case AbsenceVacation, AbsenceSick, AbsenceHoliday:
+	default:
+		return fmt.Errorf("invalid absence type %q", absence.Type)
+	}
+	if _, err := time.Parse("2006-01-02", absence.Date); err != nil {
+		return fmt.Errorf("invalid date %q", absence.Date)
+	}
+	if absence.Minutes < 0 || absence.Minutes > 24*60 {
+		return errors.New("absence minutes must be between 0 and 1440")
+	}
+	return nil
+}
+
+// absenceScope matches the absences of an organization, including those that apply to every organization
+func absenceScope(organizationID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationID == 0 {
+			return db
+		}
+		return db.Where("organization_id IN ?", []uint{0, organizationID})
+	}
+}
+
+func (a *App) getAbsences(organizationID uint, startDate, endDate string) ([]Absence, error) {
+	var absences []Absence
+	err := a.db.Scopes(absenceScope(organizationID)).
+		Where("date >= ? AND date <= ?", startDate, endDate).
+		Order("date, id").
+		Find(&absences).Error
+	return absences, err
+}
+
+// GetAbsences returns the absences between two dates (inclusive) that apply to the organization,
+// or all absences when organizationID is 0
+func (a *App) GetAbsences(organizationID uint, startDate, endDate string) ([]Absence, error) {
+	absences, err := a.getAbsences(organizationID, startDate, endDate)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return absences, nil
+}
+
+// AddAbsence records an absence on every day between two dates (inclusive) that the organization's
+// schedule expects work on, a single day is always recorded. Days that already have an absence of
+// the same type are skipped
+func (a *App) AddAbsence(organizationID uint, startDate, endDate string, absenceType AbsenceType, name string, minutes int) ([]Absence, error) {
+	if organizationID != 0 {
+		if _, err := a.getOrganization(organizationID); err != nil {
+			return nil, err
+		}
+	}
+	from, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, validationError(fmt.Sprintf("invalid start date %q", startDate))
+	}
+	to, err := time.Parse("2006-01-02", endDate)
+	if err != nil || to.Before(from) {
+		return nil, validationError(fmt.Sprintf("invalid end date %q", endDate))
+	}
+	schedule, err := a.getWorkSchedule(organizationID)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	existing, err := a.absenceDates(organizationID, absenceType, startDate, endDate)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+
+	var absences []Absence
+	scheduled := schedule.weekdayMinutes()
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if existing[date] || (!from.Equal(to) && scheduled[day.Weekday()] == 0) {
+			continue
+		}
+		absence := Absence{OrganizationID: organizationID, Date: date, Type: absenceType, Name: strings.TrimSpace(name), Minutes: minutes}
+		if err := validateAbsence(absence); err != nil {
+			return nil, invalid(err)
+		}
+		absences = append(absences, absence)
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		for i := range absences {
+			if err := ensureOrganizationUnlocked(tx, organizationID, absences[i].Date); err != nil {
+				return err
+			}
+			if err := insertAudited(tx, AuditManual, &absences[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return absences, nil
+}
+
+// DeleteAbsence removes an absence
+func (a *App) DeleteAbsence(absenceID uint) error {
+	var absence Absence
+	if err := a.db.First(&absence, absenceID).Error; err != nil {
+		return dbError(err, "absence", absenceID)
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureOrganizationUnlocked(tx, absence.OrganizationID, absence.Date); err != nil {
+			return err
+		}
+		return deleteAudited[Absence](tx, AuditManual, []uint{absence.ID})
+	})
+	if err != nil {
+		return toAppError(err)
+	}
+	return nil
+}
+
+// absenceDates returns the dates that already have an absence of the type for exactly this organization
+func (a *App) absenceDates(organizationID uint, absenceType AbsenceType, startDate, endDate string) (map[string]bool, error) {
+	var dates []string
+	err := a.db.Model(&Absence{}).
+		Where("organization_id = ? AND type = ? AND date >= ? AND date <= ?", organizationID, absenceType, startDate, endDate).
+		Pluck("date", &dates).Error
+	existing := make(map[string]bool)
+	for _, date := range dates {
+		existing[date] = true
+	}
+	return existing, err
+}
+
+// ImportHolidays lets the user pick an ICS calendar and records its events as public holidays
+// of the organization, or of every organization when organizationID is 0
+func (a *App) ImportHolidays(organizationID uint) (int, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select holiday calendar",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Calendars (*.ics)", Pattern: "*.ics"},
+		},
+	})
+	if err != nil || path == "" {
+		return 0, err
+	}
+	if organizationID != 0 {
+		if _, err := a.getOrganization(organizationID); err != nil {
+			return 0, err
+		}
+	}
+	imported, err := a.importHolidays(path, organizationID)
+	if err != nil {
+		Logger.Println(err)
+		return 0, toAppError(err)
+	}
+	return imported, nil
+}
+
+func (a *App) importHolidays(path string, organizationID uint) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	events, err := parseICSEvents(file)
+	if err != nil {
+		return 0, invalid(err)
+	}
+
+	imported := 0
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			for _, date := range event.dates() {
+				var count int64
+				err := tx.Model(&Absence{}).
+					Where("organization_id = ? AND type = ? AND date = ?", organizationID, AbsenceHoliday, date).
+					Count(&count).Error
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					continue
+				}
+				// Holidays in locked periods are skipped like duplicates, the import shouldn't fail on them
+				if err := ensureOrganizationUnlocked(tx, organizationID, date); err != nil {
+					var appErr *AppError
+					if errors.As(err, &appErr) && appErr.Code == ErrLocked {
+						continue
+					}
+					return err
+				}
+				absence := Absence{OrganizationID: organizationID, Date: date, Type: AbsenceHoliday, Name: event.summary, Source: filepath.Base(path)}
+				if err := insertAudited(tx, AuditImport, &absence); err != nil {
+					return err
+				}
+				imported++
+			}
+		}
+		return nil
+	})
+	return imported, err
+}
+
+// icsEvent is the part of a calendar event needed for holidays, end is exclusive like DTEND
+type icsEvent struct {
+	summary string
+	start   time.Time
+	end     time.Time
+}
+
+func (e icsEvent) dates() []string {
+	var dates []string
+	for day := e.start; day.Before(e.end); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// parseICSEvents reads the all-day and timed events of an iCalendar file. Timed events cover the
+// day they start on and recurrence rules are ignored, holiday calendars list every occurrence
+func parseICSEvents(r io.Reader) ([]icsEvent, error) {
+	// Unfold continuation lines, they start with a space or a tab
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []icsEvent
+	var event *icsEvent
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(strings.ToUpper(name), ";")
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &icsEvent{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			if event.start.IsZero() {
+				return nil, errors.New("calendar event without a start date")
+			}
+			if !event.end.After(event.start) {
+				event.end = event.start.AddDate(0, 0, 1)
+			}
+			events = append(events, *event)
+			event = nil
+		case event == nil:
+		case name == "SUMMARY":
+			event.summary = unescapeICSText(value)
+		case name == "DTSTART", name == "DTEND":
+			if len(value) < 8 {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			date, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			if name == "DTSTART" {
+				event.start = date
+			} else if strings.Contains(params, "VALUE=DATE") || len(value) == 8 {
+				event.end = date // only all-day events span several days
+			}
+		}
+	}
+	if len(events) == 0 {
+		return nil, errors.New("no events found in the calendar")
+	}
+	return events, nil
+}
+
+func unescapeICSText(text string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(text)
+}
+
+func validateLeaveAllowance(allowance LeaveAllowance) error {
+	if allowance.Type != AbsenceVacation && allowance.Type != AbsenceSick {
+		return fmt.Errorf("allowances are kept for vacation and sick days, not %q", allowance.Type)
+	}
+	if allowance.Year < 1970 || allowance.Year > 9999 {
+		return fmt.Errorf("invalid year %d", allowance.Year)
+	}
+	if allowance.Days < 0 || allowance.CarryOverDays < 0 {
+		return errors.New("allowance days can't be negative")
+	}
+	return nil
+}
+
+func (a *App) getLeaveAllowance(organizationID uint, year int, absenceType AbsenceType) (LeaveAllowance, error) {
+	var allowance LeaveAllowance
+	err := a.db.Where("organization_id = ? AND year = ? AND type = ?", organizationID, year, absenceType).First(&allowance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return LeaveAllowance{OrganizationID: organizationID, Year: year, Type: absenceType}, nil
+	}
+	return allowance, err
+}
+
+// SaveLeaveAllowance stores the yearly allowance of an absence type for an organization
+func (a *App) SaveLeaveAllowance(allowance LeaveAllowance) (LeaveAllowance, error) {
+	if allowance.OrganizationID != 0 {
+		if _, err := a.getOrganization(allowance.OrganizationID); err != nil {
+			return LeaveAllowance{}, err
+		}
+	}
+	if err := validateLeaveAllowance(allowance); err != nil {
+		return LeaveAllowance{}, invalid(err)
+	}
+
+	existing, err := a.getLeaveAllowance(allowance.OrganizationID, allowance.Year, allowance.Type)
+	if err != nil {
+		return LeaveAllowance{}, toAppError(err)
+	}
+	allowance.ID = existing.ID
+	allowance.CreatedAt = existing.CreatedAt
+	if err := a.db.Save(&allowance).Error; err != nil {
+		return LeaveAllowance{}, toAppError(err)
+	}
+	return allowance, nil
+}
+
+// GetLeaveBalances returns the vacation and sick day balances of an organization for a year.
+// Partial days count as the share of the day's scheduled time they cover
+func (a *App) GetLeaveBalances(organizationID uint, year int) ([]LeaveBalance, error) {
+	balances, err := a.getLeaveBalances(organizationID, year)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return balances, nil
+}
+
+func (a *App) getLeaveBalances(organizationID uint, year int) ([]LeaveBalance, error) {
+	schedule, err := a.getWorkSchedule(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := a.getAbsences(organizationID, fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year))
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []LeaveBalance
+	for _, absenceType := range []AbsenceType{AbsenceVacation, AbsenceSick} {
+		allowance, err := a.getLeaveAllowance(organizationID, year, absenceType)
+		if err != nil {
+			return nil, err
+		}
+		balance := LeaveBalance{Type: absenceType, Year: year, AllowanceDays: allowance.Days, CarryOverDays: allowance.CarryOverDays}
+		for _, absence := range absences {
+			if absence.Type == absenceType {
+				balance.UsedDays += absenceDays(schedule, absence)
+			}
+		}
+		balance.RemainingDays = balance.AllowanceDays + balance.CarryOverDays - balance.UsedDays
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// absenceDays returns how much of a day an absence takes, partial days are measured against the scheduled time
+func absenceDays(schedule WorkSchedule, absence Absence) float64 {
+	if absence.Minutes == 0 {
+		return 1
+	}
+	day, _ := time.Parse("2006-01-02", absence.Date)
+	scheduled := schedule.weekdayMinutes()[day.Weekday()]
+	if scheduled == 0 {
+		scheduled = 8 * 60
+	}
+	return min(float64(absence.Minutes)/float64(scheduled), 1)
+}
+
+// absenceSeconds returns the scheduled seconds of each day covered by absences
+func (a *App) absenceSeconds(schedule WorkSchedule, startDate, endDate string) (map[string]int, error) {
+	absences, err := a.getAbsences(schedule.OrganizationID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	minutes := schedule.weekdayMinutes()
+	covered := make(map[string]int)
+	for _, absence := range absences {
+		day, err := time.Parse("2006-01-02", absence.Date)
+		if err != nil {
+			return nil, err
+		}
+		scheduled := minutes[day.Weekday()] * 60
+		if absence.Minutes == 0 {
+			covered[absence.Date] = scheduled
+		} else {
+			covered[absence.Date] = min(covered[absence.Date]+absence.Minutes*60, scheduled)
+		}
+	}
+	return covered, nil
+}
+
+// absenceHours formats the length of an absence for reports
+func absenceHours(absence Absence) string {
+	if absence.Minutes == 0 {
+		return "Full day"
+	}
+	return fmt.Sprintf("%.2f", secondsToHours(absence.Minutes*60))
+}
+
+var absenceTypeNames = map[AbsenceType]string{
+	AbsenceVacation: "Vacation",
+	AbsenceSick:     "Sick",
+	AbsenceHoliday:  "Public holiday",
+}
+
+// absenceCells returns the date, type, name and length of an absence for report rows
+func absenceCells(absence Absence) []string {
+	return []string{absence.Date, absenceTypeNames[absence.Type], absence.Name, absenceHours(absence)}
+}
+
+// leaveCells returns the cells of a leave balance for report rows
+func leaveCells(balance LeaveBalance) []string {
+	return []string{
+		absenceTypeNames[balance.Type],
+		fmt.Sprintf("%.1f", balance.AllowanceDays),
+		fmt.Sprintf("%.1f", balance.CarryOverDays),
+		fmt.Sprintf("%.1f", balance.UsedDays),
+		fmt.Sprintf("%.1f", balance.RemainingDays),
+	}
+}
+
+// scopeAbsences returns the absences in an organization report, other reports have none
+func (a *App) scopeAbsences(scope reportScope, startDate, endDate string) ([]Absence, error) {
+	if scope.organizationID == 0 || scope.kind != "organization" {
+		return nil, nil
+	}
+	return a.getAbsences(scope.organizationID, startDate, endDate)
+}
+
+// scopeLeave returns the leave balances in an organization report, only once an allowance was set or leave taken
+func (a *App) scopeLeave(scope reportScope, year int) ([]LeaveBalance, error) {
+	if scope.organizationID == 0 || scope.kind != "organization" {
+		return nil, nil
+	}
+	balances, err := a.getLeaveBalances(scope.organizationID, year)
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range balances {
+		if balance.AllowanceDays > 0 || balance.CarryOverDays > 0 || balance.UsedDays > 0 {
+			return balances, nil
+		}
+	}
+	return nil, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/theBGuy/go-work-tracker/auto_update"
@@ -17,14 +18,28 @@ import (
 type App struct {
 	ctx                context.Context
 	db                 *gorm.DB
+	dbMu               sync.RWMutex // read by the background work for a tick, written while db is swapped, see withDb
 	startTime          time.Time
-	lastSave           time.Time
+	saveDir            string // data directory holding the workspaces, see getSaveDir
+	workspaceName      string
+	dbDir              string          // directory of the active workspace
+	store              *encryptedStore // set while the database is encrypted, see encryption.go
+	openErr            error           // why the database couldn't be opened at startup
+	routinesStarted    bool
+	session            WorkSession    // session of the running timer, saved along with its hours
+	location           *time.Location // reporting timezone of the running timer's organization
 	isRunning          bool
 	organization       Organization
 	project            Project
+	task               Task // task the timer tracks within the project, zero for none
+	timerStack         []TimerFrame
+	parallel           map[uint]*parallelTimer // parallel timers by project ID
+	parallelMu         sync.Mutex
+	complianceWarned   map[string]bool // violations already warned about, see checkCompliance
 	version            string
 	environment        string
 	newVersonAvailable bool
+	settings           Settings
 }
 
 var ctx context.Context
@@ -51,11 +66,12 @@ func NewApp() *App {
 		environment, _ = readEnvConfig(WailsConfigFile)
 	}
 
-	dbDir, err := getSaveDir(environment)
+	saveDir, err := getSaveDir(environment)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println("Starting Go Work Tracker. \nVersion: ", version, "\nEnvironment: ", environment, "\nSave directory: ", dbDir)
+	workspace := activeWorkspace(saveDir)
+	fmt.Println("Starting Go Work Tracker. \nVersion: ", version, "\nEnvironment: ", environment, "\nSave directory: ", saveDir, "\nWorkspace: ", workspace)
 
 	// Check for updates
 	var newVersonAvailable bool
@@ -63,14 +79,21 @@ func NewApp() *App {
 		newVersonAvailable = auto_update.Run(version)
 	}
 
-	db := NewDb(dbDir)
-
-	return &App{
-		db:                 db,
+	app := &App{
+		saveDir:            saveDir,
+		workspaceName:      workspace,
+		dbDir:              workspaceDir(saveDir, workspace),
 		version:            version,
 		environment:        environment,
 		newVersonAvailable: newVersonAvailable,
 	}
+	// An encrypted database stays closed until the frontend unlocks it
+	if err := app.openDatabase(""); err != nil {
+		Logger.Println("Database not opened:", err)
+		app.openErr = err
+	}
+
+	return app
 }
 
 func (a *App) GetVersion() string {
@@ -84,6 +107,7 @@ func (a *App) UpdateAvailable() bool {
 type ActiveTimer struct {
 	Organization Organization `json:"organization"`
 	Project      Project      `json:"project"`
+	Task         Task         `json:"task"`
 	IsRunning    bool         `json:"isRunning"`
 	TimeElapsed  int          `json:"timeElapsed"`
 }
@@ -92,6 +116,7 @@ func (a *App) GetActiveTimer() ActiveTimer {
 	return ActiveTimer{
 		Organization: a.organization,
 		Project:      a.project,
+		Task:         a.task,
 		IsRunning:    a.isRunning,
 		TimeElapsed:  a.TimeElapsed(),
 	}
@@ -101,28 +126,74 @@ func (a *App) GetActiveTimer() ActiveTimer {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	if a.db != nil {
+		a.startRoutines()
+	}
+}
+
+// startRoutines starts the background work once the database is open. The routines are started once and use
+// whichever database is open, a database opened later (unlocked or of another workspace) only gets checked
+func (a *App) startRoutines() {
+	a.withDb(a.reconcileOnStartup)
+	if a.routinesStarted {
+		a.withDb(a.cleanupSoftDeletedRecords)
+		return
+	}
+	a.routinesStarted = true
 	a.monitorTime()
 	a.monitorUpdates()
 	a.cleanupRoutine()
+	a.schedulerRoutine()
+	a.mailRoutine()
+	a.syncRoutine()
+	a.teamRoutine()
+	a.backupRoutine()
 }
 
 // shutdown is called at termination
 func (a *App) shutdown(ctx context.Context) {
 	fmt.Println("Shutting down...")
+	a.dbMu.Lock()
+	defer a.dbMu.Unlock()
 	if a.isRunning {
-		a.StopTimer()
+		if err := a.StopTimer(); err != nil {
+			Logger.Println(err)
+		}
+	}
+	a.stopParallelTimers()
+	a.closeDatabase()
+}
+
+// withDb runs f while the open database can't be closed or swapped for another, f is skipped while no
+// database is open (a locked workspace). Code swapping a.db holds dbMu for writing, so f must not call it
+func (a *App) withDb(f func()) {
+	a.dbMu.RLock()
+	defer a.dbMu.RUnlock()
+	if a.db == nil {
+		return
 	}
+	f()
 }
 
 func (a *App) monitorTime() {
 	ticker := time.NewTicker(1 * time.Second)
 	go func() {
 		for range ticker.C {
-			if a.isRunning && time.Now().Format("2006-01-02") != a.startTime.Format("2006-01-02") {
-				a.StopTimer()
-				a.StartTimer(a.organization, a.project)
-				runtime.EventsEmit(a.ctx, "new-day")
-			}
+			a.withDb(func() {
+				if a.isRunning && dateIn(time.Now(), a.location) != dateIn(a.startTime, a.location) {
+					if err := a.StopTimer(); err != nil {
+						Logger.Println(err)
+						runtime.EventsEmit(a.ctx, "timer-error", toAppError(err))
+					}
+					// The new day may fall in a locked period, the timer stays stopped then
+					if err := a.StartTimer(a.organization, a.project); err != nil {
+						runtime.EventsEmit(a.ctx, "timer-error", toAppError(err))
+						runtime.EventsEmit(a.ctx, "timer-switched", a.GetActiveTimer())
+					}
+					runtime.EventsEmit(a.ctx, "new-day", dateIn(time.Now(), a.location))
+				}
+				a.splitParallelTimersAtMidnight()
+			})
 		}
 	}()
 }
@@ -153,12 +224,12 @@ func (a *App) monitorUpdates() {
 
 func (a *App) cleanupRoutine() {
 	// Run on startup then every 24 hours
-	a.cleanupSoftDeletedRecords()
+	a.withDb(a.cleanupSoftDeletedRecords)
 	ticker := time.NewTicker(24 * time.Hour)
 
 	go func() {
 		for range ticker.C {
-			a.cleanupSoftDeletedRecords()
+			a.withDb(a.cleanupSoftDeletedRecords)
 		}
 	}()
 }
@@ -176,35 +247,61 @@ func (a *App) TimerRunning() bool {
 	return a.isRunning
 }
 
-func (a *App) StartTimer(organization Organization, project Project) {
+// StartTimer starts the main timer, it can't run while today is in a locked period of the organization
+func (a *App) StartTimer(organization Organization, project Project) error {
+	if err := a.timerUnlocked(organization.ID); err != nil {
+		return err
+	}
 	a.startTime = time.Now()
 	a.organization = organization
 	a.project = project
+	a.location = a.reportingLocation(organization.ID)
 	a.isRunning = true
 
 	ctx, cancel = context.WithCancel(context.Background())
+	timerCtx := ctx
 
 	go func() {
 		for {
 			select {
 			case <-time.After(1 * time.Minute):
-				a.saveTimer(a.project.ID)
-			case <-ctx.Done():
+				a.withDb(func() {
+					if timerCtx.Err() != nil {
+						return // stopped while waiting, e.g. to switch the database
+					}
+					if _, err := a.saveTimer(a.project.ID); err != nil {
+						Logger.Println(err)
+						runtime.EventsEmit(a.ctx, "timer-error", toAppError(err))
+					}
+					a.checkCompliance()
+				})
+			case <-timerCtx.Done():
 				return
 			}
 		}
 	}()
+	return nil
 }
 
-func (a *App) StopTimer() {
+// StopTimer stops the running timer, the timer is stopped even if the final save fails
+func (a *App) StopTimer() error {
 	if !a.isRunning {
-		return
+		return nil
 	}
-	secsWork := a.saveTimer(a.project.ID)
-	a.NewWorkSession(a.project.ID, secsWork)
+	_, err := a.saveTimer(a.project.ID)
 	cancel()
 	a.isRunning = false
-	a.lastSave = time.Time{}
+	a.session = WorkSession{}
+	return err
+}
+
+// timerTaskID returns the ID of the task the timer tracks, nil for none
+func (a *App) timerTaskID() *uint {
+	if a.task.ID == 0 {
+		return nil
+	}
+	taskID := a.task.ID
+	return &taskID
 }
 
 // TimeElapsed returns the total seconds worked in the current timer session
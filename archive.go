@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ArchiveFilter string
+
+const (
+	ArchiveActive   ArchiveFilter = "active"
+	ArchiveArchived ArchiveFilter = "archived"
+	ArchiveAll      ArchiveFilter = "all"
+)
+
+// archiveScope filters a query on the archived state, an empty filter only returns active rows
+func archiveScope(filter ArchiveFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch filter {
+		case ArchiveAll:
+			return db
+		case ArchiveArchived:
+			return db.Where("archived_at IS NOT NULL")
+		default:
+			return db.Where("archived_at IS NULL")
+		}
+	}
+}
+
+// setOrganizationArchived archives or unarchives an organization, refusing to archive the one being timed
+func (a *App) setOrganizationArchived(organizationID uint, archived bool) (Organization, error) {
+	organization, err := a.getOrganization(organizationID)
+	if err != nil {
+		return Organization{}, err
+	}
+	if archived && a.isTimedOrganization(organization.ID) {
+		return Organization{}, conflictError(fmt.Sprintf("the timer is running for %s, stop it first", organization.Name))
+	}
+	if (organization.ArchivedAt != nil) == archived {
+		return organization, nil
+	}
+
+	before := organization
+	organization.ArchivedAt = nil
+	if archived {
+		now := time.Now().UTC()
+		organization.ArchivedAt = &now
+	}
+	if err := a.saveAudited(before, &organization); err != nil {
+		return Organization{}, toAppError(err)
+	}
+	return organization, nil
+}
+
+// setProjectArchived archives or unarchives a project, refusing to archive the one being timed
+func (a *App) setProjectArchived(projectID uint, archived bool) (Project, error) {
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return Project{}, err
+	}
+	if archived && a.isTimed(project.ID) {
+		return Project{}, conflictError(fmt.Sprintf("the timer is running for %s, stop it first", project.Name))
+	}
+	if (project.ArchivedAt != nil) == archived {
+		return project, nil
+	}
+
+	before := project
+	project.ArchivedAt = nil
+	if archived {
+		now := time.Now().UTC()
+		project.ArchivedAt = &now
+	}
+	if err := a.saveAudited(before, &project); err != nil {
+		return Project{}, toAppError(err)
+	}
+	return project, nil
+}
+
+// ArchiveOrganization hides an organization and its projects from the pickers, their hours stay in reports
+func (a *App) ArchiveOrganization(organizationID uint) (Organization, error) {
+	return a.setOrganizationArchived(organizationID, true)
+}
+
+// UnarchiveOrganization makes an archived organization active again
+func (a *App) UnarchiveOrganization(organizationID uint) (Organization, error) {
+	return a.setOrganizationArchived(organizationID, false)
+}
+
+// ArchiveProject hides a project from the pickers, its hours stay in reports
+func (a *App) ArchiveProject(projectID uint) (Project, error) {
+	return a.setProjectArchived(projectID, true)
+}
+
+// UnarchiveProject makes an archived project active again
+func (a *App) UnarchiveProject(projectID uint) (Project, error) {
+	return a.setProjectArchived(projectID, false)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// AuditSource is what caused a change
+type AuditSource string
+
+const (
+	AuditTimer    AuditSource = "timer"
+	AuditManual   AuditSource = "manual"
+	AuditImport   AuditSource = "import"
+	AuditTransfer AuditSource = "transfer"
+	AuditUndo     AuditSource = "undo"
+	AuditRedo     AuditSource = "redo"
+	AuditRepair   AuditSource = "repair"
+	AuditSync     AuditSource = "sync" // applied from another device's log, see sync.go
+)
+
+// AuditEntry is a single change to tracked data. The table is append-only, see migrateAuditLog
+type AuditEntry struct {
+	ID         uint        `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
+	EntityType string      `gorm:"index:idx_audit_entity" json:"entity_type"`
+	EntityID   uint        `gorm:"index:idx_audit_entity" json:"entity_id"`
+	ProjectID  uint        `gorm:"index" json:"project_id"` // 0 for organizations
+	Date       string      `gorm:"index" json:"date"`       // work date of hours and sessions
+	Action     AuditAction `json:"action"`
+	Source     AuditSource `json:"source"`
+	Before     string      `json:"before"` // JSON, empty on create
+	After      string      `json:"after"`  // JSON, empty on delete
+}
+
+// AuditFilter narrows down GetAuditLog, zero values match everything
+type AuditFilter struct {
+	EntityType     string `json:"entity_type"`
+	OrganizationID uint   `json:"organization_id"`
+	ProjectID      uint   `json:"project_id"`
+	StartDate      string `json:"start_date"` // work date, YYYY-MM-DD
+	EndDate        string `json:"end_date"`
+	Limit          int    `json:"limit"`
+}
+
+// auditable is implemented by the models whose changes are audited
+type auditable interface {
+	auditInfo() (entityType string, id uint, projectID uint, date string)
+}
+
+func (o Organization) auditInfo() (string, uint, uint, string) {
+	return "organization", o.ID, 0, ""
+}
+
+func (p Project) auditInfo() (string, uint, uint, string) {
+	return "project", p.ID, p.ID, ""
+}
+
+func (w WorkHours) auditInfo() (string, uint, uint, string) {
+	return "work_hours", w.ID, w.ProjectID, w.Date
+}
+
+func (w WorkSession) auditInfo() (string, uint, uint, string) {
+	return "work_session", w.ID, w.ProjectID, w.Date
+}
+
+// migrateAuditLog makes the audit table append-only, the triggers are recreated if a migration rebuilds the table
+func migrateAuditLog(db *gorm.DB) {
+	for _, statement := range []string{"UPDATE", "DELETE"} {
+		err := db.Exec(fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS audit_entries_no_%s
+			BEFORE %s ON audit_entries
+			BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`, statement, statement)).Error
+		handleDBError(err)
+	}
+}
+
+// recordAudit appends a change to the audit log, before or after is nil on create and delete
+func recordAudit(tx *gorm.DB, source AuditSource, action AuditAction, before, after auditable) error {
+	entry := AuditEntry{Action: action, Source: source}
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	entry.EntityType, entry.EntityID, entry.ProjectID, entry.Date = subject.auditInfo()
+
+	for _, value := range []struct {
+		model auditable
+		field *string
+	}{{before, &entry.Before}, {after, &entry.After}} {
+		if value.model == nil {
+			continue
+		}
+		data, err := json.Marshal(value.model)
+		if err != nil {
+			return err
+		}
+		*value.field = string(data)
+	}
+	return tx.Create(&entry).Error
+}
+
+// deleteAudited soft deletes the rows with the given IDs and audits each of them
+func deleteAudited[T auditable](tx *gorm.DB, source AuditSource, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var rows []T
+	if err := tx.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(new(T), ids).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := recordAudit(tx, source, AuditDelete, row, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreAudited restores the soft deleted rows with the given IDs and audits each of them
+func restoreAudited[T auditable](tx *gorm.DB, source AuditSource, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var rows []T
+	if err := tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Find(&rows).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(new(T)).Where("id IN ?", ids).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	var restored []T
+	if err := tx.Where("id IN ?", ids).Find(&restored).Error; err != nil {
+		return err
+	}
+	deleted := make(map[uint]T)
+	for _, row := range rows {
+		_, id, _, _ := row.auditInfo()
+		deleted[id] = row
+	}
+	for _, row := range restored {
+		var before auditable
+		_, id, _, _ := row.auditInfo()
+		if previous, ok := deleted[id]; ok {
+			before = previous
+		}
+		if err := recordAudit(tx, source, AuditRestore, before, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createAudited creates a row and records it in the audit log
+func (a *App) createAudited(source AuditSource, model auditable) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		return insertAudited(tx, source, model)
+	})
+}
+
+// insertAudited creates a row within a transaction and records it in the audit log
+func insertAudited(tx *gorm.DB, source AuditSource, model auditable) error {
+	if err := tx.Create(model).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, source, AuditCreate, nil, model)
+}
+
+// saveAudited saves a changed row and records its previous and new values in the audit log
+func (a *App) saveAudited(before auditable, model auditable) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, AuditManual, AuditUpdate, before, model)
+	})
+}
+
+func (a *App) auditQuery(filter AuditFilter) *gorm.DB {
+	query := a.db.Model(&AuditEntry{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.OrganizationID != 0 {
+		query = query.Where(
+			"(entity_type = 'organization' AND entity_id = ?) OR project_id IN (?)",
+			filter.OrganizationID,
+			a.db.Unscoped().Model(&Project{}).Select("id").Where("organization_id = ?", filter.OrganizationID),
+		)
+	}
+	if filter.StartDate != "" {
+		query = query.Where("date >= ?", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		query = query.Where("date <= ?", filter.EndDate)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return query.Order("id DESC")
+}
+
+// GetAuditLog returns the audit entries matching the filter, most recent first
+func (a *App) GetAuditLog(filter AuditFilter) (entries []AuditEntry, err error) {
+	if err := a.auditQuery(filter).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ExportAuditLog writes the audit entries matching the filter to a CSV file in the export directory
+func (a *App) ExportAuditLog(filter AuditFilter) (string, error) {
+	entries, err := a.GetAuditLog(filter)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := a.exportRoot()
+	if err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(root, "audit", fmt.Sprintf("audit_log_%s.csv", time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+
+	csvFile, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	writer.Write([]string{"Timestamp", "Entity", "Entity ID", "Project ID", "Date", "Action", "Source", "Before", "After"})
+	for _, entry := range entries {
+		writer.Write([]string{
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.EntityType,
+			strconv.FormatUint(uint64(entry.EntityID), 10),
+			strconv.FormatUint(uint64(entry.ProjectID), 10),
+			entry.Date,
+			string(entry.Action),
+			string(entry.Source),
+			entry.Before,
+			entry.After,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
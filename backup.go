@@ -0,0 +1,669 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// Remote backups
+//
+// A backup is a snapshot of the database, gzip compressed and encrypted with AES-256-GCM under a key derived
+// from the target's passphrase, uploaded to an S3-compatible bucket or a WebDAV folder (see backup_remote.go).
+// The passphrase never leaves the device, so the storage provider only ever sees ciphertext. Every upload is
+// downloaded again and checked before older backups are removed, and a restore checks the backup the same way
+// before it replaces anything.
+//
+// The credentials and the passphrase of a target are stored in the database, so targets can only be set up once
+// the database is encrypted, and they are never sent back to the frontend. On a new installation the backups are
+// found with the target's settings before they are saved (FindRemoteBackups, RestoreRemoteBackup), the restored
+// database brings its targets along.
+
+const (
+	backupMagic      = "WTBAK1"
+	backupPrefix     = "worktracker-"
+	backupExtension  = ".wtbak"
+	backupTimeFormat = "20060102T150405Z"
+	backupRetryDelay = 15 * time.Minute
+	beforeRestoreExt = ".before-restore"
+)
+
+var backupNamePattern = regexp.MustCompile(`^worktracker-\d{8}T\d{6}Z\.wtbak$`)
+
+// Restores need it too, a backup holds the targets it was made with
+const backupSecretsReason = "the credentials of backup targets are stored in it"
+
+type BackupKind string
+
+const (
+	BackupS3     BackupKind = "s3"
+	BackupWebDAV BackupKind = "webdav"
+)
+
+// BackupTarget is a remote location the database is backed up to
+type BackupTarget struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	Kind          BackupKind `json:"kind"`
+	Endpoint      string     `json:"endpoint"` // e.g. https://s3.eu-central-1.amazonaws.com or https://cloud.example.com/remote.php/dav/files/me
+	Bucket        string     `json:"bucket"`   // S3 only
+	Region        string     `json:"region"`   // S3 only, us-east-1 when empty
+	Folder        string     `json:"folder"`   // key prefix or folder the backups are put in
+	Username      string     `json:"username"` // the access key for S3
+	Password      string     `json:"password"` // the secret key for S3
+	Passphrase    string     `json:"passphrase"`
+	PasswordSet   bool       `gorm:"-" json:"password_set"` // the password and the passphrase are kept from the frontend, see redacted
+	PassphraseSet bool       `gorm:"-" json:"passphrase_set"`
+	IntervalHours int        `json:"interval_hours"` // 0 only backs up on demand
+	KeepCount     int        `json:"keep_count"`     // backups kept on the target, 0 keeps all
+	Enabled       bool       `json:"enabled"`
+	LastRunAt     *time.Time `json:"last_run_at"`
+	LastBackupAt  *time.Time `json:"last_backup_at"` // last successful backup
+	LastError     string     `json:"last_error"`
+}
+
+// BackupRun records a single upload to a target
+type BackupRun struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	TargetID   uint       `gorm:"index" json:"target_id"`
+	ObjectName string     `json:"object_name"`
+	Size       int64      `json:"size"`
+	SHA256     string     `json:"sha256"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	Status     RunStatus  `json:"status"`
+	Error      string     `json:"error"`
+}
+
+// RemoteBackup is a backup found on a target, size and checksum are only known for uploads from this database
+type RemoteBackup struct {
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Size       int64      `json:"size"`
+	SHA256     string     `json:"sha256"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+// backupMu keeps backups and restores from overlapping
+var backupMu sync.Mutex
+
+// redacted returns the target without its secrets, only whether they are set
+func (t BackupTarget) redacted() BackupTarget {
+	t.PasswordSet, t.PassphraseSet = t.Password != "", t.Passphrase != ""
+	t.Password, t.Passphrase = "", ""
+	return t
+}
+
+// normalizeBackupTarget trims the settings of a target as they are typed in
+func normalizeBackupTarget(target BackupTarget) BackupTarget {
+	target.Name = strings.TrimSpace(target.Name)
+	target.Endpoint = strings.TrimRight(strings.TrimSpace(target.Endpoint), "/")
+	target.Folder = strings.Trim(strings.TrimSpace(target.Folder), "/")
+	return target
+}
+
+func validateBackupTarget(target BackupTarget) error {
+	if strings.TrimSpace(target.Name) == "" {
+		return errors.New("a name is required")
+	}
+	if target.Kind != BackupS3 && target.Kind != BackupWebDAV {
+		return fmt.Errorf("invalid backup target kind %q", target.Kind)
+	}
+	if _, err := newBackupStore(target); err != nil {
+		return fmt.Errorf("%w, it must start with http:// or https://", err)
+	}
+	if target.Kind == BackupS3 && (target.Bucket == "" || target.Username == "" || target.Password == "") {
+		return errors.New("S3 needs a bucket, an access key and a secret key")
+	}
+	if len(target.Passphrase) < minPassphraseLength {
+		return fmt.Errorf("the backup passphrase needs at least %d characters", minPassphraseLength)
+	}
+	if target.IntervalHours < 0 || target.KeepCount < 0 {
+		return errors.New("the interval and the number of kept backups can't be negative")
+	}
+	return nil
+}
+
+func (a *App) getBackupTarget(targetID uint) (BackupTarget, error) {
+	var target BackupTarget
+	if err := a.db.Where(&BackupTarget{ID: targetID}).First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return BackupTarget{}, notFoundError("backup target", targetID)
+		}
+		return BackupTarget{}, err
+	}
+	return target, nil
+}
+
+// GetBackupTargets returns the remote backup targets by name, without their password and passphrase
+func (a *App) GetBackupTargets() (targets []BackupTarget, err error) {
+	if err := a.db.Order("name").Find(&targets).Error; err != nil {
+		return nil, toAppError(err)
+	}
+	for i := range targets {
+		targets[i] = targets[i].redacted()
+	}
+	return targets, nil
+}
+
+// SaveBackupTarget creates or updates a backup target, an empty password or passphrase keeps the saved one.
+// Backups made before the passphrase changed still need the old passphrase to be restored
+func (a *App) SaveBackupTarget(target BackupTarget) (BackupTarget, error) {
+	if err := a.requireEncryption(backupSecretsReason); err != nil {
+		return BackupTarget{}, err
+	}
+	target = normalizeBackupTarget(target)
+	var current BackupTarget
+	if target.ID != 0 {
+		var err error
+		if current, err = a.getBackupTarget(target.ID); err != nil {
+			return BackupTarget{}, toAppError(err)
+		}
+		if target.Password == "" {
+			target.Password = current.Password
+		}
+		if target.Passphrase == "" {
+			target.Passphrase = current.Passphrase
+		}
+	}
+	if err := validateBackupTarget(target); err != nil {
+		return BackupTarget{}, invalid(err)
+	}
+
+	if target.ID != 0 {
+		target.CreatedAt = current.CreatedAt
+		target.LastRunAt, target.LastBackupAt, target.LastError = current.LastRunAt, current.LastBackupAt, current.LastError
+	}
+	if err := a.db.Save(&target).Error; err != nil {
+		return BackupTarget{}, toAppError(err)
+	}
+	return target.redacted(), nil
+}
+
+// DeleteBackupTarget removes a target and its run history, the backups stay on the remote storage
+func (a *App) DeleteBackupTarget(targetID uint) error {
+	if _, err := a.getBackupTarget(targetID); err != nil {
+		return toAppError(err)
+	}
+	if err := a.db.Where(&BackupRun{TargetID: targetID}).Delete(&BackupRun{}).Error; err != nil {
+		return toAppError(err)
+	}
+	return dbError(a.db.Delete(&BackupTarget{}, targetID).Error, "backup target", targetID)
+}
+
+// GetBackupRuns returns the upload history of a target, most recent first
+func (a *App) GetBackupRuns(targetID uint) (runs []BackupRun, err error) {
+	if err := a.db.Where(&BackupRun{TargetID: targetID}).Order("created_at DESC").Find(&runs).Error; err != nil {
+		return nil, toAppError(err)
+	}
+	return runs, nil
+}
+
+// RunBackup uploads a backup to the target immediately, the run is returned whether it succeeded or not
+func (a *App) RunBackup(targetID uint) (BackupRun, error) {
+	target, err := a.getBackupTarget(targetID)
+	if err != nil {
+		return BackupRun{}, toAppError(err)
+	}
+	return a.runBackup(target), nil
+}
+
+// runBackup uploads a snapshot, reads it back to check it and applies the target's retention
+func (a *App) runBackup(target BackupTarget) BackupRun {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	now := time.Now().UTC()
+	run := BackupRun{TargetID: target.ID, ObjectName: backupPrefix + now.Format(backupTimeFormat) + backupExtension, Status: RunSuccess}
+	err := func() error {
+		store, err := newBackupStore(target)
+		if err != nil {
+			return err
+		}
+		plaintext, err := a.snapshotDatabase()
+		if err != nil {
+			return err
+		}
+		data, err := encryptBackup(plaintext, target.Passphrase)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		run.Size, run.SHA256 = int64(len(data)), hex.EncodeToString(sum[:])
+
+		if err := store.put(run.ObjectName, data); err != nil {
+			return err
+		}
+		if _, err := fetchBackup(store, run.ObjectName, run.SHA256, target.Passphrase); err != nil {
+			return fmt.Errorf("the uploaded backup doesn't check out: %w", err)
+		}
+		verifiedAt := time.Now().UTC()
+		run.VerifiedAt = &verifiedAt
+		return pruneBackups(store, target.KeepCount)
+	}()
+
+	updates := map[string]any{"last_run_at": &now, "last_error": ""}
+	if err != nil {
+		run.Status, run.Error = RunFailed, err.Error()
+		updates["last_error"] = run.Error
+		Logger.Printf("Backup to %s failed: %v", target.Name, err)
+	}
+	if run.VerifiedAt != nil {
+		// The backup is safe even when removing old ones failed
+		updates["last_backup_at"] = &now
+	}
+	if err := a.db.Create(&run).Error; err != nil {
+		Logger.Println("Failed to record the backup run:", err)
+	}
+	if err := a.db.Model(&BackupTarget{ID: target.ID}).Updates(updates).Error; err != nil {
+		Logger.Println("Failed to update the backup target:", err)
+	}
+	return run
+}
+
+// snapshotDatabase returns the database as the bytes of a SQLite file, an encrypted one is taken from memory
+func (a *App) snapshotDatabase() ([]byte, error) {
+	if a.store != nil {
+		a.store.mu.Lock()
+		defer a.store.mu.Unlock()
+		plaintext, _, err := a.store.snapshot()
+		return plaintext, err
+	}
+	return serializeDb(a.db)
+}
+
+// pruneBackups removes the oldest backups beyond keep, other files in the folder are left alone
+func pruneBackups(store backupStore, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	names, err := listBackups(store)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		if err := store.remove(names[0]); err != nil {
+			return fmt.Errorf("removing the old backup %s failed: %w", names[0], err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// listBackups returns the names of the backups on a target, oldest first
+func listBackups(store backupStore) ([]string, error) {
+	all, err := store.list()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range all {
+		if backupNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetRemoteBackups lists the backups on a target, newest first
+func (a *App) GetRemoteBackups(targetID uint) ([]RemoteBackup, error) {
+	target, err := a.getBackupTarget(targetID)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return a.remoteBackups(target)
+}
+
+// FindRemoteBackups lists the backups on a target that isn't saved, to restore one on a new installation
+func (a *App) FindRemoteBackups(target BackupTarget) ([]RemoteBackup, error) {
+	target = normalizeBackupTarget(target)
+	if err := validateBackupTarget(target); err != nil {
+		return nil, invalid(err)
+	}
+	target.ID = 0
+	return a.remoteBackups(target)
+}
+
+// remoteBackups lists the backups on a target, the uploads recorded for it add their size and checksum
+func (a *App) remoteBackups(target BackupTarget) ([]RemoteBackup, error) {
+	store, err := newBackupStore(target)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	names, err := listBackups(store)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+
+	var runs []BackupRun
+	if target.ID != 0 {
+		if err := a.db.Where("target_id = ? AND status = ?", target.ID, RunSuccess).Find(&runs).Error; err != nil {
+			return nil, toAppError(err)
+		}
+	}
+	known := map[string]BackupRun{}
+	for _, run := range runs {
+		known[run.ObjectName] = run
+	}
+
+	backups := []RemoteBackup{}
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		backup := RemoteBackup{Name: name}
+		backup.CreatedAt, _ = time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupExtension))
+		if run, ok := known[name]; ok {
+			backup.Size, backup.SHA256, backup.VerifiedAt = run.Size, run.SHA256, run.VerifiedAt
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// VerifyBackup downloads a backup and checks that it decrypts to an intact database.
+// An empty passphrase uses the target's
+func (a *App) VerifyBackup(targetID uint, name string, passphrase string) error {
+	target, store, err := a.backupSource(targetID, name, passphrase)
+	if err != nil {
+		return err
+	}
+	var run BackupRun
+	a.db.Where(&BackupRun{TargetID: targetID, ObjectName: name, Status: RunSuccess}).Limit(1).Find(&run)
+	if _, err := fetchBackup(store, name, run.SHA256, target.Passphrase); err != nil {
+		return toAppError(err)
+	}
+	if run.ID != 0 {
+		now := time.Now().UTC()
+		if err := a.db.Model(&run).Update("verified_at", &now).Error; err != nil {
+			return toAppError(err)
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the database with a backup once it checks out. The current database is kept next
+// to it with a .before-restore suffix, an encrypted database stays encrypted with its key.
+// An empty passphrase uses the target's
+func (a *App) RestoreBackup(targetID uint, name string, passphrase string) error {
+	if err := a.requireEncryption(backupSecretsReason); err != nil {
+		return err
+	}
+	target, store, err := a.backupSource(targetID, name, passphrase)
+	if err != nil {
+		return err
+	}
+	return a.restoreBackup(store, name, target.Passphrase)
+}
+
+// RestoreRemoteBackup restores a backup from a target that isn't saved, e.g. on a new installation.
+// The database must be encrypted, like for RestoreBackup
+func (a *App) RestoreRemoteBackup(target BackupTarget, name string) error {
+	if err := a.requireEncryption(backupSecretsReason); err != nil {
+		return err
+	}
+	if !backupNamePattern.MatchString(name) {
+		return validationError(fmt.Sprintf("%q is not a backup", name))
+	}
+	target = normalizeBackupTarget(target)
+	if err := validateBackupTarget(target); err != nil {
+		return invalid(err)
+	}
+	store, err := newBackupStore(target)
+	if err != nil {
+		return invalid(err)
+	}
+	return a.restoreBackup(store, name, target.Passphrase)
+}
+
+// restoreBackup downloads a backup and puts it in place of the database
+func (a *App) restoreBackup(store backupStore, name, passphrase string) error {
+	plaintext, err := fetchBackup(store, name, "", passphrase)
+	if err != nil {
+		return toAppError(err)
+	}
+
+	// The database is locked before backupMu, in the order the backup routine takes them
+	err = func() error {
+		a.dbMu.Lock()
+		defer a.dbMu.Unlock()
+		backupMu.Lock()
+		defer backupMu.Unlock()
+		encrypted := a.store
+		if err := a.releaseDatabase(); err != nil {
+			return err
+		}
+		if err := a.replaceDatabase(plaintext, encrypted); err != nil {
+			// Fall back to the database that was there before
+			a.openErr = a.reopenDatabase(encrypted)
+			return fmt.Errorf("the backup couldn't be restored: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		return toAppError(err)
+	}
+	if a.ctx != nil {
+		a.startRoutines()
+	}
+	return nil
+}
+
+// backupSource returns the target of a backup with the passphrase to use and a client for its storage
+func (a *App) backupSource(targetID uint, name string, passphrase string) (BackupTarget, backupStore, error) {
+	if a.db == nil {
+		return BackupTarget{}, nil, lockedError("unlock the database first")
+	}
+	if !backupNamePattern.MatchString(name) {
+		return BackupTarget{}, nil, validationError(fmt.Sprintf("%q is not a backup", name))
+	}
+	target, err := a.getBackupTarget(targetID)
+	if err != nil {
+		return BackupTarget{}, nil, toAppError(err)
+	}
+	if passphrase != "" {
+		target.Passphrase = passphrase
+	}
+	store, err := newBackupStore(target)
+	if err != nil {
+		return BackupTarget{}, nil, invalid(err)
+	}
+	return target, store, nil
+}
+
+// replaceDatabase keeps a copy of the closed database and opens the restored one in its place
+func (a *App) replaceDatabase(plaintext []byte, encrypted *encryptedStore) error {
+	path := filepath.Join(a.dbDir, dbFileName)
+	if encrypted != nil {
+		path = encrypted.path
+	}
+	if current, err := os.ReadFile(path); err == nil {
+		if err := writeFileAtomic(path+beforeRestoreExt, current); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if encrypted != nil {
+		return a.openEncrypted(plaintext, encrypted.mode, encrypted.salt, encrypted.key)
+	}
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := writeFileAtomic(path, plaintext); err != nil {
+		return err
+	}
+	return a.openDatabase("")
+}
+
+// reopenDatabase opens the database closed for a restore again, an encrypted one with the key it had
+func (a *App) reopenDatabase(encrypted *encryptedStore) error {
+	if encrypted == nil {
+		return a.openDatabase("")
+	}
+	data, err := os.ReadFile(encrypted.path)
+	if err != nil {
+		return err
+	}
+	plaintext, err := decryptDatabaseFile(data, encrypted.key)
+	if err != nil {
+		return err
+	}
+	return a.openEncrypted(plaintext, encrypted.mode, encrypted.salt, encrypted.key)
+}
+
+// encryptBackup compresses a database and encrypts it, the header with the salt is authenticated too
+func encryptBackup(plaintext []byte, passphrase string) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(deriveKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header := append([]byte(backupMagic), salt...)
+	out := append(append([]byte{}, header...), nonce...)
+	return aead.Seal(out, nonce, compressed.Bytes(), header), nil
+}
+
+// decryptBackup returns the database in a backup
+func decryptBackup(data []byte, passphrase string) ([]byte, error) {
+	headerSize := len(backupMagic) + encryptionSaltSize
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(backupMagic)) {
+		return nil, errors.New("the backup is damaged or of an unknown version")
+	}
+	aead, err := newAEAD(deriveKey(passphrase, data[len(backupMagic):headerSize]))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, errors.New("the backup is damaged")
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	compressed, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], data[:headerSize])
+	if err != nil {
+		return nil, validationError("the backup can't be decrypted, the passphrase is wrong or the backup is damaged")
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// fetchBackup downloads a backup and returns its database after checking it, the checksum is skipped when empty
+func fetchBackup(store backupStore, name, checksum, passphrase string) ([]byte, error) {
+	data, err := store.get(name)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != checksum {
+			return nil, errors.New("the backup's checksum doesn't match the uploaded one")
+		}
+	}
+	plaintext, err := decryptBackup(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, checkDatabase(plaintext)
+}
+
+// checkDatabase opens a serialized database in memory and runs SQLite's integrity check on it
+func checkDatabase(plaintext []byte) error {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Raw(func(driverConn any) error {
+		return driverConn.(*sqlite3.SQLiteConn).Deserialize(plaintext, "main")
+	})
+	if err != nil {
+		return fmt.Errorf("the backup is not a database: %w", err)
+	}
+
+	var result string
+	if err := conn.QueryRowContext(context.Background(), "PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("the backup's database is damaged: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("the backup's database is damaged: %s", result)
+	}
+	var tables int
+	err = conn.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('organizations', 'work_sessions')").Scan(&tables)
+	if err != nil || tables != 2 {
+		return errors.New("the backup is not a work tracker database")
+	}
+	return nil
+}
+
+// runDueBackups backs up to every enabled target whose interval has passed, failed backups are retried after a while
+func (a *App) runDueBackups() {
+	var targets []BackupTarget
+	if err := a.db.Where("enabled = ? AND interval_hours > 0", true).Find(&targets).Error; err != nil {
+		Logger.Println("Failed to load the backup targets:", err)
+		return
+	}
+	now := time.Now()
+	for _, target := range targets {
+		due := target.LastBackupAt == nil || now.Sub(*target.LastBackupAt) >= time.Duration(target.IntervalHours)*time.Hour
+		if !due || (target.LastRunAt != nil && target.LastError != "" && now.Sub(*target.LastRunAt) < backupRetryDelay) {
+			continue
+		}
+		a.runBackup(target)
+	}
+}
+
+// backupRoutine uploads scheduled backups in the background
+func (a *App) backupRoutine() {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			a.withDb(a.runDueBackups)
+		}
+	}()
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupStore is where backup files are uploaded to, names are relative to the target's folder
+type backupStore interface {
+	put(name string, data []byte) error
+	get(name string) ([]byte, error)
+	list() ([]string, error)
+	remove(name string) error
+}
+
+const backupRequestTimeout = 5 * time.Minute
+
+func newBackupStore(target BackupTarget) (backupStore, error) {
+	endpoint, err := url.Parse(strings.TrimRight(target.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", target.Endpoint)
+	}
+	client := &http.Client{Timeout: backupRequestTimeout}
+	prefix := strings.Trim(target.Folder, "/")
+
+	switch target.Kind {
+	case BackupS3:
+		region := target.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &s3Store{client: client, endpoint: endpoint, bucket: target.Bucket, prefix: prefix, region: region,
+			accessKey: target.Username, secretKey: target.Password}, nil
+	case BackupWebDAV:
+		return &webdavStore{client: client, endpoint: endpoint, prefix: prefix,
+			username: target.Username, password: target.Password}, nil
+	default:
+		return nil, fmt.Errorf("invalid backup target kind %q", target.Kind)
+	}
+}
+
+// remoteError turns a failed response into an error with the start of its body
+func remoteError(operation string, response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return fmt.Errorf("%s failed: %s", operation, response.Status)
+	}
+	return fmt.Errorf("%s failed: %s: %s", operation, response.Status, message)
+}
+
+// s3Store talks to S3 or an S3-compatible service (MinIO, Backblaze B2, Wasabi) with path-style
+// URLs and Signature Version 4
+type s3Store struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+}
+
+func (s *s3Store) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+func (s *s3Store) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	target := *s.endpoint
+	target.Path = path.Join("/", s.endpoint.Path, s.bucket, key)
+	target.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	request, err := http.NewRequest(method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(request, body, s.accessKey, s.secretKey, s.region, time.Now())
+	return s.client.Do(request)
+}
+
+func (s *s3Store) put(name string, data []byte) error {
+	response, err := s.do(http.MethodPut, s.key(name), nil, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return remoteError("upload", response)
+	}
+	return nil
+}
+
+func (s *s3Store) get(name string) ([]byte, error) {
+	response, err := s.do(http.MethodGet, s.key(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, remoteError("download", response)
+	}
+	return io.ReadAll(response.Body)
+}
+
+func (s *s3Store) list() ([]string, error) {
+	var names []string
+	query := url.Values{"list-type": {"2"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix+"/")
+	}
+	for {
+		response, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if response.StatusCode != http.StatusOK {
+			err = remoteError("listing", response)
+		} else {
+			err = xml.NewDecoder(response.Body).Decode(&result)
+		}
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, query.Get("prefix"))
+			if !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		if !result.IsTruncated {
+			return names, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s *s3Store) remove(name string) error {
+	response, err := s.do(http.MethodDelete, s.key(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return remoteError("delete", response)
+	}
+	return nil
+}
+
+// signS3Request adds a Signature Version 4 Authorization header, the host and all headers set on the request are signed
+func signS3Request(request *http.Request, body []byte, accessKey, secretKey, region string, now time.Time) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalPath := request.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalPath,
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts the query parameters and escapes them the way SigV4 expects
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(name)+"="+sigV4Escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func sigV4Escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// webdavStore talks to a WebDAV server (Nextcloud, ownCloud, Apache mod_dav) with basic authentication
+type webdavStore struct {
+	client   *http.Client
+	endpoint *url.URL
+	prefix   string
+	username string
+	password string
+}
+
+func (w *webdavStore) url(name string) string {
+	target := *w.endpoint
+	target.Path = path.Join("/", w.endpoint.Path, w.prefix, name)
+	if name == "" {
+		target.Path += "/"
+	}
+	return target.String()
+}
+
+func (w *webdavStore) do(method, name string, body []byte, header map[string]string) (*http.Response, error) {
+	request, err := http.NewRequest(method, w.url(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.username != "" {
+		request.SetBasicAuth(w.username, w.password)
+	}
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	return w.client.Do(request)
+}
+
+// ensureFolder creates the target's folder and its parents, existing folders answer 405
+func (w *webdavStore) ensureFolder() error {
+	if w.prefix == "" {
+		return nil
+	}
+	folder := ""
+	for _, part := range strings.Split(w.prefix, "/") {
+		folder = path.Join(folder, part)
+		target := *w.endpoint
+		target.Path = path.Join("/", w.endpoint.Path, folder) + "/"
+		request, err := http.NewRequest("MKCOL", target.String(), nil)
+		if err != nil {
+			return err
+		}
+		if w.username != "" {
+			request.SetBasicAuth(w.username, w.password)
+		}
+		response, err := w.client.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("creating the folder %s failed: %s", folder, response.Status)
+		}
+	}
+	return nil
+}
+
+func (w *webdavStore) put(name string, data []byte) error {
+	if err := w.ensureFolder(); err != nil {
+		return err
+	}
+	response, err := w.do(http.MethodPut, name, data, map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return remoteError("upload", response)
+	}
+	return nil
+}
+
+func (w *webdavStore) get(name string) ([]byte, error) {
+	response, err := w.do(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, remoteError("download", response)
+	}
+	return io.ReadAll(response.Body)
+}
+
+func (w *webdavStore) list() ([]string, error) {
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`)
+	response, err := w.do("PROPFIND", "", body, map[string]string{"Depth": "1", "Content-Type": "application/xml"})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil // nothing was uploaded yet
+	}
+	if response.StatusCode != http.StatusMultiStatus {
+		return nil, remoteError("listing", response)
+	}
+	var result struct {
+		Responses []struct {
+			Href       string    `xml:"href"`
+			Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range result.Responses {
+		if entry.Collection != nil {
+			continue
+		}
+		href, err := url.PathUnescape(entry.Href)
+		if err != nil {
+			href = entry.Href
+		}
+		names = append(names, path.Base(href))
+	}
+	return names, nil
+}
+
+func (w *webdavStore) remove(name string) error {
+	response, err := w.do(http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return remoteError("delete", response)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestEncryptBackup(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("SQLite format 3\x00 and some pages "), 100)
+	data, err := encryptBackup(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(backupMagic)) || bytes.Contains(data, []byte("SQLite")) {
+		t.Fatal("the backup isn't encrypted")
+	}
+	if len(data) >= len(plaintext) {
+		t.Errorf("the backup is %d bytes for %d bytes of database, it isn't compressed", len(data), len(plaintext))
+	}
+	got, err := decryptBackup(data, "correct horse")
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("round trip: %v", err)
+	}
+	if again, _ := encryptBackup(plaintext, "correct horse"); bytes.Equal(again, data) {
+		t.Error("two backups of the same database are the same, the salt or nonce isn't random")
+	}
+
+	headerSize := len(backupMagic) + encryptionSaltSize
+	tamper := func(i int) []byte {
+		changed := bytes.Clone(data)
+		changed[i] ^= 0x01
+		return changed
+	}
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+	}{
+		{"wrong passphrase", data, "wrong horse"},
+		{"salt changed", tamper(len(backupMagic)), "correct horse"},
+		{"nonce changed", tamper(headerSize), "correct horse"},
+		{"ciphertext changed", tamper(len(data) - 20), "correct horse"},
+		{"truncated", data[:len(data)-1], "correct horse"},
+	}
+	for _, test := range tests {
+		var appErr *AppError
+		if _, err := decryptBackup(test.data, test.passphrase); !errors.As(err, &appErr) || appErr.Code != ErrValidation {
+			t.Errorf("%s: err = %v, want a validation error", test.name, err)
+		}
+	}
+	for name, damaged := range map[string][]byte{
+		"other magic":         append([]byte("WTBAK9"), data[len(backupMagic):]...),
+		"header only":         data[:headerSize],
+		"not a backup at all": []byte("hello"),
+	} {
+		if _, err := decryptBackup(damaged, "correct horse"); err == nil {
+			t.Errorf("%s: decrypted", name)
+		}
+	}
+}
+
+// memoryStore is a backup store kept in memory
+type memoryStore map[string][]byte
+
+func (m memoryStore) put(name string, data []byte) error {
+	m[name] = data
+	return nil
+}
+
+func (m memoryStore) get(name string) ([]byte, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (m memoryStore) list() ([]string, error) {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m memoryStore) remove(name string) error {
+	delete(m, name)
+	return nil
+}
+
+func (m memoryStore) names() []string {
+	names, _ := m.list()
+	sort.Strings(names)
+	return names
+}
+
+func TestPruneBackups(t *testing.T) {
+	store := memoryStore{}
+	for _, name := range []string{
+		"worktracker-20260301T120000Z.wtbak",
+		"worktracker-20260303T120000Z.wtbak",
+		"worktracker-20260302T120000Z.wtbak",
+		"worktracker-20260304T120000Z.wtbak",
+		"worktracker-latest.wtbak",
+		"notes.txt",
+	} {
+		store.put(name, []byte(name))
+	}
+
+	if err := pruneBackups(store, 0); err != nil || len(store) != 6 {
+		t.Fatalf("keeping all removed backups: %v, %v", store.names(), err)
+	}
+	if err := pruneBackups(store, 5); err != nil || len(store) != 6 {
+		t.Fatalf("keeping more than there are removed backups: %v, %v", store.names(), err)
+	}
+	if err := pruneBackups(store, 2); err != nil {
+		t.Fatal(err)
+	}
+	// The newest are kept by the time in their name, files that aren't backups are left alone
+	want := []string{
+		"notes.txt",
+		"worktracker-20260303T120000Z.wtbak",
+		"worktracker-20260304T120000Z.wtbak",
+		"worktracker-latest.wtbak",
+	}
+	if got := store.names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after pruning: %v, want %v", got, want)
+	}
+}
+
+func TestCheckDatabase(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := a.snapshotDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkDatabase(snapshot); err != nil {
+		t.Fatalf("a snapshot of the database doesn't check out: %v", err)
+	}
+
+	other, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "other.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, text TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	otherSnapshot, err := serializeDb(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := other.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a database", []byte("hello, this is not SQLite")},
+		{"truncated", snapshot[:len(snapshot)/2]},
+		{"another SQLite database", otherSnapshot},
+	}
+	for _, test := range tests {
+		if err := checkDatabase(test.data); err == nil {
+			t.Errorf("%s: checks out", test.name)
+		}
+	}
+}
+
+func TestBackupTargetSecrets(t *testing.T) {
+	server := newWebDAVServer(t, "me", "secret")
+	target := BackupTarget{Name: "Cloud", Kind: BackupWebDAV, Endpoint: server.URL + "/dav/", Folder: "/backups/",
+		Username: "me", Password: "secret", Passphrase: "backup passphrase", KeepCount: 3}
+
+	// A plaintext database doesn't take credentials, and can't restore a backup holding them
+	plain := newTestApp(t)
+	var appErr *AppError
+	if _, err := plain.SaveBackupTarget(target); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("saving into a plaintext database: %v, want a conflict", err)
+	}
+	if err := plain.RestoreRemoteBackup(target, "worktracker-20260301T120000Z.wtbak"); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("restoring into a plaintext database: %v, want a conflict", err)
+	}
+
+	a := newEncryptedTestApp(t)
+	saved, err := a.SaveBackupTarget(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Endpoint != server.URL+"/dav" || saved.Folder != "backups" {
+		t.Errorf("saved endpoint %q and folder %q, want them trimmed", saved.Endpoint, saved.Folder)
+	}
+	targets, err := a.GetBackupTargets()
+	if err != nil || len(targets) != 1 {
+		t.Fatalf("targets: %v, %v", targets, err)
+	}
+	for _, got := range []BackupTarget{saved, targets[0]} {
+		data, _ := json.Marshal(got)
+		if strings.Contains(string(data), "secret") || strings.Contains(string(data), "backup passphrase") {
+			t.Errorf("the frontend gets the secrets: %s", data)
+		}
+		if !got.PasswordSet || !got.PassphraseSet {
+			t.Errorf("the frontend isn't told the secrets are set: %+v", got)
+		}
+	}
+
+	// Saving the redacted target keeps the secrets, new ones replace them
+	saved.KeepCount = 5
+	if _, err := a.SaveBackupTarget(saved); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := a.getBackupTarget(saved.ID)
+	if err != nil || stored.Password != "secret" || stored.Passphrase != "backup passphrase" || stored.KeepCount != 5 {
+		t.Errorf("after saving without secrets: %+v, %v", stored, err)
+	}
+	saved.Password = "new secret"
+	if _, err := a.SaveBackupTarget(saved); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := a.getBackupTarget(saved.ID); stored.Password != "new secret" || stored.Passphrase != "backup passphrase" {
+		t.Errorf("after changing the password: %+v", stored)
+	}
+
+	// The database can't be decrypted while it holds credentials
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("decrypting with a backup target: %v, want a conflict", err)
+	}
+	if err := a.DeleteBackupTarget(saved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); err != nil {
+		t.Errorf("decrypting without backup targets: %v", err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	server := newWebDAVServer(t, "me", "secret")
+	target := BackupTarget{Name: "Cloud", Kind: BackupWebDAV, Endpoint: server.URL + "/dav", Folder: "work/backups",
+		Username: "me", Password: "secret", Passphrase: "backup passphrase"}
+
+	a := newEncryptedTestApp(t)
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := a.SaveBackupTarget(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := a.RunBackup(saved.ID)
+	if err != nil || run.Status != RunSuccess || run.VerifiedAt == nil {
+		t.Fatalf("backup: %+v, %v", run, err)
+	}
+	backups, err := a.GetRemoteBackups(saved.ID)
+	if err != nil || len(backups) != 1 || backups[0].Name != run.ObjectName || backups[0].SHA256 != run.SHA256 {
+		t.Fatalf("remote backups: %+v, %v", backups, err)
+	}
+	if err := a.VerifyBackup(saved.ID, run.ObjectName, "wrong passphrase"); err == nil {
+		t.Error("the backup was verified with the wrong passphrase")
+	}
+	if err := a.VerifyBackup(saved.ID, run.ObjectName, ""); err != nil {
+		t.Errorf("verifying: %v", err)
+	}
+
+	// Restoring goes back to the state of the backup and keeps the replaced database
+	if _, err := a.NewOrganization("Globex", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RestoreBackup(saved.ID, run.ObjectName, ""); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	a.db.Model(&Organization{}).Order("name").Pluck("name", &names)
+	if !reflect.DeepEqual(names, []string{"Acme"}) {
+		t.Errorf("organizations after the restore: %v, want Acme", names)
+	}
+	if _, err := os.Stat(filepath.Join(a.dbDir, encryptedDbFileName+beforeRestoreExt)); err != nil {
+		t.Errorf("the replaced database wasn't kept: %v", err)
+	}
+	if a.store == nil {
+		t.Error("the restored database isn't encrypted")
+	}
+
+	// A new installation finds the backups with the target's settings and gets the target back with them
+	fresh := newEncryptedTestApp(t)
+	if _, err := fresh.FindRemoteBackups(BackupTarget{Name: "Cloud", Kind: BackupWebDAV, Endpoint: target.Endpoint}); err == nil {
+		t.Error("backups were searched without a passphrase")
+	}
+	found, err := fresh.FindRemoteBackups(target)
+	if err != nil || len(found) != 1 || found[0].Name != run.ObjectName {
+		t.Fatalf("found %+v, %v", found, err)
+	}
+	wrong := target
+	wrong.Passphrase = "wrong passphrase"
+	if err := fresh.RestoreRemoteBackup(wrong, run.ObjectName); err == nil {
+		t.Error("a backup was restored with the wrong passphrase")
+	}
+	if err := fresh.RestoreRemoteBackup(target, run.ObjectName); err != nil {
+		t.Fatal(err)
+	}
+	targets, err := fresh.GetBackupTargets()
+	if err != nil || len(targets) != 1 || targets[0].Name != "Cloud" {
+		t.Errorf("targets after restoring on a new installation: %+v, %v", targets, err)
+	}
+	if err := fresh.db.Where("name = ?", "Acme").First(&Organization{}).Error; err != nil {
+		t.Errorf("the data wasn't restored: %v", err)
+	}
+}
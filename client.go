@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Client is who pays for the work. Several organizations can bill the same client and
+// a project can be billed to another client than its organization
+type Client struct {
+	ID             uint            `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
+	Name           string          `json:"name"`
+	BillingAddress string          `json:"billing_address"` // multi-line, printed as is
+	Notes          string          `json:"notes"`
+	Contacts       []ClientContact `json:"contacts"`
+}
+
+// ClientContact is a person to reach at a client
+type ClientContact struct {
+	ID       uint   `gorm:"primarykey" json:"id"`
+	ClientID uint   `gorm:"index" json:"client_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+}
+
+func (c Client) auditInfo() (string, uint, uint, string) {
+	return "client", c.ID, 0, ""
+}
+
+// reportScope selects the hours that go into a report and how they are labeled
+type reportScope struct {
+	kind           string // "organization" or "client", used in report titles
+	name           string // used in report titles and as {org} in export paths
+	organizationID uint   // report template to use, 0 for the default template
+	label          string // SQL expression naming the project of a row
+	address        string // billing address printed below the PDF title
+	filter         func(*gorm.DB) *gorm.DB
+}
+
+// organizationScope reports on the projects of an organization
+func (a *App) organizationScope(organizationName string) (reportScope, error) {
+	var organization Organization
+	if err := a.db.Where(&Organization{Name: organizationName}).First(&organization).Error; err != nil {
+		Logger.Println(err)
+		return reportScope{}, err
+	}
+	return reportScope{
+		kind:           "organization",
+		name:           organization.Name,
+		organizationID: organization.ID,
+		label:          "projects.name",
+		filter: func(db *gorm.DB) *gorm.DB {
+			return db.Where("projects.organization_id = ?", organization.ID)
+		},
+	}, nil
+}
+
+// clientScope reports on the projects billed to a client across organizations,
+// projects are labeled with their organization since names only are unique within one
+func (a *App) clientScope(clientID uint) (reportScope, error) {
+	client, err := a.getClient(clientID)
+	if err != nil {
+		return reportScope{}, err
+	}
+	return reportScope{
+		kind:    "client",
+		name:    client.Name,
+		address: client.BillingAddress,
+		label:   "organizations.name || ' / ' || projects.name",
+		filter: func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN organizations ON organizations.id = projects.organization_id").
+				Where("organizations.deleted_at IS NULL").
+				Where("COALESCE(projects.client_id, organizations.client_id) = ?", client.ID)
+		},
+	}, nil
+}
+
+// reportTemplate returns the PDF template of the scope's organization, client reports use the default template
+func (a *App) reportTemplate(scope reportScope) (ReportTemplate, error) {
+	if scope.organizationID == 0 {
+		return defaultReportTemplate(0), nil
+	}
+	return a.getReportTemplate(scope.organizationID)
+}
+
+func (a *App) getClient(clientID uint) (Client, error) {
+	var client Client
+	if err := a.db.Preload("Contacts").Where(&Client{ID: clientID}).First(&client).Error; err != nil {
+		return Client{}, dbError(err, "client", clientID)
+	}
+	return client, nil
+}
+
+func validateClient(client Client) error {
+	if strings.TrimSpace(client.Name) == "" {
+		return validationError("client name is empty")
+	}
+	for _, contact := range client.Contacts {
+		if strings.TrimSpace(contact.Name) == "" {
+			return validationError("contact name is empty")
+		}
+		if contact.Email != "" {
+			if _, err := parseAddressList(contact.Email); err != nil {
+				return validationError(fmt.Sprintf("invalid email for %s: %v", contact.Name, err))
+			}
+		}
+	}
+	return nil
+}
+
+// GetClients returns all clients with their contacts
+func (a *App) GetClients() (clients []Client, err error) {
+	if err := a.db.Preload("Contacts").Order("name").Find(&clients).Error; err != nil {
+		return nil, toAppError(err)
+	}
+	return clients, nil
+}
+
+// SaveClient creates the client if its ID is 0 or updates it, its contacts are replaced by the given ones
+func (a *App) SaveClient(client Client) (Client, error) {
+	client.Name = strings.TrimSpace(client.Name)
+	if err := validateClient(client); err != nil {
+		return Client{}, err
+	}
+
+	var before *Client
+	if client.ID != 0 {
+		existing, err := a.getClient(client.ID)
+		if err != nil {
+			return Client{}, err
+		}
+		before = &existing
+		client.CreatedAt = existing.CreatedAt
+	}
+
+	contacts := client.Contacts
+	client.Contacts = nil
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&client).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(&ClientContact{ClientID: client.ID}).Delete(&ClientContact{}).Error; err != nil {
+			return err
+		}
+		for i := range contacts {
+			contacts[i].ID = 0
+			contacts[i].ClientID = client.ID
+		}
+		if len(contacts) > 0 {
+			if err := tx.Create(&contacts).Error; err != nil {
+				return err
+			}
+		}
+		client.Contacts = contacts
+
+		if before == nil {
+			return recordAudit(tx, AuditManual, AuditCreate, nil, client)
+		}
+		return recordAudit(tx, AuditManual, AuditUpdate, *before, client)
+	})
+	if err != nil {
+		return Client{}, toAppError(err)
+	}
+	return client, nil
+}
+
+// DeleteClient deletes a client, its organizations and projects are no longer billed to anyone
+func (a *App) DeleteClient(clientID uint) error {
+	client, err := a.getClient(clientID)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		var organizations []Organization
+		if err := tx.Where("client_id = ?", client.ID).Find(&organizations).Error; err != nil {
+			return err
+		}
+		for _, organization := range organizations {
+			before := organization
+			organization.ClientID = nil
+			if err := tx.Save(&organization).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(tx, AuditManual, AuditUpdate, before, organization); err != nil {
+				return err
+			}
+		}
+
+		var projects []Project
+		if err := tx.Where("client_id = ?", client.ID).Find(&projects).Error; err != nil {
+			return err
+		}
+		for _, project := range projects {
+			before := project
+			project.ClientID = nil
+			if err := tx.Save(&project).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(tx, AuditManual, AuditUpdate, before, project); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where(&ClientContact{ClientID: client.ID}).Delete(&ClientContact{}).Error; err != nil {
+			return err
+		}
+		return deleteAudited[Client](tx, AuditManual, []uint{client.ID})
+	})
+	if err != nil {
+		return toAppError(err)
+	}
+	return nil
+}
+
+// clientIDPointer validates a client ID, 0 means no client
+func (a *App) clientIDPointer(clientID uint) (*uint, error) {
+	if clientID == 0 {
+		return nil, nil
+	}
+	if _, err := a.getClient(clientID); err != nil {
+		return nil, err
+	}
+	return &clientID, nil
+}
+
+// SetOrganizationClient sets the client an organization bills to, 0 removes it
+func (a *App) SetOrganizationClient(organizationID, clientID uint) (Organization, error) {
+	organization, err := a.getOrganization(organizationID)
+	if err != nil {
+		return Organization{}, err
+	}
+	before := organization
+	if organization.ClientID, err = a.clientIDPointer(clientID); err != nil {
+		return Organization{}, err
+	}
+	if err := a.saveAudited(before, &organization); err != nil {
+		return Organization{}, toAppError(err)
+	}
+	return organization, nil
+}
+
+// SetProjectClient sets the client a project bills to, 0 bills the organization's client
+func (a *App) SetProjectClient(projectID, clientID uint) (Project, error) {
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return Project{}, err
+	}
+	before := project
+	if project.ClientID, err = a.clientIDPointer(clientID); err != nil {
+		return Project{}, err
+	}
+	if err := a.saveAudited(before, &project); err != nil {
+		return Project{}, toAppError(err)
+	}
+	return project, nil
+}
+
+// GetClientWorkTimeForRange returns the seconds worked for the client per "Organization / Project" between the dates
+func (a *App) GetClientWorkTimeForRange(startDate, endDate string, clientID uint) (map[string]int, error) {
+	scope, err := a.clientScope(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.db.Table("work_hours").
+		Select(scope.label+", COALESCE(SUM(work_hours.seconds), 0)").
+		Joins("JOIN projects ON projects.id = work_hours.project_id").
+		Where("projects.deleted_at IS NULL AND work_hours.deleted_at IS NULL"). // Ignore deleted projects and hours
+		Scopes(scope.filter).
+		Where("work_hours.date >= ? AND work_hours.date <= ?", startDate, endDate).
+		Group(scope.label).
+		Rows()
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	defer rows.Close()
+
+	workTimes := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var project string
+		var seconds int
+		if err := rows.Scan(&project, &seconds); err != nil {
+			return nil, toAppError(err)
+		}
+		workTimes[project] = seconds
+		total += seconds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, toAppError(err)
+	}
+	workTimes["total"] = total
+	return workTimes, nil
+}
+
+// ExportClientByMonth exports a month of the client's hours across organizations
+func (a *App) ExportClientByMonth(exportType ExportType, clientID uint, year int, month time.Month) (string, error) {
+	scope, err := a.clientScope(clientID)
+	if err != nil {
+		return "", err
+	}
+	return a.exportScopeByMonth(exportType, scope, year, month, true)
+}
+
+// ExportClientByYear exports a year of the client's hours across organizations
+func (a *App) ExportClientByYear(exportType ExportType, clientID uint, year int) (string, error) {
+	scope, err := a.clientScope(clientID)
+	if err != nil {
+		return "", err
+	}
+	return a.exportScopeByYear(exportType, scope, year, true)
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+type ComplianceRule string
+
+const (
+	RuleDailyMax  ComplianceRule = "daily_max"
+	RuleWeeklyMax ComplianceRule = "weekly_max"
+	RuleRest      ComplianceRule = "rest"
+	RuleBreak     ComplianceRule = "break"
+)
+
+// ComplianceRules are the working-time limits of an organization's contract, a limit of 0 turns its rule off.
+// Rules are checked against the organization's own sessions and hours
+type ComplianceRules struct {
+	ID                uint      `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	OrganizationID    uint      `gorm:"uniqueIndex" json:"organization_id"`
+	Enabled           bool      `json:"enabled"`
+	MaxDailyMinutes   int       `json:"max_daily_minutes"`
+	MaxWeeklyMinutes  int       `json:"max_weekly_minutes"`
+	MinRestMinutes    int       `json:"min_rest_minutes"`    // between the end of a working day and the start of the next
+	BreakAfterMinutes int       `json:"break_after_minutes"` // longest stretch of work without a break
+	MinBreakMinutes   int       `json:"min_break_minutes"`   // shortest pause that counts as a break
+}
+
+// ComplianceViolation is a rule that was broken, weekly violations are dated on the Monday of the week
+type ComplianceViolation struct {
+	Rule          ComplianceRule `json:"rule"`
+	Date          string         `json:"date"`
+	Message       string         `json:"message"`
+	ActualSeconds int            `json:"actual_seconds"`
+	LimitSeconds  int            `json:"limit_seconds"`
+}
+
+func defaultComplianceRules(organizationID uint) ComplianceRules {
+	return ComplianceRules{
+		OrganizationID:    organizationID,
+		MaxDailyMinutes:   10 * 60,
+		MaxWeeklyMinutes:  48 * 60,
+		MinRestMinutes:    11 * 60,
+		BreakAfterMinutes: 6 * 60,
+		MinBreakMinutes:   30,
+	}
+}
+
+func validateComplianceRules(rules ComplianceRules) error {
+	for _, minutes := range []int{rules.MaxDailyMinutes, rules.MinRestMinutes, rules.BreakAfterMinutes, rules.MinBreakMinutes} {
+		if minutes < 0 || minutes > 24*60 {
+			return errors.New("daily limits must be between 0 and 1440 minutes")
+		}
+	}
+	if rules.MaxWeeklyMinutes < 0 || rules.MaxWeeklyMinutes > 7*24*60 {
+		return errors.New("the weekly limit must be between 0 and 10080 minutes")
+	}
+	return nil
+}
+
+func (a *App) getComplianceRules(organizationID uint) (ComplianceRules, error) {
+	var rules ComplianceRules
+	err := a.db.Where("organization_id = ?", organizationID).First(&rules).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultComplianceRules(organizationID), nil
+	}
+	return rules, err
+}
+
+// GetComplianceRules returns the working-time rules of the organization
+func (a *App) GetComplianceRules(organizationID uint) (ComplianceRules, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return ComplianceRules{}, err
+	}
+	rules, err := a.getComplianceRules(organizationID)
+	if err != nil {
+		return ComplianceRules{}, toAppError(err)
+	}
+	return rules, nil
+}
+
+// SaveComplianceRules validates and stores the working-time rules of an organization
+func (a *App) SaveComplianceRules(rules ComplianceRules) (ComplianceRules, error) {
+	if _, err := a.getOrganization(rules.OrganizationID); err != nil {
+		return ComplianceRules{}, err
+	}
+	if err := validateComplianceRules(rules); err != nil {
+		return ComplianceRules{}, invalid(err)
+	}
+
+	existing, err := a.getComplianceRules(rules.OrganizationID)
+	if err != nil {
+		return ComplianceRules{}, toAppError(err)
+	}
+	rules.ID = existing.ID
+	rules.CreatedAt = existing.CreatedAt
+	if err := a.db.Save(&rules).Error; err != nil {
+		return ComplianceRules{}, toAppError(err)
+	}
+	return rules, nil
+}
+
+// GetComplianceReport returns the violations of the organization's working-time rules between two dates (inclusive)
+func (a *App) GetComplianceReport(organizationID uint, startDate, endDate string) ([]ComplianceViolation, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return nil, err
+	}
+	violations, err := a.getComplianceViolations(organizationID, startDate, endDate)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return violations, nil
+}
+
+// workStretch is uninterrupted work, sessions closer together than a break are one stretch
+type workStretch struct {
+	date  string // work date of the first session
+	start time.Time
+	end   time.Time
+}
+
+// workStretches merges sessions into stretches, parallel sessions overlap and are merged as well
+func workStretches(sessions []WorkSession, minBreak time.Duration) []workStretch {
+	sorted := make([]WorkSession, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.StartedAt.IsZero() && session.EndedAt.After(session.StartedAt) {
+			sorted = append(sorted, session)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartedAt.Before(sorted[j].StartedAt)
+	})
+
+	// Switching timers leaves gaps of a few seconds, those never count as a break
+	minBreak = max(minBreak, time.Minute)
+	var stretches []workStretch
+	for _, session := range sorted {
+		last := len(stretches) - 1
+		if last >= 0 && session.StartedAt.Sub(stretches[last].end) < minBreak {
+			if session.EndedAt.After(stretches[last].end) {
+				stretches[last].end = session.EndedAt
+			}
+			continue
+		}
+		stretches = append(stretches, workStretch{date: session.Date, start: session.StartedAt, end: session.EndedAt})
+	}
+	return stretches
+}
+
+// evaluateCompliance checks the hours per day and the sessions against the rules
+func evaluateCompliance(rules ComplianceRules, days map[string]int, sessions []WorkSession) []ComplianceViolation {
+	var violations []ComplianceViolation
+
+	if limit := rules.MaxDailyMinutes * 60; limit > 0 {
+		for date, seconds := range days {
+			if seconds > limit {
+				violations = append(violations, ComplianceViolation{
+					Rule:          RuleDailyMax,
+					Date:          date,
+					Message:       fmt.Sprintf("%.2fh worked, maximum %.2fh", secondsToHours(seconds), secondsToHours(limit)),
+					ActualSeconds: seconds,
+					LimitSeconds:  limit,
+				})
+			}
+		}
+	}
+
+	if limit := rules.MaxWeeklyMinutes * 60; limit > 0 {
+		weeks := make(map[string]int)
+		for date, seconds := range days {
+			weeks[weekStart(date)] += seconds
+		}
+		for monday, seconds := range weeks {
+			if seconds > limit {
+				violations = append(violations, ComplianceViolation{
+					Rule:          RuleWeeklyMax,
+					Date:          monday,
+					Message:       fmt.Sprintf("%.2fh worked in the week, maximum %.2fh", secondsToHours(seconds), secondsToHours(limit)),
+					ActualSeconds: seconds,
+					LimitSeconds:  limit,
+				})
+			}
+		}
+	}
+
+	stretches := workStretches(sessions, time.Duration(rules.MinBreakMinutes)*time.Minute)
+	if limit := rules.BreakAfterMinutes * 60; limit > 0 {
+		for _, stretch := range stretches {
+			if seconds := int(stretch.end.Sub(stretch.start).Seconds()); seconds > limit {
+				violations = append(violations, ComplianceViolation{
+					Rule:          RuleBreak,
+					Date:          stretch.date,
+					Message:       fmt.Sprintf("%.2fh without a %d min break, due after %.2fh", secondsToHours(seconds), rules.MinBreakMinutes, secondsToHours(limit)),
+					ActualSeconds: seconds,
+					LimitSeconds:  limit,
+				})
+			}
+		}
+	}
+	if limit := rules.MinRestMinutes * 60; limit > 0 {
+		for i := 1; i < len(stretches); i++ {
+			previous, next := stretches[i-1], stretches[i]
+			if previous.date == next.date {
+				continue // a break within the working day
+			}
+			if seconds := int(next.start.Sub(previous.end).Seconds()); seconds < limit {
+				violations = append(violations, ComplianceViolation{
+					Rule:          RuleRest,
+					Date:          next.date,
+					Message:       fmt.Sprintf("%.2fh rest since the previous working day, minimum %.2fh", secondsToHours(seconds), secondsToHours(limit)),
+					ActualSeconds: seconds,
+					LimitSeconds:  limit,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Date != violations[j].Date {
+			return violations[i].Date < violations[j].Date
+		}
+		return violations[i].Rule < violations[j].Rule
+	})
+	return violations
+}
+
+// weekStart returns the Monday of the date's week
+func weekStart(date string) string {
+	day, _ := time.Parse("2006-01-02", date)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7).Format("2006-01-02")
+}
+
+// getComplianceViolations returns the violations dated between two dates (inclusive), the weeks and
+// the working day before the range are loaded too so weekly limits and rest periods are complete
+func (a *App) getComplianceViolations(organizationID uint, startDate, endDate string) ([]ComplianceViolation, error) {
+	rules, err := a.getComplianceRules(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !rules.Enabled {
+		return nil, nil
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, err
+	}
+	from := weekStart(startDate)
+	to := end.AddDate(0, 0, 6-(int(end.Weekday())+6)%7).Format("2006-01-02")
+	monday, _ := time.Parse("2006-01-02", from)
+	sessionsFrom := monday.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var rows []struct {
+		Date    string
+		Seconds int
+	}
+	err = a.db.Table("work_hours").
+		Select("work_hours.date, SUM(work_hours.seconds) AS seconds").
+		Joins("JOIN projects ON projects.id = work_hours.project_id").
+		Where("projects.deleted_at IS NULL AND work_hours.deleted_at IS NULL"). // Ignore deleted projects and merged duplicates
+		Where("projects.organization_id = ?", organizationID).
+		Where("work_hours.date >= ? AND work_hours.date <= ?", from, to).
+		Group("work_hours.date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	days := make(map[string]int)
+	for _, row := range rows {
+		days[row.Date] = row.Seconds
+	}
+
+	var sessions []WorkSession
+	err = a.db.Joins("JOIN projects ON projects.id = work_sessions.project_id").
+		Where("projects.deleted_at IS NULL AND projects.organization_id = ?", organizationID).
+		Where("work_sessions.date >= ? AND work_sessions.date <= ?", sessionsFrom, endDate).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []ComplianceViolation
+	for _, violation := range evaluateCompliance(rules, days, sessions) {
+		// Weeks are reported when they overlap the range, they all start on or after the first Monday loaded
+		inRange := violation.Date <= endDate && (violation.Rule == RuleWeeklyMax || violation.Date >= startDate)
+		if inRange {
+			violations = append(violations, violation)
+		}
+	}
+	return violations, nil
+}
+
+// scopeCompliance returns the violations in an organization report, other reports have none
+func (a *App) scopeCompliance(scope reportScope, startDate, endDate string) ([]ComplianceViolation, error) {
+	if scope.organizationID == 0 || scope.kind != "organization" {
+		return nil, nil
+	}
+	return a.getComplianceViolations(scope.organizationID, startDate, endDate)
+}
+
+// checkCompliance warns about the rules the running timer breaks today, each violation is reported once
+func (a *App) checkCompliance() {
+	if !a.isRunning {
+		return
+	}
+	today := dateIn(time.Now(), a.location)
+	violations, err := a.getComplianceViolations(a.organization.ID, today, today)
+	if err != nil {
+		Logger.Println(err)
+		return
+	}
+	for _, violation := range violations {
+		key := fmt.Sprintf("%d/%s/%s", a.organization.ID, violation.Rule, violation.Date)
+		if a.complianceWarned[key] {
+			continue
+		}
+		if a.complianceWarned == nil {
+			a.complianceWarned = make(map[string]bool)
+		}
+		a.complianceWarned[key] = true
+		runtime.EventsEmit(a.ctx, "compliance-warning", violation)
+	}
+}
+
+var complianceRuleNames = map[ComplianceRule]string{
+	RuleDailyMax:  "Daily maximum",
+	RuleWeeklyMax: "Weekly maximum",
+	RuleRest:      "Rest period",
+	RuleBreak:     "Break",
+}
+
+// complianceCells returns the date, rule and details of a violation for report rows
+func complianceCells(violation ComplianceViolation) []string {
+	return []string{violation.Date, complianceRuleNames[violation.Rule], violation.Message}
+}
+
+// complianceCounts returns the number of violations per month and rule, in month order
+func complianceCounts(violations []ComplianceViolation) [][]string {
+	counts := make(map[string]map[ComplianceRule]int)
+	var months []string
+	for _, violation := range violations {
+		month := monthLabel(violation.Date)
+		if counts[month] == nil {
+			counts[month] = make(map[ComplianceRule]int)
+			months = append(months, month)
+		}
+		counts[month][violation.Rule]++
+	}
+
+	var rows [][]string
+	for _, month := range months {
+		for _, rule := range []ComplianceRule{RuleDailyMax, RuleWeeklyMax, RuleRest, RuleBreak} {
+			if count := counts[month][rule]; count > 0 {
+				rows = append(rows, []string{month, complianceRuleNames[rule], fmt.Sprintf("%d", count)})
+			}
+		}
+	}
+	return rows
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testSession returns a session starting at a time on its date, in UTC, lasting the given minutes
+func testSession(date, start string, minutes int) WorkSession {
+	startedAt, err := time.Parse("2006-01-02 15:04:05", date+" "+start)
+	if err != nil {
+		startedAt, err = time.Parse("2006-01-02 15:04", date+" "+start)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return WorkSession{Date: date, Seconds: minutes * 60, StartedAt: startedAt, EndedAt: startedAt.Add(time.Duration(minutes) * time.Minute)}
+}
+
+// violationKeys reduces violations to "rule date actual-minutes" for comparison
+func violationKeys(violations []ComplianceViolation) []string {
+	keys := []string{}
+	for _, violation := range violations {
+		keys = append(keys, fmt.Sprintf("%s %s %d", violation.Rule, violation.Date, violation.ActualSeconds/60))
+	}
+	return keys
+}
+
+func TestEvaluateCompliance(t *testing.T) {
+	rules := defaultComplianceRules(1) // 10h a day, 48h a week, 11h rest, a 30 min break after 6h
+	hours := func(h float64) int { return int(h * 3600) }
+
+	tests := []struct {
+		name     string
+		rules    ComplianceRules
+		days     map[string]int
+		sessions []WorkSession
+		want     []string
+	}{
+		{
+			name:  "daily maximum",
+			rules: rules,
+			days:  map[string]int{"2026-03-02": hours(10), "2026-03-03": hours(10.5)},
+			want:  []string{"daily_max 2026-03-03 630"},
+		},
+		{
+			name:  "weekly maximum dated on the Monday",
+			rules: rules,
+			// Monday to Sunday is one week, the next Monday starts another
+			days: map[string]int{"2026-03-02": hours(10), "2026-03-03": hours(10), "2026-03-04": hours(10),
+				"2026-03-05": hours(10), "2026-03-08": hours(8.5), "2026-03-09": hours(10)},
+			want: []string{"weekly_max 2026-03-02 2910"},
+		},
+		{
+			name:     "rest across midnight",
+			rules:    rules,
+			sessions: []WorkSession{testSession("2026-03-02", "18:00", 5*60+30), testSession("2026-03-03", "06:00", 60)},
+			want:     []string{"rest 2026-03-03 390"},
+		},
+		{
+			name:  "a session running past midnight counts for the day it started",
+			rules: rules,
+			sessions: []WorkSession{testSession("2026-03-02", "21:00", 4*60), // until 01:00
+				testSession("2026-03-03", "09:00", 4*60)},
+			want: []string{"rest 2026-03-03 480"},
+		},
+		{
+			name:     "enough rest",
+			rules:    rules,
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 5*60), testSession("2026-03-03", "08:00", 60)},
+			want:     []string{},
+		},
+		{
+			name:     "breaks within a day aren't rest",
+			rules:    rules,
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 3*60), testSession("2026-03-02", "13:00", 3*60)},
+			want:     []string{},
+		},
+		{
+			name:     "a pause shorter than the break doesn't count",
+			rules:    rules,
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 4*60), testSession("2026-03-02", "12:10", 3*60)},
+			want:     []string{"break 2026-03-02 430"},
+		},
+		{
+			name:     "a pause as long as the break counts",
+			rules:    rules,
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 4*60), testSession("2026-03-02", "12:30", 3*60)},
+			want:     []string{},
+		},
+		{
+			name:  "gaps of seconds never count as a break",
+			rules: ComplianceRules{BreakAfterMinutes: 6 * 60},
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 4*60),
+				testSession("2026-03-02", "12:00:30", 3*60)},
+			want: []string{"break 2026-03-02 420"},
+		},
+		{
+			name:  "parallel sessions are merged",
+			rules: rules,
+			sessions: []WorkSession{testSession("2026-03-02", "08:00", 5*60), testSession("2026-03-02", "09:00", 60),
+				testSession("2026-03-02", "12:50", 2*60)},
+			want: []string{"break 2026-03-02 410"},
+		},
+		{
+			name:     "sessions without times are skipped",
+			rules:    rules,
+			sessions: []WorkSession{{Date: "2026-03-02", Seconds: 8 * 3600}},
+			want:     []string{},
+		},
+		{
+			name:  "limits of 0 are off",
+			rules: ComplianceRules{},
+			days:  map[string]int{"2026-03-02": hours(16)},
+			sessions: []WorkSession{testSession("2026-03-02", "06:00", 16*60),
+				testSession("2026-03-03", "00:00", 60)},
+			want: []string{},
+		},
+		{
+			name:  "sorted by date then rule",
+			rules: rules,
+			days:  map[string]int{"2026-03-02": hours(11), "2026-03-03": hours(11)},
+			sessions: []WorkSession{testSession("2026-03-02", "10:00", 11*60),
+				testSession("2026-03-03", "06:00", 11*60)},
+			want: []string{"break 2026-03-02 660", "daily_max 2026-03-02 660",
+				"break 2026-03-03 660", "daily_max 2026-03-03 660", "rest 2026-03-03 540"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := violationKeys(evaluateCompliance(test.rules, test.days, test.sessions))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("violations = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := map[string]string{
+		"2026-03-02": "2026-03-02", // Monday
+		"2026-03-08": "2026-03-02", // Sunday
+		"2026-03-01": "2026-02-23",
+		"2026-01-01": "2025-12-29",
+	}
+	for date, want := range tests {
+		if got := weekStart(date); got != want {
+			t.Errorf("weekStart(%s) = %s, want %s", date, got, want)
+		}
+	}
+}
+
+func TestGetComplianceViolations(t *testing.T) {
+	a := newTestApp(t)
+	created, err := a.NewOrganization("Acme", "Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	organizationID, projectID := created.Organization.ID, created.Project.ID
+
+	// Sunday evening until late, then Monday morning: the rest falls short on Monday
+	sessions := []WorkSession{testSession("2026-03-01", "18:00", 5*60), testSession("2026-03-02", "07:00", 11*60)}
+	for _, session := range sessions {
+		session.ProjectID = projectID
+		if err := a.db.Create(&session).Error; err != nil {
+			t.Fatal(err)
+		}
+		hours := WorkHours{Date: session.Date, Seconds: session.Seconds, ProjectID: projectID}
+		if err := a.db.Create(&hours).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rules are off until enabled
+	violations, err := a.getComplianceViolations(organizationID, "2026-03-02", "2026-03-02")
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("disabled rules: %v, %v", violations, err)
+	}
+
+	rules := defaultComplianceRules(organizationID)
+	rules.Enabled = true
+	if _, err := a.SaveComplianceRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	// The working day before the range is loaded for the rest period, its own violations aren't reported
+	violations, err = a.getComplianceViolations(organizationID, "2026-03-02", "2026-03-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"break 2026-03-02 660", "daily_max 2026-03-02 660", "rest 2026-03-02 480"}
+	if got := violationKeys(violations); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %q, want %q", got, want)
+	}
+}
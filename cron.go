@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed 5 field cron expression: minute hour day-of-month month day-of-week.
+// Fields support "*", lists ("1,15"), ranges ("1-5") and steps ("*/15", "0-30/10")
+type cronSchedule struct {
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+	// Like cron, when both day fields are restricted a day matches if either of them does
+	anyDay     bool
+	anyWeekday bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Shortcuts accepted in place of a full expression
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+func parseCron(expression string) (cronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[expression]; ok {
+		expression = macro
+	}
+
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return cronSchedule{}, fmt.Errorf("cron expression %q must have %d fields", expression, len(cronFields))
+	}
+
+	var sets []map[int]bool
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return cronSchedule{}, err
+		}
+		sets = append(sets, set)
+	}
+
+	// 7 is an alias for Sunday
+	if sets[4][7] {
+		sets[4][0] = true
+		delete(sets[4], 7)
+	}
+
+	return cronSchedule{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     parts[2] == "*",
+		anyWeekday: parts[4] == "*",
+	}, nil
+}
+
+func parseCronField(part string, field cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	max := field.max
+	if field.name == "day of week" {
+		max = 7
+	}
+
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if idx := strings.Index(item, "/"); idx != -1 {
+			var err error
+			rangePart = item[:idx]
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %s field %q", field.name, item)
+			}
+		}
+
+		start, end := field.min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s field %q", field.name, item)
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s field %q", field.name, item)
+				}
+			} else if step > 1 {
+				// "5/15" means every 15 starting at 5
+				end = max
+			}
+		}
+		if start < field.min || end > max || start > end {
+			return nil, fmt.Errorf("%s field %q out of range %d-%d", field.name, item, field.min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
+
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dayMatch := c.days[t.Day()]
+	weekdayMatch := c.weekdays[int(t.Weekday())]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekdayMatch
+	case c.anyWeekday:
+		return dayMatch
+	default:
+		return dayMatch || weekdayMatch
+	}
+}
+
+// next returns the first time strictly after t matching the schedule, in t's location.
+// Times skipped when the clocks go forward don't occur, times repeated when they go back occur once.
+// Returns the zero time if nothing matches within the next 5 years (e.g. "0 0 31 2 *")
+func (c cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			// Added rather than built with time.Date, which may pick either instance of a repeated hour
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if !c.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		if earlier := t.Add(-time.Hour); earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute() {
+			// The hour repeated when the clocks go back, it already ran the first time round
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    bool
+	}{
+		{"* * * * *", false},
+		{"@monthly", false},
+		{"*/15 9-17 * * 1-5", false},
+		{"0 0 1,15 * *", false},
+		{"5/20 * * * *", false},
+		{"0 0 * * 7", false},
+		{"0 0 * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"5-1 * * * *", true},
+		{"a * * * *", true},
+		{"@sometimes", true},
+	}
+	for _, test := range tests {
+		_, err := parseCron(test.expression)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseCron(%q) error = %v, want error %v", test.expression, err, test.wantErr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	at := func(loc *time.Location, value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		after      time.Time
+		want       []string // consecutive occurrences
+	}{
+		{"every minute", "* * * * *", at(time.UTC, "2026-01-01 10:00"), []string{"2026-01-01 10:01", "2026-01-01 10:02"}},
+		{"step", "*/20 * * * *", at(time.UTC, "2026-01-01 10:05"), []string{"2026-01-01 10:20", "2026-01-01 10:40", "2026-01-01 11:00"}},
+		{"step from", "5/20 * * * *", at(time.UTC, "2026-01-01 10:05"), []string{"2026-01-01 10:25", "2026-01-01 10:45", "2026-01-01 11:05"}},
+		{"range with step", "0 8-12/2 * * *", at(time.UTC, "2026-01-01 09:00"), []string{"2026-01-01 10:00", "2026-01-01 12:00", "2026-01-02 08:00"}},
+		{"list", "0 0 1,15 * *", at(time.UTC, "2026-01-02 00:00"), []string{"2026-01-15 00:00", "2026-02-01 00:00"}},
+		{"weekdays", "30 9 * * 1-5", at(time.UTC, "2026-01-02 10:00"), []string{"2026-01-05 09:30", "2026-01-06 09:30"}}, // a Friday
+		{"sunday as 7", "0 0 * * 7", at(time.UTC, "2026-01-01 00:00"), []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+		// Both day fields restricted: either one matches, the 13th or any Friday
+		{"day of month or week", "0 0 13 * 5", at(time.UTC, "2026-01-01 00:00"), []string{"2026-01-02 00:00", "2026-01-09 00:00", "2026-01-13 00:00", "2026-01-16 00:00"}},
+		{"monthly", "@monthly", at(time.UTC, "2026-01-31 23:59"), []string{"2026-02-01 00:00", "2026-03-01 00:00"}},
+		{"yearly", "@yearly", at(time.UTC, "2026-06-01 00:00"), []string{"2027-01-01 00:00"}},
+		{"leap day", "0 0 29 2 *", at(time.UTC, "2026-01-01 00:00"), []string{"2028-02-29 00:00"}},
+		{"never", "0 0 31 2 *", at(time.UTC, "2026-01-01 00:00"), []string{""}},
+		// 02:30 doesn't exist on the day the clocks go forward
+		{"dst forward", "30 2 * * *", at(berlin, "2026-03-28 12:00"), []string{"2026-03-30 02:30"}},
+		{"dst forward hourly", "0 * * * *", at(berlin, "2026-03-29 01:30"), []string{"2026-03-29 03:00", "2026-03-29 04:00"}},
+		// 02:30 happens twice on the day the clocks go back, it runs once
+		{"dst back", "30 2 * * *", at(berlin, "2026-10-25 00:00"), []string{"2026-10-25 02:30", "2026-10-26 02:30"}},
+		{"in location", "0 9 * * *", at(berlin, "2026-07-01 10:00"), []string{"2026-07-02 09:00"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cron, err := parseCron(test.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current := test.after
+			for _, want := range test.want {
+				current = cron.next(current)
+				got := ""
+				if !current.IsZero() {
+					got = current.Format("2006-01-02 15:04")
+					if current.Location() != test.after.Location() {
+						t.Errorf("next is in %s, want %s", current.Location(), test.after.Location())
+					}
+				}
+				if got != want {
+					t.Fatalf("next = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronNextDSTBackElapsed(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	cron, err := parseCron("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := cron.next(time.Date(2026, 10, 25, 0, 0, 0, 0, berlin))
+	if _, offset := first.Zone(); offset != 2*3600 {
+		t.Fatalf("first 02:30 has offset %d, want summer time", offset)
+	}
+	// Starting between the two 02:30s must not run the repeated one
+	if next := cron.next(first.Add(10 * time.Minute)); next.Day() != 26 {
+		t.Errorf("next = %s, want the next day", next)
+	}
+}
+
+func TestLatestPerPeriod(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 6, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name        string
+		period      ReportPeriod
+		occurrences []time.Time
+		want        []time.Time
+	}{
+		{"empty", PeriodMonth, nil, nil},
+		{"single", PeriodMonth, []time.Time{day(3, 1)}, []time.Time{day(3, 1)}},
+		{"a week of a daily schedule", PeriodMonth,
+			[]time.Time{day(3, 3), day(3, 4), day(3, 5), day(3, 6), day(3, 7), day(3, 8), day(3, 9)},
+			[]time.Time{day(3, 9)}},
+		{"across months", PeriodMonth,
+			[]time.Time{day(2, 27), day(2, 28), day(3, 1), day(3, 2)},
+			[]time.Time{day(2, 28), day(3, 2)}},
+		{"monthly schedule", PeriodMonth,
+			[]time.Time{day(1, 1), day(2, 1), day(3, 1)},
+			[]time.Time{day(1, 1), day(2, 1), day(3, 1)}},
+		{"yearly report", PeriodYear,
+			[]time.Time{day(1, 1), day(2, 1), day(3, 1)},
+			[]time.Time{day(3, 1)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := latestPerPeriod(test.period, test.occurrences)
+			if len(got) != len(test.want) {
+				t.Fatalf("latestPerPeriod = %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if !got[i].Equal(test.want[i]) {
+					t.Errorf("latestPerPeriod[%d] = %s, want %s", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
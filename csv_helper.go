@@ -5,38 +5,31 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func (a *App) exportCSVByMonth(organization string, year int, month time.Month) (string, error) {
-	MonthlyTotals, err := a.getMonthlyTotals(organization, year, month)
+func (a *App) exportCSVByMonth(scope reportScope, year int, month time.Month, interactive bool) (string, error) {
+	MonthlyTotals, err := a.getMonthlyTotals(scope, year, month)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 
-	// Get the save directory
-	dbDir, err := getSaveDir(a.environment)
+	// Resolve the output file from the export settings
+	csvFilePath, err := a.exportFilePath(CSV, scope, year, month, interactive)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-
-	// Create the directories for the organization, year, and month
-	dir := filepath.Join(dbDir, "csv", organization, strconv.Itoa(year), month.String())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Println(err)
-		return "", err
+	if csvFilePath == "" {
+		// Save dialog was cancelled
+		return "", nil
 	}
 
 	// Create the CSV file
-	dateStr := fmt.Sprintf("%d-%s", year, month.String())
-	csvFileName := fmt.Sprintf("work_hours_%s.csv", dateStr)
-	csvFilePath := filepath.Join(dir, csvFileName)
 	csvFile, err := os.Create(csvFilePath)
 	if err != nil {
 		log.Println(err)
@@ -48,27 +41,40 @@ func (a *App) exportCSVByMonth(organization string, year int, month time.Month)
 	defer writer.Flush()
 
 	// Write the monthly total to the CSV file
-	writer.Write([]string{"Month total for " + organization})
-	writer.Write([]string{"Month", "Hours", "Time (HH:MM:SS)"})
+	writer.Write([]string{"Month total for " + scope.name})
+	rounded := MonthlyTotals.Rounded
+	writer.Write(append([]string{"Month", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	timeStr := formatTime(MonthlyTotals.MonthlyTotal)
 	monthlyHours := secondsToHours(MonthlyTotals.MonthlyTotal)
-	writer.Write([]string{month.String(), fmt.Sprintf("%.2f", monthlyHours), timeStr})
+	writer.Write(append([]string{month.String(), fmt.Sprintf("%.2f", monthlyHours), timeStr}, rounded.cells(rounded.Total)...))
 
 	// Write the monthly totals per project to the CSV file
 	writer.Write([]string{})
 	writer.Write([]string{"Monthly breakdown"})
-	writer.Write([]string{"Project", "Hours", "Time (HH:MM:SS)"})
+	writer.Write(append([]string{"Project", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	for _, projectTotal := range MonthlyTotals.ProjectTotals {
 		timeStr := formatTime(projectTotal.Seconds)
 		projectHours := secondsToHours(projectTotal.Seconds)
-		writer.Write([]string{projectTotal.Name, fmt.Sprintf("%.2f", projectHours), timeStr})
+		writer.Write(append([]string{projectTotal.Name, fmt.Sprintf("%.2f", projectHours), timeStr}, rounded.cells(rounded.ProjectTotals[projectTotal.Name])...))
+	}
+
+	// Write the monthly totals per task to the CSV file
+	if len(MonthlyTotals.TaskTotals) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Task breakdown"})
+		writer.Write([]string{"Project", "Task", "Hours", "Time (HH:MM:SS)"})
+		for _, taskTotal := range MonthlyTotals.TaskTotals {
+			timeStr := formatTime(taskTotal.Seconds)
+			taskHours := secondsToHours(taskTotal.Seconds)
+			writer.Write([]string{taskTotal.Project, taskTotal.Task, fmt.Sprintf("%.2f", taskHours), timeStr})
+		}
 	}
 
 	// Write the weekly totals to the CSV file
 	weekRanges := getWeekRanges(year, month)
 	writer.Write([]string{})
 	writer.Write([]string{"Weekly breakdown"})
-	writer.Write([]string{"Week", "Project", "Hours", "Time (HH:MM:SS)"})
+	writer.Write(append([]string{"Week", "Project", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	for week := 1; week <= 5; week++ {
 		projectTotals, ok := MonthlyTotals.WeeklyTotals[week]
 		if !ok {
@@ -77,49 +83,91 @@ func (a *App) exportCSVByMonth(organization string, year int, month time.Month)
 		for project, seconds := range projectTotals {
 			timeStr := formatTime(seconds)
 			projectHours := secondsToHours(seconds)
-			writer.Write([]string{fmt.Sprintf("(%s)", weekRanges[week]), project, fmt.Sprintf("%.2f", projectHours), timeStr})
+			writer.Write(append([]string{fmt.Sprintf("(%s)", weekRanges[week]), project, fmt.Sprintf("%.2f", projectHours), timeStr}, rounded.cells(rounded.WeeklyTotals[week][project])...))
 		}
 	}
 
 	// Write the daily totals to the CSV file
 	writer.Write([]string{})
 	writer.Write([]string{"Daily breakdown"})
-	writer.Write([]string{"Date", "Project", "Hours", "Time (HH:MM:SS)"})
+	writer.Write(append([]string{"Date", "Project", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	for _, date := range MonthlyTotals.Dates {
 		for project, seconds := range MonthlyTotals.DailyTotals[date] {
 			timeStr := formatTime(seconds)
 			projectHours := secondsToHours(seconds)
-			writer.Write([]string{date, project, fmt.Sprintf("%.2f", projectHours), timeStr})
+			writer.Write(append([]string{date, project, fmt.Sprintf("%.2f", projectHours), timeStr}, rounded.cells(rounded.DailyTotals[date][project])...))
+		}
+	}
+	// Time tracked on several projects at once is counted for each of them, list it so it can be checked
+	if len(MonthlyTotals.Overlaps) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Overlapping time (counted once per project)"})
+		writer.Write([]string{"Date", "From", "To", "Projects", "Hours", "Time (HH:MM:SS)"})
+		for _, overlap := range MonthlyTotals.Overlaps {
+			writer.Write(append([]string{overlap.Date}, overlapCells(overlap)...))
+		}
+	}
+	if MonthlyTotals.Overtime.Enabled {
+		writer.Write([]string{})
+		writer.Write([]string{"Overtime"})
+		writer.Write([]string{"Week", "Expected hours", "Absence hours", "Actual hours", "Difference (hours)"})
+		for _, row := range overtimeRows(MonthlyTotals.Overtime, weekLabel) {
+			writer.Write(row)
+		}
+		for _, row := range overtimeBalanceRows(MonthlyTotals.Overtime, month.String()) {
+			writer.Write(row)
 		}
 	}
-	runtime.ClipboardSetText(a.ctx, csvFilePath)
+	if len(MonthlyTotals.Absences) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Absences"})
+		writer.Write([]string{"Date", "Type", "Name", "Hours"})
+		for _, absence := range MonthlyTotals.Absences {
+			writer.Write(absenceCells(absence))
+		}
+	}
+	if len(MonthlyTotals.Compliance) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Working-time violations"})
+		writer.Write([]string{"Date", "Rule", "Details"})
+		for _, violation := range MonthlyTotals.Compliance {
+			writer.Write(complianceCells(violation))
+		}
+	}
+	if len(MonthlyTotals.Locks) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Period status"})
+		writer.Write([]string{"Period", "Status", "Since", "Note"})
+		for _, lock := range MonthlyTotals.Locks {
+			writer.Write(lockCells(lock))
+		}
+	}
+	writeReconciliation(writer, rounded, MonthlyTotals.MonthlyTotal)
+	if interactive {
+		runtime.ClipboardSetText(a.ctx, csvFilePath)
+	}
 	return csvFilePath, nil
 }
 
-func (a *App) exportCSVByYear(organization string, year int) (string, error) {
-	YearlyTotals, err := a.getYearlyTotals(organization, year)
+func (a *App) exportCSVByYear(scope reportScope, year int, interactive bool) (string, error) {
+	YearlyTotals, err := a.getYearlyTotals(scope, year)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 
-	// Get the save directory
-	dbDir, err := getSaveDir(a.environment)
+	// Resolve the output file from the export settings
+	csvFilePath, err := a.exportFilePath(CSV, scope, year, 0, interactive)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-
-	// Create the directories for the organization and year
-	dir := filepath.Join(dbDir, "csv", organization, strconv.Itoa(year))
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Println(err)
-		return "", err
+	if csvFilePath == "" {
+		// Save dialog was cancelled
+		return "", nil
 	}
 
 	// Create the CSV file
-	csvFileName := fmt.Sprintf("work_hours_%d.csv", year)
-	csvFilePath := filepath.Join(dir, csvFileName)
 	csvFile, err := os.Create(csvFilePath)
 	if err != nil {
 		log.Println(err)
@@ -131,26 +179,27 @@ func (a *App) exportCSVByYear(organization string, year int) (string, error) {
 	defer writer.Flush()
 
 	// Write the yearly total to the CSV file
-	writer.Write([]string{"Yearly total for " + organization})
-	writer.Write([]string{"Year", "Hours", "Time (HH:MM:SS)"})
+	writer.Write([]string{"Yearly total for " + scope.name})
+	rounded := YearlyTotals.Rounded
+	writer.Write(append([]string{"Year", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	timeStr := formatTime(YearlyTotals.YearlyTotal)
 	yearlyHours := secondsToHours(YearlyTotals.YearlyTotal)
-	writer.Write([]string{strconv.Itoa(year), fmt.Sprintf("%.2f", yearlyHours), timeStr})
+	writer.Write(append([]string{strconv.Itoa(year), fmt.Sprintf("%.2f", yearlyHours), timeStr}, rounded.cells(rounded.Total)...))
 
 	// Write the yearly totals per project to the CSV file
 	writer.Write([]string{})
 	writer.Write([]string{"Yearly breakdown"})
-	writer.Write([]string{"Project", "Hours", "Time (HH:MM:SS)"})
+	writer.Write(append([]string{"Project", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	for _, yearlyTotal := range YearlyTotals.ProjectTotals {
 		timeStr := formatTime(yearlyTotal.Seconds)
 		projectHours := secondsToHours(yearlyTotal.Seconds)
-		writer.Write([]string{yearlyTotal.Name, fmt.Sprintf("%.2f", projectHours), timeStr})
+		writer.Write(append([]string{yearlyTotal.Name, fmt.Sprintf("%.2f", projectHours), timeStr}, rounded.cells(rounded.ProjectTotals[yearlyTotal.Name])...))
 	}
 
 	// Write the monthly totals to the CSV file
 	writer.Write([]string{})
 	writer.Write([]string{"Monthly breakdown"})
-	writer.Write([]string{"Month", "Project", "Hours", "Time (HH:MM:SS)"})
+	writer.Write(append([]string{"Month", "Project", "Hours", "Time (HH:MM:SS)"}, rounded.headers()...))
 	for mIdx := time.January; mIdx <= time.December; mIdx++ {
 		month := monthMap[int(mIdx)]
 		if _, ok := YearlyTotals.MonthlyTotals[month]; !ok {
@@ -172,9 +221,58 @@ func (a *App) exportCSVByYear(organization string, year int) (string, error) {
 		for project, seconds := range projectTotals {
 			timeStr := formatTime(seconds)
 			projectHours := secondsToHours(seconds)
-			writer.Write([]string{month, project, fmt.Sprintf("%.2f", projectHours), timeStr})
+			writer.Write(append([]string{month, project, fmt.Sprintf("%.2f", projectHours), timeStr}, rounded.cells(rounded.MonthlyTotals[month][project])...))
 		}
 	}
-	runtime.ClipboardSetText(a.ctx, csvFilePath)
+	if YearlyTotals.Overtime.Enabled {
+		writer.Write([]string{})
+		writer.Write([]string{"Overtime"})
+		writer.Write([]string{"Month", "Expected hours", "Absence hours", "Actual hours", "Difference (hours)"})
+		for _, row := range overtimeRows(YearlyTotals.Overtime, monthLabel) {
+			writer.Write(row)
+		}
+		for _, row := range overtimeBalanceRows(YearlyTotals.Overtime, strconv.Itoa(year)) {
+			writer.Write(row)
+		}
+	}
+	if len(YearlyTotals.Leave) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Leave"})
+		writer.Write([]string{"Type", "Allowance (days)", "Carried over (days)", "Taken (days)", "Remaining (days)"})
+		for _, balance := range YearlyTotals.Leave {
+			writer.Write(leaveCells(balance))
+		}
+	}
+	if len(YearlyTotals.Compliance) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Working-time violations"})
+		writer.Write([]string{"Month", "Rule", "Violations"})
+		for _, row := range complianceCounts(YearlyTotals.Compliance) {
+			writer.Write(row)
+		}
+	}
+	if len(YearlyTotals.Locks) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Period status"})
+		writer.Write([]string{"Period", "Status", "Since", "Note"})
+		for _, lock := range YearlyTotals.Locks {
+			writer.Write(lockCells(lock))
+		}
+	}
+	writeReconciliation(writer, rounded, YearlyTotals.YearlyTotal)
+	if interactive {
+		runtime.ClipboardSetText(a.ctx, csvFilePath)
+	}
 	return csvFilePath, nil
 }
+
+// writeReconciliation writes the difference between the raw and the billable total when time is rounded
+func writeReconciliation(writer *csv.Writer, rounded RoundedTotals, raw int) {
+	if !rounded.Enabled {
+		return
+	}
+	writer.Write([]string{})
+	writer.Write([]string{"Rounding reconciliation"})
+	writer.Write([]string{"Policy", "Raw hours", "Billable hours", "Difference (hours)"})
+	writer.Write(rounded.reconciliation(raw))
+}
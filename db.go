@@ -2,7 +2,6 @@ package main
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -14,13 +13,17 @@ import (
 )
 
 type Organization struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
-	Name      string         `json:"name"`
-	Favorite  bool           `json:"favorite"`
-	Projects  []Project      `json:"projects"`
+	ID         uint           `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	UID        string         `gorm:"size:36" json:"uid"` // identifies the row across synced devices, see sync.go
+	Name       string         `json:"name"`
+	Favorite   bool           `json:"favorite"`
+	Timezone   string         `json:"timezone"`    // IANA zone used to bucket tracked time into days
+	ArchivedAt *time.Time     `json:"archived_at"` // archived organizations are hidden but keep their history
+	ClientID   *uint          `gorm:"index" json:"client_id"`
+	Projects   []Project      `json:"projects"`
 }
 
 type Project struct {
@@ -28,9 +31,12 @@ type Project struct {
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	UID            string         `gorm:"size:36" json:"uid"`
 	Name           string         `json:"name"`
 	OrganizationID uint           `json:"organization_id"`
 	Favorite       bool           `json:"favorite"`
+	ArchivedAt     *time.Time     `json:"archived_at"`
+	ClientID       *uint          `gorm:"index" json:"client_id"` // overrides the organization's client
 	WorkHours      []WorkHours    `json:"work_hours"`
 }
 
@@ -49,15 +55,22 @@ type WorkSession struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	UID       string         `gorm:"size:36" json:"uid"`
 	Date      string         `json:"date"`
 	Seconds   int            `json:"seconds"`
 	ProjectID uint           `json:"project_id"`
+	TaskID    *uint          `gorm:"index" json:"task_id"` // nil when no task was tracked
+	Parallel  bool           `json:"parallel"`             // recorded by a parallel timer, see timers.go
+	StartedAt time.Time      `json:"started_at"`           // stored in UTC
+	EndedAt   time.Time      `json:"ended_at"`             // stored in UTC
+	Timezone  string         `json:"timezone"`             // zone the session was recorded in
 }
 
 var (
 	Logger = log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)
 )
 
+// handleDBError aborts a migration step, tryMigrateDb turns the panic into an error. Bound methods return errors instead
 func handleDBError(err error) {
 	if err != nil {
 		panic(err)
@@ -65,7 +78,7 @@ func handleDBError(err error) {
 }
 
 func (a *App) cleanupSoftDeletedRecords() {
-	query := "deleted_at IS NOT NULL AND deleted_at <= datetime('now', '-30 days')"
+	query := fmt.Sprintf("deleted_at IS NOT NULL AND deleted_at <= datetime('now', '-%d days')", trashRetentionDays)
 
 	// Delete soft deleted records for WorkHours
 	result := a.db.Unscoped().Where(query).Delete(&WorkHours{})
@@ -75,6 +88,22 @@ func (a *App) cleanupSoftDeletedRecords() {
 		log.Printf("Deleted %d WorkHours records", result.RowsAffected)
 	}
 
+	// Delete soft deleted records for Task
+	result = a.db.Unscoped().Where(query).Delete(&Task{})
+	if err := result.Error; err != nil {
+		log.Printf("Error deleting Task records: %v", err)
+	} else {
+		log.Printf("Deleted %d Task records", result.RowsAffected)
+	}
+
+	// Delete soft deleted records for Absence
+	result = a.db.Unscoped().Where(query).Delete(&Absence{})
+	if err := result.Error; err != nil {
+		log.Printf("Error deleting Absence records: %v", err)
+	} else {
+		log.Printf("Deleted %d Absence records", result.RowsAffected)
+	}
+
 	// Delete soft deleted records for Project
 	result = a.db.Unscoped().Where(query).Delete(&Project{})
 	if err := result.Error; err != nil {
@@ -90,18 +119,54 @@ func (a *App) cleanupSoftDeletedRecords() {
 	} else {
 		log.Printf("Deleted %d Organization records", result.RowsAffected)
 	}
+
+	// Operations on purged records can't be undone anymore
+	result = a.db.Where("created_at <= datetime('now', ?)", fmt.Sprintf("-%d days", trashRetentionDays)).Delete(&JournalEntry{})
+	if err := result.Error; err != nil {
+		log.Printf("Error deleting JournalEntry records: %v", err)
+	} else {
+		log.Printf("Deleted %d JournalEntry records", result.RowsAffected)
+	}
 }
 
-func NewDb(dbDir string) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(filepath.Join(dbDir, "worktracker.sqlite")), &gorm.Config{})
-	handleDBError(err)
+// openDb opens and migrates the database in a directory, the handle is closed when the migration fails
+func openDb(dbDir string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dbDir, dbFileName)), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := tryMigrateDb(db); err != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+// tryMigrateDb runs migrateDb, returning the errors it panics with
+func tryMigrateDb(db *gorm.DB) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("migrating the database failed: %v", r)
+		}
+	}()
+	migrateDb(db)
+	return nil
+}
 
+// migrateDb brings the schema and data of an opened database up to date
+func migrateDb(db *gorm.DB) {
 	fixOutdatedDb(db)
 
-	err = db.AutoMigrate(&WorkHours{}, &Project{}, &Organization{}, &WorkSession{})
+	err := db.AutoMigrate(&WorkHours{}, &Project{}, &Organization{}, &WorkSession{}, &Settings{}, &ReportTemplate{}, &ReportSchedule{}, &ReportRun{}, &ReportMailing{}, &MailDelivery{}, &JournalEntry{}, &AuditEntry{}, &Task{}, &Client{}, &ClientContact{}, &RoundingPolicy{}, &WorkSchedule{}, &Absence{}, &LeaveAllowance{}, &ComplianceRules{}, &PeriodLock{}, &SyncConfig{}, &SyncCursor{}, &SyncAlias{}, &TeamConfig{}, &TeamQueueItem{}, &BackupTarget{}, &BackupRun{})
 	handleDBError(err)
 
-	return db
+	migrateAuditLog(db)
+
+	migrateTimezones(db)
+
+	migrateSyncUIDs(db)
 }
 
 func (a *App) getOrganization(organizationID uint) (Organization, error) {
@@ -114,7 +179,7 @@ func (a *App) getOrganization(organizationID uint) (Organization, error) {
 
 	if err != nil {
 		Logger.Println(err)
-		return Organization{}, err
+		return Organization{}, dbError(err, "organization", organizationID)
 	}
 	return organization, nil
 }
@@ -127,11 +192,23 @@ func (a *App) getProject(projectID uint) (Project, error) {
 		First(&project).Error
 	if err != nil {
 		Logger.Println(err)
-		return Project{}, err
+		return Project{}, dbError(err, "project", projectID)
 	}
 	return project, nil
 }
 
+// getWorkSession returns a work session, refusing the one the running timer is still writing to
+func (a *App) getWorkSession(workSessionID uint) (WorkSession, error) {
+	var workSession WorkSession
+	if err := a.db.Where(&WorkSession{ID: workSessionID}).First(&workSession).Error; err != nil {
+		return WorkSession{}, dbError(err, "work session", workSessionID)
+	}
+	if a.isRunningSession(workSession.ID) {
+		return WorkSession{}, conflictError("the session is still running, stop the timer first")
+	}
+	return workSession, nil
+}
+
 type NewOrgRet struct {
 	Organization Organization `json:"organization"`
 	Project      Project      `json:"project"`
@@ -139,46 +216,73 @@ type NewOrgRet struct {
 
 func (a *App) NewOrganization(organizationName string, projectName string) (NewOrgRet, error) {
 	if organizationName == "" || projectName == "" {
-		return NewOrgRet{}, errors.New("organization name or project name is empty")
+		return NewOrgRet{}, validationError("organization name or project name is empty")
 	}
 
-	// Check if organization exists, if not create it
 	var organization Organization
-	if err := a.db.Where(&Organization{Name: organizationName}).First(&organization).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			organization = Organization{Name: organizationName}
-			if err := a.db.Create(&organization).Error; err != nil {
-				return NewOrgRet{}, err
-			}
-		} else {
-			return NewOrgRet{}, err
+	var project Project
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if organization, err = findOrCreateOrganization(tx, organizationName); err != nil {
+			return err
+		}
+		project, err = findOrCreateProject(tx, organization, projectName)
+		return err
+	})
+	if err != nil {
+		return NewOrgRet{}, toAppError(err)
+	}
+	return NewOrgRet{Organization: organization, Project: project}, nil
+}
+
+// findOrCreateOrganization returns the organization with the given name, creating it if it doesn't exist
+func findOrCreateOrganization(tx *gorm.DB, organizationName string) (Organization, error) {
+	var organization Organization
+	err := tx.Where(&Organization{Name: organizationName}).First(&organization).Error
+	if err == gorm.ErrRecordNotFound {
+		organization = Organization{Name: organizationName, Timezone: localTimezone()}
+		err = insertAudited(tx, AuditManual, &organization)
+	} else if err == nil && organization.ArchivedAt != nil {
+		// Adding to an archived organization brings it back
+		before := organization
+		organization.ArchivedAt = nil
+		if err = tx.Save(&organization).Error; err == nil {
+			err = recordAudit(tx, AuditManual, AuditUpdate, before, organization)
 		}
 	}
+	return organization, err
+}
 
-	// Check if project exists within the organization, if not create it
+// findOrCreateProject returns the organization's project with the given name, creating it if it doesn't exist.
+// The project gets an empty WorkHours entry for today either way
+func findOrCreateProject(tx *gorm.DB, organization Organization, projectName string) (Project, error) {
 	var project Project
-	if err := a.db.Where("name = ? AND organization_id = ?", projectName, organization.ID).First(&project).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			project = Project{Name: projectName, OrganizationID: organization.ID}
-			if err := a.db.Create(&project).Error; err != nil {
-				return NewOrgRet{}, err
-			}
-		} else {
-			return NewOrgRet{}, err
-		}
+	err := tx.Where(&Project{Name: projectName, OrganizationID: organization.ID}).First(&project).Error
+	if err == gorm.ErrRecordNotFound {
+		project = Project{Name: projectName, OrganizationID: organization.ID}
+		err = insertAudited(tx, AuditManual, &project)
+	}
+	if err != nil {
+		return Project{}, err
 	}
 
 	// Create a new WorkHours entry for the project
-	currentDate := time.Now().Format("2006-01-02")
+	currentDate := dateIn(time.Now(), loadLocation(organization.Timezone))
 	workHours := WorkHours{
 		Date:      currentDate,
 		ProjectID: project.ID,
 		Seconds:   0,
 	}
-	if err := a.db.Create(&workHours).Error; err != nil {
-		handleDBError(err)
+	var existing int64
+	if err := tx.Model(&WorkHours{}).Where(&workHours).Count(&existing).Error; err != nil {
+		return Project{}, err
 	}
-	return NewOrgRet{Organization: organization, Project: project}, nil
+	if existing == 0 {
+		if err := insertAudited(tx, AuditManual, &workHours); err != nil {
+			return Project{}, err
+		}
+	}
+	return project, nil
 }
 
 func (a *App) SetOrganization(organizationID uint) error {
@@ -186,6 +290,9 @@ func (a *App) SetOrganization(organizationID uint) error {
 	if err != nil {
 		return err
 	}
+	if organization.ArchivedAt != nil {
+		return conflictError(fmt.Sprintf("%s is archived, unarchive it first", organization.Name))
+	}
 	fmt.Println("Organization set to:", organization.Name)
 	a.organization = organization
 
@@ -194,7 +301,7 @@ func (a *App) SetOrganization(organizationID uint) error {
 
 func (a *App) RenameOrganization(organizationID uint, newName string) (Organization, error) {
 	if newName == "" {
-		return Organization{}, errors.New("organization name is empty")
+		return Organization{}, validationError("organization name is empty")
 	}
 
 	organization, err := a.getOrganization(organizationID)
@@ -203,66 +310,45 @@ func (a *App) RenameOrganization(organizationID uint, newName string) (Organizat
 	}
 
 	// Update the organization's name
+	before := organization
 	organization.Name = newName
-	if err := a.db.Save(&organization).Error; err != nil {
-		handleDBError(err)
+	if err := a.saveAudited(before, &organization); err != nil {
+		return Organization{}, toAppError(err)
 	}
 	return organization, nil
 }
 
-func (a *App) ToggleFavoriteOrganization(organizationID uint) {
+func (a *App) ToggleFavoriteOrganization(organizationID uint) error {
 	organization, err := a.getOrganization(organizationID)
 	if err != nil {
-		handleDBError(err)
+		return err
 	}
 
+	before := organization
 	organization.Favorite = !organization.Favorite
-	if err := a.db.Save(&organization).Error; err != nil {
-		handleDBError(err)
+	if err := a.saveAudited(before, &organization); err != nil {
+		return toAppError(err)
 	}
+	return nil
 }
 
 // Create a new project for the specified organization
 func (a *App) NewProject(organizationName string, projectName string) (Project, error) {
 	if projectName == "" || organizationName == "" {
-		return Project{}, errors.New("project name or organization name is empty")
-	}
-
-	// Check if the organization exists, if not create it
-	var organization Organization
-	if err := a.db.Where(&Organization{Name: organizationName}).First(&organization).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			organization = Organization{Name: organizationName}
-			if err := a.db.Create(&organization).Error; err != nil {
-				return Project{}, err
-			}
-		} else {
-			return Project{}, err
-		}
+		return Project{}, validationError("project name or organization name is empty")
 	}
 
-	// Check if the project exists within the organization, if not create it
 	var project Project
-	if err := a.db.Where(&Project{Name: projectName, OrganizationID: organization.ID}).First(&project).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			project = Project{Name: projectName, OrganizationID: organization.ID}
-			if err := a.db.Create(&project).Error; err != nil {
-				handleDBError(err)
-			}
-		} else {
-			handleDBError(err)
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		organization, err := findOrCreateOrganization(tx, organizationName)
+		if err != nil {
+			return err
 		}
-	}
-
-	// Create a new WorkHours entry for the project
-	currentDate := time.Now().Format("2006-01-02")
-	workHours := WorkHours{
-		Date:      currentDate,
-		ProjectID: project.ID,
-		Seconds:   0,
-	}
-	if err := a.db.Create(&workHours).Error; err != nil {
-		handleDBError(err)
+		project, err = findOrCreateProject(tx, organization, projectName)
+		return err
+	})
+	if err != nil {
+		return Project{}, toAppError(err)
 	}
 	return project, nil
 }
@@ -278,14 +364,22 @@ func (a *App) GetAllProjects() (projects []Project, err error) {
 
 func (a *App) SetProject(projectID uint) error {
 	if a.isRunning {
-		a.StopTimer()
+		if err := a.StopTimer(); err != nil {
+			return err
+		}
 	}
 
 	project, err := a.getProject(projectID)
 	if err != nil {
 		return err
 	}
+	if project.ArchivedAt != nil {
+		return conflictError(fmt.Sprintf("%s is archived, unarchive it first", project.Name))
+	}
 	fmt.Println("Project set to:", project.Name, "for Organization:", a.organization.Name)
+	if a.project.ID != project.ID {
+		a.task = Task{}
+	}
 	a.project = project
 
 	return nil
@@ -293,62 +387,69 @@ func (a *App) SetProject(projectID uint) error {
 
 func (a *App) RenameProject(projectID uint, newName string) (Project, error) {
 	if newName == "" || projectID == 0 {
-		return Project{}, errors.New("project name is empty or project ID is 0")
+		return Project{}, validationError("project name is empty or project ID is 0")
 	}
 
 	// Find the project within the organization
-	var project Project
-	if err := a.db.Where(&Project{ID: projectID}).First(&project).Error; err != nil {
-		handleDBError(err)
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return Project{}, err
 	}
 
 	// Update the project's name
+	before := project
 	project.Name = newName
-	if err := a.db.Save(&project).Error; err != nil {
-		handleDBError(err)
+	if err := a.saveAudited(before, &project); err != nil {
+		return Project{}, toAppError(err)
 	}
 	return project, nil
 }
 
-func (a *App) DeleteProject(projectID uint) {
+func (a *App) DeleteProject(projectID uint) error {
 	if projectID == 0 {
-		return
+		return validationError("project ID is 0")
 	}
 
-	var project Project
-	if err := a.db.Where(&Project{ID: projectID}).First(&project).Error; err != nil {
-		handleDBError(err)
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return err
 	}
 
-	// Delete the project's WorkHours entries
-	if err := a.db.Where(&WorkHours{ProjectID: project.ID}).Delete(&WorkHours{}).Error; err != nil {
-		handleDBError(err)
+	// Delete the project's WorkHours entries along with it
+	changes := journalChanges{Deleted: journalRows{Projects: []uint{project.ID}}}
+	err = a.db.Model(&WorkHours{}).
+		Where(&WorkHours{ProjectID: project.ID}).
+		Pluck("id", &changes.Deleted.WorkHours).Error
+	if err != nil {
+		return toAppError(err)
 	}
 
-	// Delete the project
-	if err := a.db.Delete(&project).Error; err != nil {
-		handleDBError(err)
+	if err := a.perform(fmt.Sprintf("Delete project %s", project.Name), AuditManual, changes); err != nil {
+		return toAppError(err)
 	}
+	return nil
 }
 
-func (a *App) ToggleFavoriteProject(projectID uint) {
+func (a *App) ToggleFavoriteProject(projectID uint) error {
 	if projectID == 0 {
-		return
+		return validationError("project ID is 0")
 	}
 
 	project, err := a.getProject(projectID)
 	if err != nil {
-		handleDBError(err)
+		return err
 	}
 
+	before := project
 	project.Favorite = !project.Favorite
-	if err := a.db.Save(&project).Error; err != nil {
-		handleDBError(err)
+	if err := a.saveAudited(before, &project); err != nil {
+		return toAppError(err)
 	}
+	return nil
 }
 
-// GetProjects returns the list of projects for the specified organization
-func (a *App) GetProjects(organizationID uint) (projects []Project, err error) {
+// GetProjects returns the list of projects for the specified organization, filtered by archived state
+func (a *App) GetProjects(organizationID uint, filter ArchiveFilter) (projects []Project, err error) {
 	// Find the organization
 	organization, err := a.getOrganization(organizationID)
 	if err != nil {
@@ -359,6 +460,7 @@ func (a *App) GetProjects(organizationID uint) (projects []Project, err error) {
 	err = a.db.
 		Where("projects.deleted_at IS NULL"). // Ignore deleted projects
 		Where(&Project{OrganizationID: organization.ID}).
+		Scopes(archiveScope(filter)).
 		Find(&projects).Error
 	if err != nil {
 		return nil, err
@@ -367,78 +469,136 @@ func (a *App) GetProjects(organizationID uint) (projects []Project, err error) {
 	return projects, nil
 }
 
-func (a *App) DeleteOrganization(organizationID uint) {
+func (a *App) DeleteOrganization(organizationID uint) error {
 	// Find the organization
 	organization, err := a.getOrganization(organizationID)
 	if err != nil {
-		handleDBError(err)
+		return err
 	}
 
-	// Delete the organization's projects' WorkHours entries
-	var projectIDs []int
+	// Delete the organization's projects and their WorkHours entries along with it
+	changes := journalChanges{Deleted: journalRows{Organizations: []uint{organization.ID}}}
 	err = a.db.Model(&Project{}).
 		Where("organization_id = ?", organization.ID).
-		Pluck("id", &projectIDs).Error
-	if err != nil {
-		handleDBError(err)
-	}
-
-	err = a.db.Where("project_id IN (?)", projectIDs).Delete(&WorkHours{}).Error
+		Pluck("id", &changes.Deleted.Projects).Error
 	if err != nil {
-		handleDBError(err)
+		return toAppError(err)
 	}
 
-	// Delete the organization's projects
-	if err := a.db.Where(&Project{OrganizationID: organization.ID}).Delete(&Project{}).Error; err != nil {
-		handleDBError(err)
+	if len(changes.Deleted.Projects) > 0 {
+		err = a.db.Model(&WorkHours{}).
+			Where("project_id IN (?)", changes.Deleted.Projects).
+			Pluck("id", &changes.Deleted.WorkHours).Error
+		if err != nil {
+			return toAppError(err)
+		}
 	}
 
-	// Delete the organization
-	if err := a.db.Delete(&organization).Error; err != nil {
-		handleDBError(err)
+	if err := a.perform(fmt.Sprintf("Delete organization %s", organization.Name), AuditManual, changes); err != nil {
+		return toAppError(err)
 	}
+	return nil
 }
 
-func (a *App) GetOrganizations() (organizations []Organization, err error) {
-	if err := a.db.Find(&organizations).Where("organizations.deleted_at IS NULL").Error; err != nil {
+// GetOrganizations returns the list of organizations, filtered by archived state
+func (a *App) GetOrganizations(filter ArchiveFilter) (organizations []Organization, err error) {
+	if err := a.db.Scopes(archiveScope(filter)).Find(&organizations).Error; err != nil {
 		return nil, err
 	}
 
 	return organizations, nil
 }
 
-func (a *App) saveTimer(projectID uint) int {
-	endTime := time.Now()
-	secsWorked := 0
-	if !a.lastSave.IsZero() {
-		// If lastSave is set, calculate the seconds worked since the last save
-		secsWorked = int(endTime.Sub(a.lastSave).Seconds())
-	} else {
-		// If lastSave is not set, calculate the seconds worked since the timer started
-		secsWorked = int(endTime.Sub(a.startTime).Seconds())
+// addWorkHours adds seconds to a project's WorkHours entry for the date, creating it if needed
+func addWorkHours(tx *gorm.DB, source AuditSource, projectID uint, date string, seconds int) error {
+	workHours := WorkHours{
+		Date:      date,
+		ProjectID: projectID,
+		Seconds:   0,
+	}
+	result := tx.FirstOrCreate(&workHours, WorkHours{Date: date, ProjectID: projectID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if err := recordAudit(tx, source, AuditCreate, nil, workHours); err != nil {
+			return err
+		}
+	}
+
+	before := workHours
+	if err := tx.Model(&workHours).Update("seconds", gorm.Expr("seconds + ?", seconds)).Error; err != nil {
+		return err
 	}
-	date := a.startTime.Format("2006-01-02")
+	workHours.Seconds = before.Seconds + seconds
+	return recordAudit(tx, source, AuditUpdate, before, workHours)
+}
+
+// removableSeconds caps the seconds taken off a project's day at the hours it holds, so they never go negative.
+// Sessions saved by older versions that ran past midnight have part of their hours on the day before
+func removableSeconds(db *gorm.DB, projectID uint, date string, seconds int) (int, error) {
+	var hours int
+	err := db.Model(&WorkHours{}).Where(&WorkHours{ProjectID: projectID, Date: date}).
+		Select("COALESCE(SUM(seconds), 0)").Scan(&hours).Error
+	return min(seconds, max(hours, 0)), err
+}
+
+// saveTimer adds the time worked since the last save to the project's hours and the running session
+func (a *App) saveTimer(projectID uint) (int, error) {
+	return a.saveSession(projectID, a.timerTaskID(), a.startTime, a.location, &a.session, false)
+}
+
+// saveSession saves a running timer, running points to the timer's session which is created on the first save
+func (a *App) saveSession(projectID uint, taskID *uint, startTime time.Time, location *time.Location, running *WorkSession, parallel bool) (int, error) {
+	endTime := time.Now()
+	totalSecs := int(endTime.Sub(startTime).Seconds())
+	// The session holds the seconds saved so far, only the difference is added to the hours
+	secsWorked := totalSecs - running.Seconds
+	date := dateIn(startTime, location)
 
 	// Find the project within the organization
 	project, err := a.getProject(projectID)
 	if err != nil {
-		handleDBError(err)
+		return totalSecs, err
 	}
 
-	workHours := WorkHours{
-		Date:      date,
-		ProjectID: project.ID,
-		Seconds:   0,
-	}
-	err = a.db.FirstOrCreate(&workHours, WorkHours{Date: date, ProjectID: project.ID}).Error
-	handleDBError(err)
+	session := *running
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnlocked(tx, projectDay{project.ID, date}); err != nil {
+			return err
+		}
+		if err := addWorkHours(tx, AuditTimer, project.ID, date, secsWorked); err != nil {
+			return err
+		}
 
-	err = a.db.Model(&workHours).Update("seconds", gorm.Expr("seconds + ?", secsWorked)).Error
-	handleDBError(err)
+		if session.ID == 0 {
+			session = WorkSession{
+				Date:      date,
+				ProjectID: project.ID,
+				TaskID:    taskID,
+				Parallel:  parallel,
+				Seconds:   totalSecs,
+				StartedAt: startTime.UTC(),
+				EndedAt:   endTime.UTC(),
+				Timezone:  localTimezone(),
+			}
+			return insertAudited(tx, AuditTimer, &session)
+		}
 
-	a.lastSave = time.Now()
+		before := session
+		session.Seconds = totalSecs
+		session.EndedAt = endTime.UTC()
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, AuditTimer, AuditUpdate, before, session)
+	})
+	if err != nil {
+		return totalSecs, toAppError(err)
+	}
+	*running = session
 
-	return int(endTime.Sub(a.startTime).Seconds())
+	return totalSecs, nil
 }
 
 // GetWorkTime returns the total seconds worked on the specified date
@@ -633,7 +793,7 @@ func (a *App) GetWorkTimeByProject(projectID uint, date string) (seconds int, er
 	}
 
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = dateIn(time.Now(), a.projectLocation(projectID))
 	}
 
 	project, err := a.getProject(projectID)
@@ -994,7 +1154,7 @@ func (a *App) GetYearlyWorkTimeByProject(year int, organizationID uint) (yearlyW
 // NewWorkSession creates a new work session for the specified project
 func (a *App) NewWorkSession(projectID uint, seconds int) (WorkSession, error) {
 	if projectID == 0 {
-		return WorkSession{}, errors.New("project ID is 0")
+		return WorkSession{}, validationError("project ID is 0")
 	}
 
 	project, err := a.getProject(projectID)
@@ -1002,13 +1162,28 @@ func (a *App) NewWorkSession(projectID uint, seconds int) (WorkSession, error) {
 		return WorkSession{}, err
 	}
 
+	endedAt := time.Now().UTC()
+	startedAt := endedAt.Add(-time.Duration(seconds) * time.Second)
 	workSession := WorkSession{
-		Date:      time.Now().Format("2006-01-02"),
+		Date:      dateIn(startedAt, a.reportingLocation(project.OrganizationID)),
 		ProjectID: project.ID,
 		Seconds:   seconds,
-	}
-	if err := a.db.Create(&workSession).Error; err != nil {
-		handleDBError(err)
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		Timezone:  localTimezone(),
+	}
+	// Sessions are the source of the daily hours
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnlocked(tx, projectDay{workSession.ProjectID, workSession.Date}); err != nil {
+			return err
+		}
+		if err := insertAudited(tx, AuditManual, &workSession); err != nil {
+			return err
+		}
+		return addWorkHours(tx, AuditManual, workSession.ProjectID, workSession.Date, workSession.Seconds)
+	})
+	if err != nil {
+		return WorkSession{}, toAppError(err)
 	}
 	return workSession, nil
 }
@@ -1016,53 +1191,41 @@ func (a *App) NewWorkSession(projectID uint, seconds int) (WorkSession, error) {
 // TransferWorkSession transfers the specified work session to the specified project
 func (a *App) TransferWorkSession(workSessionID, projectID uint) error {
 	if workSessionID == 0 || projectID == 0 {
-		return errors.New("work session ID or project ID is 0")
+		return validationError("work session ID or project ID is 0")
 	}
 
 	// Find the work session
-	var workSession WorkSession
-	if err := a.db.Where(&WorkSession{ID: workSessionID}).First(&workSession).Error; err != nil {
+	workSession, err := a.getWorkSession(workSessionID)
+	if err != nil {
 		return err
 	}
 
 	// Find the project we are transferring to
-	var project Project
-	if err := a.db.Where(&Project{ID: projectID}).First(&project).Error; err != nil {
-		return err
-	}
-
-	// Find or create the project's WorkHours entry
-	workHours := WorkHours{
-		Date:      workSession.Date,
-		ProjectID: project.ID,
-		Seconds:   0,
-	}
-
-	if err := a.db.FirstOrCreate(&workHours, WorkHours{Date: workHours.Date, ProjectID: project.ID}).Error; err != nil {
-		return err
-	}
-
-	// Subtract the seconds from the original project's WorkHours entry
-	var originalWorkHours WorkHours
-	if err := a.db.Where(&WorkHours{ProjectID: workSession.ProjectID, Date: workSession.Date}).First(&originalWorkHours).Error; err != nil {
-		return err
-	}
-
-	if err := a.db.Model(&originalWorkHours).Update("seconds", gorm.Expr("seconds - ?", workSession.Seconds)).Error; err != nil {
-		return err
-	}
-
-	// Add the seconds to the project's WorkHours entry
-	if err := a.db.Model(&workHours).Update("seconds", gorm.Expr("seconds + ?", workSession.Seconds)).Error; err != nil {
+	project, err := a.getProject(projectID)
+	if err != nil {
 		return err
 	}
 
-	// Update the work session's project ID
-	workSession.ProjectID = project.ID
-	if err := a.db.Save(&workSession).Error; err != nil {
-		return err
+	// Move the seconds from the original project's WorkHours entry to the new project's
+	seconds, err := removableSeconds(a.db, workSession.ProjectID, workSession.Date, workSession.Seconds)
+	if err != nil {
+		return toAppError(err)
+	}
+	changes := journalChanges{
+		Adjustments: []hoursAdjustment{
+			{ProjectID: workSession.ProjectID, Date: workSession.Date, Seconds: -seconds},
+			{ProjectID: project.ID, Date: workSession.Date, Seconds: seconds},
+		},
+		Transfer: &sessionTransfer{
+			WorkSessionID: workSession.ID,
+			FromProjectID: workSession.ProjectID,
+			ToProjectID:   project.ID,
+			FromTaskID:    workSession.TaskID,
+		},
+	}
+	if err := a.perform(fmt.Sprintf("Transfer session from %s to %s", workSession.Date, project.Name), AuditTransfer, changes); err != nil {
+		return toAppError(err)
 	}
-
 	return nil
 }
 
@@ -1097,28 +1260,29 @@ func (a *App) GetWorkSessionsByDate(date string) (workSessions []WorkSession, er
 }
 
 // DeleteWorkSession deletes the specified work session
-func (a *App) DeleteWorkSession(workSessionID uint) {
+func (a *App) DeleteWorkSession(workSessionID uint) error {
 	if workSessionID == 0 {
-		return
+		return validationError("work session ID is 0")
 	}
 
-	var workSession WorkSession
-	if err := a.db.Where(&WorkSession{ID: workSessionID}).First(&workSession).Error; err != nil {
-		handleDBError(err)
+	workSession, err := a.getWorkSession(workSessionID)
+	if err != nil {
+		return err
 	}
 
-	if err := a.db.Delete(&workSession).Error; err != nil {
-		handleDBError(err)
+	// Subtract the seconds from the project's WorkHours entry
+	seconds, err := removableSeconds(a.db, workSession.ProjectID, workSession.Date, workSession.Seconds)
+	if err != nil {
+		return toAppError(err)
 	}
-
-	// Update the project's WorkHours entry
-	var workHours WorkHours
-	if err := a.db.Where(&WorkHours{ProjectID: workSession.ProjectID, Date: workSession.Date}).First(&workHours).Error; err != nil {
-		handleDBError(err)
+	changes := journalChanges{
+		Deleted: journalRows{WorkSessions: []uint{workSession.ID}},
+		Adjustments: []hoursAdjustment{
+			{ProjectID: workSession.ProjectID, Date: workSession.Date, Seconds: -seconds},
+		},
 	}
-
-	// Subtract the seconds from the project's WorkHours entry
-	if err := a.db.Model(&workHours).Update("seconds", gorm.Expr("seconds - ?", workSession.Seconds)).Error; err != nil {
-		handleDBError(err)
+	if err := a.perform(fmt.Sprintf("Delete session from %s", workSession.Date), AuditManual, changes); err != nil {
+		return toAppError(err)
 	}
+	return nil
 }
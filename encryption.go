@@ -0,0 +1,640 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/argon2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Encryption at rest
+//
+// An encrypted database is kept as worktracker.sqlite.enc: a header saying where the key comes from, followed by
+// the SQLite file encrypted with AES-256-GCM. The key is derived from a passphrase with Argon2id, or is a random
+// key kept in the OS keyring (see keyring_*.go). While the app runs the database lives in memory and is written
+// back encrypted shortly after every change and on shutdown, so the plaintext never reaches the disk.
+//
+// When both files exist the encrypted one wins: switching writes the new file before removing the old one.
+
+const (
+	dbFileName          = "worktracker.sqlite"
+	encryptedDbFileName = dbFileName + ".enc"
+	encryptedDbMagic    = "WTENC1"
+	encryptionSaltSize  = 16
+	minPassphraseLength = 8
+	encryptionFlushRate = 2 * time.Second
+)
+
+type EncryptionMode string
+
+const (
+	EncryptionNone       EncryptionMode = "none"
+	EncryptionPassphrase EncryptionMode = "passphrase"
+	EncryptionKeyring    EncryptionMode = "keyring"
+)
+
+// encryptionModeBytes is how the modes are stored in the file header
+var encryptionModeBytes = map[EncryptionMode]byte{EncryptionPassphrase: 'p', EncryptionKeyring: 'k'}
+
+// EncryptionStatus tells the frontend whether the database is encrypted and still has to be unlocked
+type EncryptionStatus struct {
+	Mode             EncryptionMode `json:"mode"`
+	Locked           bool           `json:"locked"` // the passphrase is needed before anything else works
+	KeyringAvailable bool           `json:"keyring_available"`
+	Error            string         `json:"error"` // why the database couldn't be opened, e.g. a missing keyring entry
+}
+
+// encryptedStore writes the in-memory database back to its encrypted file
+type encryptedStore struct {
+	path  string
+	mode  EncryptionMode
+	salt  []byte
+	key   []byte
+	conn  *sql.Conn // keeps the in-memory database alive, it is dropped with its last connection
+	mu    sync.Mutex
+	dirty atomic.Bool // written on the next flush even without changes, e.g. after the key changed
+	// data_version of the database when it was last written, SQLite changes it on every commit of the
+	// app's connections, so a change is only seen once it is committed
+	version int64
+	stop    chan struct{}
+}
+
+// openDatabase opens the plaintext or encrypted database of the save directory. An encrypted database
+// needs the passphrase, or the keyring key; without it the app stays locked until Unlock
+func (a *App) openDatabase(passphrase string) error {
+	path := filepath.Join(a.dbDir, encryptedDbFileName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		db, err := openDb(a.dbDir)
+		if err != nil {
+			return err
+		}
+		a.db = db
+		a.settings, _ = a.loadSettings()
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	mode, salt, err := parseEncryptedHeader(data)
+	if err != nil {
+		return err
+	}
+	var key []byte
+	switch mode {
+	case EncryptionPassphrase:
+		if passphrase == "" {
+			return lockedError("the database is encrypted, enter the passphrase to unlock it")
+		}
+		key = deriveKey(passphrase, salt)
+	case EncryptionKeyring:
+		if key, err = keyringGet(a.dbDir); err != nil {
+			return fmt.Errorf("the database key couldn't be read from the keyring: %w", err)
+		}
+	}
+	plaintext, err := decryptDatabaseFile(data, key)
+	if err != nil {
+		return err
+	}
+	return a.openEncrypted(plaintext, mode, salt, key)
+}
+
+// openEncrypted opens a database in memory that is saved encrypted with the key
+func (a *App) openEncrypted(plaintext []byte, mode EncryptionMode, salt, key []byte) error {
+	db, store, err := openMemoryDb(plaintext)
+	if err != nil {
+		return err
+	}
+	store.path = filepath.Join(a.dbDir, encryptedDbFileName)
+	store.mode, store.salt, store.key = mode, salt, key
+	if err := tryMigrateDb(db); err != nil {
+		store.close(db)
+		return err
+	}
+	a.db, a.store = db, store
+	a.settings, _ = a.loadSettings()
+	store.dirty.Store(true)
+	go store.flushRoutine()
+	return nil
+}
+
+// requireEncryption refuses to store credentials in a plaintext database, reason tells what is stored
+func (a *App) requireEncryption(reason string) error {
+	if a.db == nil {
+		return lockedError("unlock the database first")
+	}
+	if a.store == nil {
+		return conflictError("encrypt the database first, " + reason)
+	}
+	return nil
+}
+
+// storedSecrets names the credentials stored in the database, which keep it from being decrypted
+func (a *App) storedSecrets() ([]string, error) {
+	var secrets []string
+	var targets int64
+	if err := a.db.Model(&BackupTarget{}).Count(&targets).Error; err != nil {
+		return nil, err
+	}
+	if targets > 0 {
+		secrets = append(secrets, "the backup targets")
+	}
+	settings, err := a.loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.SMTPPassword != "" {
+		secrets = append(secrets, "the SMTP password")
+	}
+	var config TeamConfig
+	if err := a.db.Limit(1).Find(&config, teamConfigID).Error; err != nil {
+		return nil, err
+	}
+	if config.Token != "" {
+		secrets = append(secrets, "the team server's API token")
+	}
+	return secrets, nil
+}
+
+// GetEncryptionStatus returns how the database is stored and whether it still has to be unlocked
+func (a *App) GetEncryptionStatus() EncryptionStatus {
+	status := EncryptionStatus{Mode: EncryptionNone, Locked: a.db == nil, KeyringAvailable: keyringAvailable()}
+	if a.store != nil {
+		status.Mode = a.store.mode
+	} else if data, err := readEncryptedHeaderFile(filepath.Join(a.dbDir, encryptedDbFileName)); err == nil {
+		status.Mode, _, _ = parseEncryptedHeader(data)
+	}
+	if a.openErr != nil {
+		status.Error = a.openErr.Error()
+	}
+	return status
+}
+
+// Unlock opens the encrypted database with its passphrase and starts the app's background work
+func (a *App) Unlock(passphrase string) error {
+	a.dbMu.Lock()
+	if a.db != nil {
+		a.dbMu.Unlock()
+		return nil
+	}
+	err := a.openDatabase(passphrase)
+	a.dbMu.Unlock()
+	if err != nil {
+		if errors.Is(err, errWrongKey) {
+			return validationError("wrong passphrase")
+		}
+		return toAppError(err)
+	}
+	a.openErr = nil
+	if a.ctx != nil {
+		a.startRoutines()
+	}
+	return nil
+}
+
+// SetEncryption encrypts the database with a passphrase or a keyring key, changes the passphrase,
+// or decrypts it again with EncryptionNone. The current passphrase is required when there is one
+func (a *App) SetEncryption(mode EncryptionMode, passphrase string, currentPassphrase string) (EncryptionStatus, error) {
+	a.dbMu.Lock()
+	defer a.dbMu.Unlock()
+	if a.db == nil {
+		return EncryptionStatus{}, lockedError("unlock the database first")
+	}
+	if a.store != nil && a.store.mode == EncryptionPassphrase &&
+		subtle.ConstantTimeCompare(deriveKey(currentPassphrase, a.store.salt), a.store.key) != 1 {
+		return EncryptionStatus{}, validationError("the current passphrase is wrong")
+	}
+
+	if mode == EncryptionNone {
+		secrets, err := a.storedSecrets()
+		if err != nil {
+			return EncryptionStatus{}, toAppError(err)
+		}
+		if len(secrets) > 0 {
+			names := secrets[len(secrets)-1]
+			if len(secrets) > 1 {
+				names = strings.Join(secrets[:len(secrets)-1], ", ") + " and " + names
+			}
+			return EncryptionStatus{}, conflictError(fmt.Sprintf("remove %s first, they would be stored unencrypted", names))
+		}
+	}
+
+	var err error
+	switch mode {
+	case EncryptionNone:
+		err = a.decryptDatabase()
+	case EncryptionPassphrase:
+		if len(passphrase) < minPassphraseLength {
+			return EncryptionStatus{}, validationError(fmt.Sprintf("the passphrase needs at least %d characters", minPassphraseLength))
+		}
+		salt := make([]byte, encryptionSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return EncryptionStatus{}, toAppError(err)
+		}
+		err = a.encryptDatabase(mode, salt, deriveKey(passphrase, salt))
+	case EncryptionKeyring:
+		if !keyringAvailable() {
+			return EncryptionStatus{}, validationError("no keyring is available on this system")
+		}
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return EncryptionStatus{}, toAppError(err)
+		}
+		// The file is written under the new key before the keyring entry is replaced, so the keyring keeps
+		// the key of the file on disk until then, and the old key is put back if the keyring refuses the new one
+		wasEncrypted := a.store != nil
+		var previousMode EncryptionMode
+		var previousSalt, previousKey []byte
+		if wasEncrypted {
+			a.store.mu.Lock()
+			previousMode, previousSalt, previousKey = a.store.mode, a.store.salt, a.store.key
+			a.store.mu.Unlock()
+		}
+		if err = a.encryptDatabase(mode, make([]byte, encryptionSaltSize), key); err != nil {
+			break
+		}
+		if err = keyringSet(a.dbDir, key); err != nil {
+			err = fmt.Errorf("the key couldn't be saved in the keyring: %w", err)
+			var rollbackErr error
+			if wasEncrypted {
+				rollbackErr = a.encryptDatabase(previousMode, previousSalt, previousKey)
+			} else {
+				rollbackErr = a.decryptDatabase()
+			}
+			if rollbackErr != nil {
+				Logger.Println("Failed to write the database back with its previous key:", rollbackErr)
+			}
+		}
+	default:
+		return EncryptionStatus{}, validationError(fmt.Sprintf("invalid encryption mode %q", mode))
+	}
+	if err != nil {
+		return EncryptionStatus{}, toAppError(err)
+	}
+	if mode != EncryptionKeyring {
+		if err := keyringDelete(a.dbDir); err != nil {
+			Logger.Println("Failed to remove the database key from the keyring:", err)
+		}
+	}
+	return a.GetEncryptionStatus(), nil
+}
+
+// encryptDatabase writes the database encrypted with a new key. A plaintext database is moved into memory
+// and its file is removed, an encrypted one just takes the new key
+func (a *App) encryptDatabase(mode EncryptionMode, salt, key []byte) error {
+	if a.store != nil {
+		a.store.mu.Lock()
+		previousMode, previousSalt, previousKey := a.store.mode, a.store.salt, a.store.key
+		a.store.mode, a.store.salt, a.store.key = mode, salt, key
+		a.store.mu.Unlock()
+		a.store.dirty.Store(true)
+		if err := a.store.flush(); err != nil {
+			// The file still has the previous key
+			a.store.mu.Lock()
+			a.store.mode, a.store.salt, a.store.key = previousMode, previousSalt, previousKey
+			a.store.mu.Unlock()
+			return err
+		}
+		return nil
+	}
+
+	plaintext, err := serializeDb(a.db)
+	if err != nil {
+		return err
+	}
+	db, store, err := openMemoryDb(plaintext)
+	if err != nil {
+		return err
+	}
+	store.path = filepath.Join(a.dbDir, encryptedDbFileName)
+	store.mode, store.salt, store.key = mode, salt, key
+	store.dirty.Store(true)
+	if err := store.flush(); err != nil {
+		store.close(db)
+		return err
+	}
+
+	old := a.db
+	a.db, a.store = db, store
+	go store.flushRoutine()
+	if sqlDB, err := old.DB(); err == nil {
+		sqlDB.Close()
+	}
+	// The file is deleted, not wiped, the disk may still hold its old blocks
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if err := os.Remove(filepath.Join(a.dbDir, dbFileName+suffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			Logger.Println("Failed to remove the plaintext database:", err)
+		}
+	}
+	return nil
+}
+
+// decryptDatabase writes the in-memory database back as a plaintext file and opens it
+func (a *App) decryptDatabase() error {
+	if a.store == nil {
+		return nil
+	}
+	store := a.store
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	plaintext, _, err := store.snapshot()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(a.dbDir, dbFileName)
+	if err := writeFileAtomic(path, plaintext); err != nil {
+		return err
+	}
+	db, err := openDb(a.dbDir)
+	if err != nil {
+		// The encrypted file is still the database
+		os.Remove(path)
+		return err
+	}
+	if err := os.Remove(store.path); err != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		os.Remove(path)
+		return err
+	}
+
+	close(store.stop)
+	old := a.db
+	a.db, a.store = db, nil
+	store.close(old)
+	return nil
+}
+
+// closeDatabase writes pending changes of an encrypted database and closes the database,
+// called on shutdown and when switching workspaces
+func (a *App) closeDatabase() {
+	if a.db == nil {
+		return
+	}
+	if a.store != nil {
+		close(a.store.stop)
+		if err := a.store.flush(); err != nil {
+			Logger.Println("Failed to save the encrypted database:", err)
+		}
+		a.store.close(a.db)
+	} else if sqlDB, err := a.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	a.db, a.store = nil, nil
+}
+
+var errWrongKey = errors.New("the database can't be decrypted with this key")
+
+func deriveKey(passphrase string, salt []byte) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, 3, 64*1024, 4, 32)
+}
+
+func encryptedHeader(mode EncryptionMode, salt []byte) []byte {
+	return append(append([]byte(encryptedDbMagic), encryptionModeBytes[mode]), salt...)
+}
+
+func readEncryptedHeaderFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	header := make([]byte, len(encryptedDbMagic)+1+encryptionSaltSize)
+	_, err = file.Read(header)
+	return header, err
+}
+
+func parseEncryptedHeader(data []byte) (EncryptionMode, []byte, error) {
+	headerSize := len(encryptedDbMagic) + 1 + encryptionSaltSize
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(encryptedDbMagic)) {
+		return "", nil, errors.New("the encrypted database is damaged or of an unknown version")
+	}
+	for mode, b := range encryptionModeBytes {
+		if data[len(encryptedDbMagic)] == b {
+			return mode, data[len(encryptedDbMagic)+1 : headerSize], nil
+		}
+	}
+	return "", nil, errors.New("the encrypted database uses an unknown key type")
+}
+
+// encryptDatabaseFile returns the header, a random nonce and the sealed database, the header is authenticated too
+func encryptDatabaseFile(mode EncryptionMode, salt, key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := encryptedHeader(mode, salt)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, header...), nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+func decryptDatabaseFile(data, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptedDbMagic) + 1 + encryptionSaltSize
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, errors.New("the encrypted database is damaged")
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], data[:headerSize])
+	if err != nil {
+		return nil, errWrongKey
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// openMemoryDb loads a serialized database into a shared in-memory database. The store's own connection
+// is kept out of the gorm.DB's pool, it sees the changes committed through it in PRAGMA data_version
+func openMemoryDb(plaintext []byte) (*gorm.DB, *encryptedStore, error) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:/worktracker-%s?vfs=memdb", newUID())), &gorm.Config{})
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	store := &encryptedStore{conn: conn, stop: make(chan struct{})}
+
+	// Deserializing would detach the connection from the shared database, so the pages are copied with a backup
+	if len(plaintext) > 0 {
+		loader, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer loader.Close()
+		loaderConn, err := loader.Conn(context.Background())
+		if err != nil {
+			return nil, nil, err
+		}
+		defer loaderConn.Close()
+		err = loaderConn.Raw(func(source any) error {
+			if err := source.(*sqlite3.SQLiteConn).Deserialize(plaintext, "main"); err != nil {
+				return err
+			}
+			return conn.Raw(func(target any) error {
+				backup, err := target.(*sqlite3.SQLiteConn).Backup("main", source.(*sqlite3.SQLiteConn), "main")
+				if err != nil {
+					return err
+				}
+				if _, err := backup.Step(-1); err != nil {
+					backup.Finish()
+					return err
+				}
+				return backup.Finish()
+			})
+		})
+		if err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return db, store, nil
+}
+
+// serializeDb returns the database as the bytes of a SQLite file
+func serializeDb(db *gorm.DB) ([]byte, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var data []byte
+	err = conn.Raw(func(driverConn any) error {
+		data, err = driverConn.(*sqlite3.SQLiteConn).Serialize("main")
+		return err
+	})
+	return data, err
+}
+
+// snapshot serializes the in-memory database with its data_version. The memory is copied as is, so a read
+// transaction keeps a write in progress from ending up half in the copy
+func (s *encryptedStore) snapshot() (data []byte, version int64, err error) {
+	ctx := context.Background()
+	if _, err := s.conn.ExecContext(ctx, "BEGIN"); err != nil {
+		return nil, 0, err
+	}
+	defer s.conn.ExecContext(ctx, "COMMIT")
+	if _, err := s.conn.ExecContext(ctx, "SELECT COUNT(*) FROM sqlite_master"); err != nil {
+		return nil, 0, err
+	}
+	if err := s.conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version); err != nil {
+		return nil, 0, err
+	}
+	err = s.conn.Raw(func(driverConn any) error {
+		var err error
+		data, err = driverConn.(*sqlite3.SQLiteConn).Serialize("main")
+		return err
+	})
+	return data, version, err
+}
+
+// flush writes the database to its encrypted file when changes were committed since the last flush
+func (s *encryptedStore) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var version int64
+	if err := s.conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version); err != nil {
+		return err
+	}
+	if !s.dirty.Load() && version == s.version {
+		return nil
+	}
+	plaintext, version, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+	data, err := encryptDatabaseFile(s.mode, s.salt, s.key, plaintext)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	s.version = version
+	s.dirty.Store(false)
+	return nil
+}
+
+func (s *encryptedStore) flushRoutine() {
+	ticker := time.NewTicker(encryptionFlushRate)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				Logger.Println("Failed to save the encrypted database:", err)
+			}
+		}
+	}
+}
+
+func (s *encryptedStore) close(db *gorm.DB) {
+	s.conn.Close()
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// writeFileAtomic replaces a file with new contents, a crash leaves either the old or the new file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDatabaseFile(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, encryptionSaltSize)
+	key := deriveKey("correct horse", salt)
+	plaintext := []byte("SQLite format 3\x00 and some pages")
+
+	data, err := encryptDatabaseFile(EncryptionPassphrase, salt, key, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, plaintext) {
+		t.Fatal("the plaintext is in the file")
+	}
+	mode, headerSalt, err := parseEncryptedHeader(data)
+	if err != nil || mode != EncryptionPassphrase || !bytes.Equal(headerSalt, salt) {
+		t.Fatalf("header: %s, %x, %v", mode, headerSalt, err)
+	}
+	got, err := decryptDatabaseFile(data, key)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("round trip: %q, %v", got, err)
+	}
+
+	// Two files of the same database differ, the nonce is random
+	again, err := encryptDatabaseFile(EncryptionPassphrase, salt, key, plaintext)
+	if err != nil || bytes.Equal(again, data) {
+		t.Errorf("the same nonce was used twice")
+	}
+
+	headerSize := len(encryptedDbMagic) + 1 + encryptionSaltSize
+	tamper := func(i int) []byte {
+		changed := bytes.Clone(data)
+		changed[i] ^= 0x01
+		return changed
+	}
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{"wrong passphrase", data, deriveKey("wrong horse", salt)},
+		{"mode byte changed", tamper(len(encryptedDbMagic)), key},
+		{"salt changed", tamper(len(encryptedDbMagic) + 1), key},
+		{"nonce changed", tamper(headerSize), key},
+		{"ciphertext changed", tamper(len(data) - 20), key},
+		{"truncated", data[:len(data)-1], key},
+	}
+	for _, test := range tests {
+		if _, err := decryptDatabaseFile(test.data, test.key); !errors.Is(err, errWrongKey) {
+			t.Errorf("%s: err = %v, want errWrongKey", test.name, err)
+		}
+	}
+	if _, err := decryptDatabaseFile(data[:headerSize], key); err == nil || errors.Is(err, errWrongKey) {
+		t.Errorf("a file without ciphertext: err = %v, want damaged", err)
+	}
+
+	if _, _, err := parseEncryptedHeader([]byte("WTENC9p" + string(salt))); err == nil {
+		t.Error("an unknown version was accepted")
+	}
+	if _, _, err := parseEncryptedHeader(append([]byte(encryptedDbMagic+"x"), salt...)); err == nil {
+		t.Error("an unknown key type was accepted")
+	}
+}
+
+// dbFiles reports which database files exist in a directory
+func dbFiles(t *testing.T, dir string) (plain, encrypted bool) {
+	t.Helper()
+	_, err := os.Stat(filepath.Join(dir, dbFileName))
+	plain = err == nil
+	_, err = os.Stat(filepath.Join(dir, encryptedDbFileName))
+	encrypted = err == nil
+	return plain, encrypted
+}
+
+func TestSetEncryptionPassphrase(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetEncryption(EncryptionPassphrase, "short", ""); err == nil {
+		t.Error("a short passphrase was accepted")
+	}
+
+	// Plaintext to encrypted: the plaintext file is removed and nothing readable is left
+	status, err := a.SetEncryption(EncryptionPassphrase, "correct horse", "")
+	if err != nil || status.Mode != EncryptionPassphrase {
+		t.Fatalf("encrypting: %+v, %v", status, err)
+	}
+	if plain, encrypted := dbFiles(t, a.dbDir); plain || !encrypted {
+		t.Fatalf("files after encrypting: plain %v, encrypted %v", plain, encrypted)
+	}
+	data, err := os.ReadFile(filepath.Join(a.dbDir, encryptedDbFileName))
+	if err != nil || bytes.Contains(data, []byte("Acme")) {
+		t.Fatalf("the encrypted file holds the data in plain (%v)", err)
+	}
+
+	// Reopening needs the passphrase
+	a.closeDatabase()
+	var appErr *AppError
+	if err := a.openDatabase(""); !errors.As(err, &appErr) || appErr.Code != ErrLocked {
+		t.Errorf("without passphrase: %v, want locked", err)
+	}
+	if err := a.Unlock("wrong horse"); !errors.As(err, &appErr) || appErr.Code != ErrValidation || a.db != nil {
+		t.Errorf("wrong passphrase: %v", err)
+	}
+	if err := a.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	var organization Organization
+	if err := a.db.Where("name = ?", "Acme").First(&organization).Error; err != nil {
+		t.Fatalf("the data didn't survive: %v", err)
+	}
+
+	// Changes are written back encrypted on close
+	if _, err := a.NewOrganization("Globex", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	a.closeDatabase()
+	if err := a.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.db.Where("name = ?", "Globex").First(&Organization{}).Error; err != nil {
+		t.Fatalf("the change made while encrypted was lost: %v", err)
+	}
+
+	// Changing the passphrase needs the current one
+	if _, err := a.SetEncryption(EncryptionPassphrase, "battery staple", "wrong horse"); err == nil {
+		t.Fatal("the passphrase was changed without the current one")
+	}
+	if _, err := a.SetEncryption(EncryptionPassphrase, "battery staple", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	a.closeDatabase()
+	if err := a.Unlock("correct horse"); err == nil {
+		t.Fatal("the old passphrase still opens the database")
+	}
+	if err := a.Unlock("battery staple"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Encrypted back to plaintext
+	if _, err := a.SetEncryption(EncryptionNone, "", "battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	if plain, encrypted := dbFiles(t, a.dbDir); !plain || encrypted {
+		t.Fatalf("files after decrypting: plain %v, encrypted %v", plain, encrypted)
+	}
+	a.closeDatabase()
+	if err := a.openDatabase(""); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if a.db.Model(&Organization{}).Count(&count); count != 2 {
+		t.Errorf("%d organizations after decrypting, want 2", count)
+	}
+}
+
+func TestFlushSeesCommittedChanges(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.SetEncryption(EncryptionPassphrase, "correct horse", ""); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(a.dbDir, encryptedDbFileName)
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.store.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, written) {
+		t.Error("the file was written again without changes")
+	}
+
+	// Commits are seen by SQLite's data version, not by gorm's callbacks that run before the commit,
+	// so a change made around gorm is written too
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec("INSERT INTO organizations (name, created_at, updated_at) VALUES ('Acme', 0, 0)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.store.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); bytes.Equal(data, written) {
+		t.Fatal("the committed change wasn't written")
+	}
+
+	a.closeDatabase()
+	if err := a.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.db.Where("name = ?", "Acme").First(&Organization{}).Error; err != nil {
+		t.Errorf("the committed change was lost: %v", err)
+	}
+}
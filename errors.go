@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// ErrorCode tells the frontend what kind of failure an AppError is
+type ErrorCode string
+
+const (
+	ErrNotFound   ErrorCode = "not_found"
+	ErrConflict   ErrorCode = "conflict"
+	ErrLocked     ErrorCode = "locked" // the change falls in a locked period, see lock.go
+	ErrValidation ErrorCode = "validation"
+	ErrStorage    ErrorCode = "storage"
+	ErrInternal   ErrorCode = "internal"
+)
+
+// AppError is the error returned by bound methods, the frontend receives it as {code, message}
+type AppError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Err     error     `json:"-"`
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func notFoundError(entity string, id uint) *AppError {
+	return &AppError{Code: ErrNotFound, Message: fmt.Sprintf("%s %d not found", entity, id), Err: gorm.ErrRecordNotFound}
+}
+
+func validationError(message string) *AppError {
+	return &AppError{Code: ErrValidation, Message: message}
+}
+
+func conflictError(message string) *AppError {
+	return &AppError{Code: ErrConflict, Message: message}
+}
+
+func lockedError(message string) *AppError {
+	return &AppError{Code: ErrLocked, Message: message}
+}
+
+// invalid marks an error from one of the validate functions as a validation error
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &AppError{Code: ErrValidation, Message: err.Error(), Err: err}
+}
+
+// toAppError classifies an error, errors that already are AppErrors are returned as is
+func toAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &AppError{Code: ErrNotFound, Message: "record not found", Err: err}
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code {
+		case sqlite3.ErrConstraint:
+			return &AppError{Code: ErrConflict, Message: err.Error(), Err: err}
+		case sqlite3.ErrBusy, sqlite3.ErrLocked:
+			return &AppError{Code: ErrStorage, Message: "the database is busy, try again", Err: err}
+		default:
+			return &AppError{Code: ErrStorage, Message: err.Error(), Err: err}
+		}
+	}
+	return &AppError{Code: ErrInternal, Message: err.Error(), Err: err}
+}
+
+// dbError wraps a database error, turning a missing row into a not found error for the entity
+func dbError(err error, entity string, id uint) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFoundError(entity, id)
+	}
+	return toAppError(err)
+}
+
+// formatError is the ErrorFormatter passed to Wails so the frontend receives structured errors
+func formatError(err error) any {
+	return toAppError(err)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultMonthlyExportTemplate = "{type}/{org}/{year}/{month}/work_hours_{period}"
+	defaultYearlyExportTemplate  = "{type}/{org}/{year}/work_hours_{period}"
+)
+
+type ExportConflict string
+
+const (
+	ExportOverwrite ExportConflict = "overwrite"
+	ExportVersion   ExportConflict = "version"
+)
+
+var exportPlaceholders = []string{"{type}", "{org}", "{project}", "{year}", "{month}", "{period}", "{timestamp}"}
+
+// Characters that are invalid in file names on at least one supported platform
+var unsafePathChars = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+
+// Names Windows reserves for devices, regardless of extension
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// sanitizePathSegment makes a single file or directory name safe to use on any platform
+func sanitizePathSegment(name string) string {
+	name = unsafePathChars.ReplaceAllString(name, "_")
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "_"
+	}
+	base := strings.ToUpper(strings.SplitN(name, ".", 2)[0])
+	if reservedFileNames[base] {
+		name = "_" + name
+	}
+	return name
+}
+
+// validateExportTemplate checks a filename template, yearly templates have no month to fill in
+func validateExportTemplate(template string, yearly bool) error {
+	if strings.TrimSpace(template) == "" {
+		return errors.New("export filename template is empty")
+	}
+	if yearly && strings.Contains(template, "{month}") {
+		return errors.New("yearly export filename template can't use {month}")
+	}
+	if filepath.IsAbs(template) || strings.HasPrefix(template, "/") || strings.HasPrefix(template, "\\") {
+		return errors.New("export filename template must be relative to the export directory")
+	}
+	for _, segment := range strings.FieldsFunc(template, isPathSeparator) {
+		if segment == ".." {
+			return errors.New("export filename template can't leave the export directory")
+		}
+	}
+	return nil
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// expandExportTemplate fills in the template placeholders and returns the path relative to the export root.
+// Every value is sanitized so organization names can't create extra directories
+func expandExportTemplate(template string, values map[string]string) string {
+	var pairs []string
+	for _, placeholder := range exportPlaceholders {
+		pairs = append(pairs, placeholder, sanitizePathSegment(values[placeholder]))
+	}
+	expanded := strings.NewReplacer(pairs...).Replace(template)
+
+	var segments []string
+	for _, segment := range strings.FieldsFunc(expanded, isPathSeparator) {
+		segments = append(segments, sanitizePathSegment(segment))
+	}
+	return filepath.Join(segments...)
+}
+
+// versionedPath returns the first name of the form "name_vN.ext" that doesn't exist yet
+func versionedPath(filePath string) string {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return filePath
+	}
+	ext := filepath.Ext(filePath)
+	base := strings.TrimSuffix(filePath, ext)
+	for version := 2; ; version++ {
+		candidate := fmt.Sprintf("%s_v%d%s", base, version, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// exportRoot returns the directory exports are written to
+func (a *App) exportRoot() (string, error) {
+	if a.settings.ExportDir != "" {
+		return a.settings.ExportDir, nil
+	}
+	return a.dbDir, nil
+}
+
+// exportProjectName names the project of a scope that has a single one, exports of several projects are "all"
+func (a *App) exportProjectName(scope reportScope) (string, error) {
+	var names []string
+	if err := a.db.Model(&Project{}).Scopes(scope.filter).Distinct().Pluck(scope.label, &names).Error; err != nil {
+		return "", err
+	}
+	if len(names) != 1 {
+		return "all", nil
+	}
+	return names[0], nil
+}
+
+// exportFilePath builds the output path for a monthly (month != 0) or yearly export and creates its directory.
+// Returns an empty path if the user cancelled the save dialog, which is only shown for interactive exports
+func (a *App) exportFilePath(exportType ExportType, scope reportScope, year int, month time.Month, interactive bool) (string, error) {
+	root, err := a.exportRoot()
+	if err != nil {
+		return "", err
+	}
+
+	template := a.settings.YearlyExportTemplate
+	period := strconv.Itoa(year)
+	if month != 0 {
+		template = a.settings.MonthlyExportTemplate
+		period = fmt.Sprintf("%d-%s", year, month.String())
+	}
+	if err := validateExportTemplate(template, month == 0); err != nil {
+		return "", err
+	}
+	project, err := a.exportProjectName(scope)
+	if err != nil {
+		return "", err
+	}
+
+	monthName := ""
+	if month != 0 {
+		monthName = month.String()
+	}
+	relPath := expandExportTemplate(template, map[string]string{
+		"{type}":      string(exportType),
+		"{org}":       scope.name,
+		"{project}":   project,
+		"{year}":      strconv.Itoa(year),
+		"{month}":     monthName,
+		"{period}":    period,
+		"{timestamp}": time.Now().Format("20060102-150405"),
+	})
+	filePath := filepath.Join(root, relPath+"."+string(exportType))
+
+	useSaveDialog := interactive && a.settings.ExportUseSaveDialog
+	if useSaveDialog {
+		filePath, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:            fmt.Sprintf("Save %s export", strings.ToUpper(string(exportType))),
+			DefaultDirectory: filepath.Dir(filePath),
+			DefaultFilename:  filepath.Base(filePath),
+			Filters: []runtime.FileFilter{{
+				DisplayName: fmt.Sprintf("%s (*.%s)", strings.ToUpper(string(exportType)), exportType),
+				Pattern:     "*." + string(exportType),
+			}},
+		})
+		if err != nil || filePath == "" {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+
+	// The save dialog already asked the user about replacing an existing file
+	if !useSaveDialog && a.settings.ExportConflict == ExportVersion {
+		filePath = versionedPath(filePath)
+	}
+	return filePath, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportFilePath(t *testing.T) {
+	a := newTestApp(t)
+	root := t.TempDir()
+	a.settings.ExportDir = root
+	a.settings.ExportConflict = ExportOverwrite
+	a.settings.MonthlyExportTemplate = "{org}/{project}/{year}/{month}/hours"
+	a.settings.YearlyExportTemplate = "{org}/{project}/{year}/hours"
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, err := a.organizationScope("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := a.exportFilePath(CSV, scope, 2026, time.March, false)
+	if want := filepath.Join(root, "Acme", "Web", "2026", "March", "hours.csv"); err != nil || path != want {
+		t.Errorf("monthly export of a single project: %q, %v, want %q", path, err, want)
+	}
+
+	// An export of several projects has no single name to fill in
+	if _, err := a.NewProject("Acme", "Mobile"); err != nil {
+		t.Fatal(err)
+	}
+	path, err = a.exportFilePath(PDF, scope, 2026, 0, false)
+	if want := filepath.Join(root, "Acme", "all", "2026", "hours.pdf"); err != nil || path != want {
+		t.Errorf("yearly export of several projects: %q, %v, want %q", path, err, want)
+	}
+
+	// Client exports name the project with its organization
+	client, err := a.SaveClient(Client{Name: "Globex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.db.Model(&Project{}).Where("name = ?", "Web").Update("client_id", client.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if scope, err = a.clientScope(client.ID); err != nil {
+		t.Fatal(err)
+	}
+	path, err = a.exportFilePath(CSV, scope, 2026, 0, false)
+	if want := filepath.Join(root, "Globex", "Acme _ Web", "2026", "hours.csv"); err != nil || path != want {
+		t.Errorf("yearly export of a client's project: %q, %v, want %q", path, err, want)
+	}
+
+	// Yearly exports have no month
+	a.settings.YearlyExportTemplate = "{org}/{year}/{month}/hours"
+	if _, err := a.exportFilePath(PDF, scope, 2026, 0, false); err == nil {
+		t.Error("yearly template with {month} was accepted")
+	}
+	if err := validateExportTemplate(a.settings.YearlyExportTemplate, false); err != nil {
+		t.Errorf("monthly template with {month} was rejected: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+import { useAppStore } from "@/stores/main";
+import { dateString, errorMessage } from "@/utils/utils";
+import {
+  AddAbsence,
+  DeleteAbsence,
+  GetAbsences,
+  GetLeaveBalances,
+  ImportHolidays,
+  SaveLeaveAllowance,
+} from "@go/main/App";
+import { main } from "@go/models";
+import DeleteIcon from "@mui/icons-material/Delete";
+import {
+  Button,
+  Checkbox,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  FormControlLabel,
+  IconButton,
+  List,
+  ListItem,
+  ListItemText,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum AbsenceType {
+  Vacation = "vacation",
+  Sick = "sick",
+  PublicHoliday = "public_holiday",
+}
+
+const absenceTypeNames: Record<string, string> = {
+  [AbsenceType.Vacation]: "Vacation",
+  [AbsenceType.Sick]: "Sick",
+  [AbsenceType.PublicHoliday]: "Public holiday",
+};
+
+interface AbsencesDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const AbsencesDialog: React.FC<AbsencesDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const [year, setYear] = useState(new Date().getFullYear());
+  const [absences, setAbsences] = useState<main.Absence[]>([]);
+  const [balances, setBalances] = useState<main.LeaveBalance[]>([]);
+  const [startDate, setStartDate] = useState(dateString());
+  const [endDate, setEndDate] = useState(dateString());
+  const [type, setType] = useState<string>(AbsenceType.Vacation);
+  const [name, setName] = useState("");
+  const [hours, setHours] = useState(0);
+  const [allOrganizations, setAllOrganizations] = useState(false);
+
+  const organizationID = () => (allOrganizations ? 0 : activeOrg?.id ?? 0);
+
+  const load = async () => {
+    if (!activeOrg) return;
+    const [allAbsences, allBalances] = await Promise.all([
+      GetAbsences(activeOrg.id, `${year}-01-01`, `${year}-12-31`),
+      GetLeaveBalances(activeOrg.id, year),
+    ]);
+    setAbsences(allAbsences);
+    setBalances(allBalances);
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    load().catch((err) => toast.error(`Failed to load absences: ${errorMessage(err)}`));
+  }, [open, activeOrg, year]);
+
+  const handleAdd = () => {
+    AddAbsence(organizationID(), startDate, endDate, type, name, Math.round(hours * 60))
+      .then(async (added) => {
+        await load();
+        toast.success(`${added.length} day(s) added`);
+      })
+      .catch((err) => toast.error(`Failed to add absence: ${errorMessage(err)}`));
+  };
+
+  const handleImport = () => {
+    ImportHolidays(organizationID())
+      .then(async (imported) => {
+        await load();
+        if (imported) toast.success(`${imported} holiday(s) imported`);
+      })
+      .catch((err) => toast.error(`Failed to import holidays: ${errorMessage(err)}`));
+  };
+
+  const handleDelete = (absence: main.Absence) => {
+    DeleteAbsence(absence.id)
+      .then(load)
+      .catch((err) => toast.error(`Failed to delete absence: ${errorMessage(err)}`));
+  };
+
+  const handleAllowance = (balance: main.LeaveBalance, changes: Partial<main.LeaveAllowance>) => {
+    if (!activeOrg) return;
+    const allowance = main.LeaveAllowance.createFrom({
+      organization_id: activeOrg.id,
+      year,
+      type: balance.type,
+      days: balance.allowance_days,
+      carry_over_days: balance.carry_over_days,
+      ...changes,
+    });
+    SaveLeaveAllowance(allowance)
+      .then(load)
+      .catch((err) => toast.error(`Failed to save allowance: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="md">
+      <DialogTitle>Absences ({activeOrg?.name})</DialogTitle>
+      <DialogContent>
+        <Stack spacing={2} sx={{ mt: 1 }}>
+          <Stack direction="row" spacing={1}>
+            <TextField
+              type="date"
+              size="small"
+              label="From"
+              value={startDate}
+              InputLabelProps={{ shrink: true }}
+              onChange={(e) => setStartDate(e.target.value)}
+            />
+            <TextField
+              type="date"
+              size="small"
+              label="To"
+              value={endDate}
+              InputLabelProps={{ shrink: true }}
+              onChange={(e) => setEndDate(e.target.value)}
+            />
+            <TextField select size="small" label="Type" value={type} onChange={(e) => setType(e.target.value)}>
+              {Object.values(AbsenceType).map((value) => (
+                <MenuItem key={value} value={value}>
+                  {absenceTypeNames[value]}
+                </MenuItem>
+              ))}
+            </TextField>
+            <TextField size="small" label="Name" value={name} onChange={(e) => setName(e.target.value)} />
+            <TextField
+              type="number"
+              size="small"
+              label="Hours (0 = full day)"
+              value={hours}
+              inputProps={{ min: 0, max: 24, step: 0.5 }}
+              onChange={(e) => setHours(Number(e.target.value))}
+            />
+          </Stack>
+          <Stack direction="row" spacing={1}>
+            <FormControlLabel
+              label="All organizations"
+              control={<Checkbox checked={allOrganizations} onChange={(e) => setAllOrganizations(e.target.checked)} />}
+            />
+            <Button variant="contained" onClick={handleAdd}>
+              Add
+            </Button>
+            <Button onClick={handleImport}>Import holidays (ICS)</Button>
+          </Stack>
+
+          <Stack direction="row" spacing={2} alignItems="center">
+            <Typography variant="subtitle1">Leave in</Typography>
+            <TextField
+              type="number"
+              size="small"
+              value={year}
+              onChange={(e) => setYear(Number(e.target.value))}
+              sx={{ width: 100 }}
+            />
+          </Stack>
+          {balances.map((balance) => (
+            <Stack key={balance.type} direction="row" spacing={2} alignItems="center">
+              <Typography sx={{ width: 120 }}>{absenceTypeNames[balance.type]}</Typography>
+              <TextField
+                type="number"
+                size="small"
+                label="Allowance (days)"
+                defaultValue={balance.allowance_days}
+                onBlur={(e) => handleAllowance(balance, { days: Number(e.target.value) })}
+              />
+              <TextField
+                type="number"
+                size="small"
+                label="Carried over (days)"
+                defaultValue={balance.carry_over_days}
+                onBlur={(e) => handleAllowance(balance, { carry_over_days: Number(e.target.value) })}
+              />
+              <Typography>
+                {balance.used_days.toFixed(1)} taken, {balance.remaining_days.toFixed(1)} left
+              </Typography>
+            </Stack>
+          ))}
+
+          <List dense>
+            {absences.map((absence) => (
+              <ListItem
+                key={absence.id}
+                secondaryAction={
+                  <IconButton onClick={() => handleDelete(absence)}>
+                    <DeleteIcon fontSize="small" />
+                  </IconButton>
+                }
+              >
+                <ListItemText
+                  primary={`${absence.date} ${absenceTypeNames[absence.type]}${absence.name ? ` – ${absence.name}` : ""}`}
+                  secondary={`${absence.minutes ? `${(absence.minutes / 60).toFixed(2)}h` : "Full day"}${
+                    absence.organization_id === 0 ? " · all organizations" : ""
+                  }`}
+                />
+              </ListItem>
+            ))}
+          </List>
+        </Stack>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default AbsencesDialog;
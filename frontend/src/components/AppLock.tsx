@@ -0,0 +1,71 @@
+import { useAppStore } from "@/stores/main";
+import { errorMessage } from "@/utils/utils";
+import { GetEncryptionStatus, Unlock } from "@go/main/App";
+import { main } from "@go/models";
+import { Box, Button, Paper, Stack, TextField, Typography } from "@mui/material";
+import React, { useEffect, useState } from "react";
+
+interface AppLockProps {
+  children: React.ReactNode;
+}
+
+/**
+ * Asks for the passphrase of an encrypted database before the app is shown
+ */
+const AppLock: React.FC<AppLockProps> = ({ children }) => {
+  const [status, setStatus] = useState<main.EncryptionStatus>();
+  const [passphrase, setPassphrase] = useState("");
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    GetEncryptionStatus().then(setStatus);
+  }, []);
+
+  useEffect(() => {
+    if (status && !status.locked) {
+      useAppStore.getState().loadSettings().catch(console.error);
+    }
+  }, [status?.locked]);
+
+  const handleUnlock = (e: React.FormEvent) => {
+    e.preventDefault();
+    Unlock(passphrase)
+      .then(() => {
+        setPassphrase("");
+        return GetEncryptionStatus().then(setStatus);
+      })
+      .catch((err) => setError(errorMessage(err)));
+  };
+
+  if (!status) return null;
+  if (!status.locked) return <>{children}</>;
+
+  return (
+    <Box sx={{ display: "flex", alignItems: "center", justifyContent: "center", minHeight: "100vh" }}>
+      <Paper sx={{ p: 4, width: 400 }}>
+        <form onSubmit={handleUnlock}>
+          <Stack spacing={2}>
+            <Typography variant="h6">Go Work Tracker is locked</Typography>
+            {status.mode === "passphrase" ? (
+              <TextField
+                autoFocus
+                type="password"
+                label="Passphrase"
+                value={passphrase}
+                onChange={(e) => setPassphrase(e.target.value)}
+              />
+            ) : (
+              <Typography color="text.secondary">{status.error}</Typography>
+            )}
+            {error && <Typography color="error">{error}</Typography>}
+            <Button type="submit" variant="contained">
+              {status.mode === "passphrase" ? "Unlock" : "Try again"}
+            </Button>
+          </Stack>
+        </form>
+      </Paper>
+    </Box>
+  );
+};
+
+export default AppLock;
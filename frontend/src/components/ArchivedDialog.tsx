@@ -0,0 +1,90 @@
+import { useAppStore } from "@/stores/main";
+import { ArchiveFilter, errorMessage, handleSort } from "@/utils/utils";
+import { GetOrganizations, GetProjects, UnarchiveOrganization, UnarchiveProject } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  List,
+  ListItem,
+  ListItemText,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+interface ArchivedDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const ArchivedDialog: React.FC<ArchivedDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const organizations = useAppStore((state) => state.organizations);
+  const setOrganizations = useAppStore((state) => state.setOrganizations);
+  const projects = useAppStore((state) => state.projects);
+  const setProjects = useAppStore((state) => state.setProjects);
+  const [archivedOrgs, setArchivedOrgs] = useState<main.Organization[]>([]);
+  const [archivedProjs, setArchivedProjs] = useState<main.Project[]>([]);
+
+  useEffect(() => {
+    if (!open) return;
+    GetOrganizations(ArchiveFilter.Archived).then(setArchivedOrgs);
+    if (activeOrg) GetProjects(activeOrg.id, ArchiveFilter.Archived).then(setArchivedProjs);
+  }, [open, activeOrg]);
+
+  const unarchiveOrganization = (organization: main.Organization) => {
+    UnarchiveOrganization(organization.id)
+      .then((restored) => {
+        setArchivedOrgs(archivedOrgs.filter((org) => org.id !== restored.id));
+        setOrganizations([...organizations, restored].sort(handleSort));
+      })
+      .catch((err) => toast.error(`Failed to unarchive ${organization.name}: ${errorMessage(err)}`));
+  };
+
+  const unarchiveProject = (project: main.Project) => {
+    UnarchiveProject(project.id)
+      .then((restored) => {
+        setArchivedProjs(archivedProjs.filter((proj) => proj.id !== restored.id));
+        setProjects([...projects, restored].sort(handleSort));
+      })
+      .catch((err) => toast.error(`Failed to unarchive ${project.name}: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Archived</DialogTitle>
+      <DialogContent>
+        <Typography variant="h6">Organizations</Typography>
+        {archivedOrgs.length === 0 && <Typography color="text.secondary">No archived organizations</Typography>}
+        <List dense>
+          {archivedOrgs.map((org) => (
+            <ListItem key={org.id} secondaryAction={<Button onClick={() => unarchiveOrganization(org)}>Unarchive</Button>}>
+              <ListItemText primary={org.name} />
+            </ListItem>
+          ))}
+        </List>
+
+        <Typography variant="h6" sx={{ mt: 2 }}>
+          Projects of {activeOrg?.name}
+        </Typography>
+        {archivedProjs.length === 0 && <Typography color="text.secondary">No archived projects</Typography>}
+        <List dense>
+          {archivedProjs.map((proj) => (
+            <ListItem key={proj.id} secondaryAction={<Button onClick={() => unarchiveProject(proj)}>Unarchive</Button>}>
+              <ListItemText primary={proj.name} />
+            </ListItem>
+          ))}
+        </List>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default ArchivedDialog;
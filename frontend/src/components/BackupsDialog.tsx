@@ -0,0 +1,427 @@
+import { errorMessage } from "@/utils/utils";
+import {
+  ConfirmAction,
+  DeleteBackupTarget,
+  FindRemoteBackups,
+  GetBackupTargets,
+  GetEncryptionStatus,
+  GetRemoteBackups,
+  RestoreBackup,
+  RestoreRemoteBackup,
+  RunBackup,
+  SaveBackupTarget,
+  VerifyBackup,
+} from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Checkbox,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  FormControlLabel,
+  List,
+  ListItem,
+  ListItemText,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum BackupKind {
+  S3 = "s3",
+  WebDAV = "webdav",
+}
+
+const backupKindNames: Record<string, string> = {
+  [BackupKind.S3]: "S3-compatible (AWS, MinIO, B2)",
+  [BackupKind.WebDAV]: "WebDAV (Nextcloud, ownCloud)",
+};
+
+const newTarget = () =>
+  main.BackupTarget.createFrom({
+    id: 0,
+    name: "",
+    kind: BackupKind.S3,
+    endpoint: "",
+    bucket: "",
+    region: "",
+    folder: "worktracker",
+    username: "",
+    password: "",
+    passphrase: "",
+    interval_hours: 24,
+    keep_count: 14,
+    enabled: true,
+    last_error: "",
+  });
+
+const endpointPlaceholders: Record<string, string> = {
+  [BackupKind.S3]: "https://s3.eu-central-1.amazonaws.com",
+  [BackupKind.WebDAV]: "https://cloud.example.com/remote.php/dav/files/me",
+};
+
+const formatSize = (bytes: number) => (bytes ? `${(bytes / 1024).toFixed(0)} KB` : "");
+
+interface BackupsDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const BackupsDialog: React.FC<BackupsDialogProps> = ({ open, setOpen }) => {
+  const [targets, setTargets] = useState<main.BackupTarget[]>([]);
+  const [target, setTarget] = useState<main.BackupTarget>(newTarget());
+  const [backups, setBackups] = useState<main.RemoteBackup[]>([]);
+  const [oldPassphrase, setOldPassphrase] = useState("");
+  const [busy, setBusy] = useState(false);
+  const [encrypted, setEncrypted] = useState(false);
+
+  const loadBackups = (targetID: number) => {
+    GetRemoteBackups(targetID)
+      .then(setBackups)
+      .catch((err) => {
+        setBackups([]);
+        toast.error(`Failed to list the backups: ${errorMessage(err)}`);
+      });
+  };
+
+  const load = (selectID?: number) => {
+    GetBackupTargets()
+      .then((loaded) => {
+        setTargets(loaded);
+        const selected = loaded.find((t) => t.id === selectID) ?? loaded[0];
+        setTarget(selected ?? newTarget());
+        setBackups([]);
+        if (selected) loadBackups(selected.id);
+      })
+      .catch((err) => toast.error(`Failed to load the backup targets: ${errorMessage(err)}`));
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    load();
+    GetEncryptionStatus().then((status) => setEncrypted(status.mode !== "none"));
+  }, [open]);
+
+  const update = (changes: Partial<main.BackupTarget>) => {
+    setTarget(main.BackupTarget.createFrom({ ...target, ...changes }));
+  };
+
+  const handleSelect = (selected?: main.BackupTarget) => {
+    setTarget(selected ?? newTarget());
+    setBackups([]);
+    setOldPassphrase("");
+    if (selected) loadBackups(selected.id);
+  };
+
+  const handleSave = () => {
+    SaveBackupTarget(target)
+      .then((saved) => {
+        toast.success("Backup target saved");
+        load(saved.id);
+      })
+      .catch((err) => toast.error(`Failed to save the backup target: ${errorMessage(err)}`));
+  };
+
+  const handleDelete = () => {
+    ConfirmAction(
+      `Delete ${target.name}`,
+      "Are you sure you want to delete this backup target? The backups already uploaded are kept.",
+    ).then((confirmed) => {
+      if (!confirmed) return;
+      DeleteBackupTarget(target.id)
+        .then(() => load())
+        .catch((err) => toast.error(`Failed to delete the backup target: ${errorMessage(err)}`));
+    });
+  };
+
+  const handleRun = () => {
+    setBusy(true);
+    RunBackup(target.id)
+      .then((run) => {
+        if (run.error) {
+          toast.error(`Backup failed: ${run.error}`);
+        } else {
+          toast.success("Backup uploaded and verified");
+        }
+        load(target.id);
+      })
+      .catch((err) => toast.error(`Backup failed: ${errorMessage(err)}`))
+      .finally(() => setBusy(false));
+  };
+
+  const handleVerify = (backup: main.RemoteBackup) => {
+    setBusy(true);
+    VerifyBackup(target.id, backup.name, oldPassphrase)
+      .then(() => {
+        toast.success(`${backup.name} is intact`);
+        loadBackups(target.id);
+      })
+      .catch((err) => toast.error(`Verification failed: ${errorMessage(err)}`))
+      .finally(() => setBusy(false));
+  };
+
+  // A target that isn't saved yet is searched with the settings typed in, to restore onto a new installation
+  const handleFind = () => {
+    setBusy(true);
+    FindRemoteBackups(target)
+      .then((found) => {
+        setBackups(found);
+        if (found.length === 0) toast.info("No backups found on this target");
+      })
+      .catch((err) => toast.error(`Failed to list the backups: ${errorMessage(err)}`))
+      .finally(() => setBusy(false));
+  };
+
+  const handleRestore = (backup: main.RemoteBackup) => {
+    ConfirmAction(
+      "Restore backup",
+      `Replace all data with the backup ${backup.name}? Running timers are stopped and the current database is kept as a .before-restore file.`,
+    ).then((confirmed) => {
+      if (!confirmed) return;
+      setBusy(true);
+      const restore =
+        target.id === 0
+          ? RestoreRemoteBackup(target, backup.name)
+          : RestoreBackup(target.id, backup.name, oldPassphrase);
+      restore
+        // Reload so every view shows the restored data
+        .then(() => window.location.reload())
+        .catch((err) => toast.error(`Restore failed: ${errorMessage(err)}`))
+        .finally(() => setBusy(false));
+    });
+  };
+
+  const isS3 = target.kind === BackupKind.S3;
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="md">
+      <DialogTitle>Remote Backups</DialogTitle>
+      <DialogContent>
+        <Stack spacing={2} sx={{ mt: 1 }}>
+          <Typography variant="body2" color="text.secondary">
+            Backups are compressed and encrypted with the backup passphrase before they are uploaded, keep it somewhere
+            safe: without it a backup can't be restored.
+          </Typography>
+          {!encrypted && (
+            <Typography variant="body2" color="warning.main">
+              The credentials of backup targets are stored in the database, encrypt it first (Database Encryption in the menu)
+              to set up a target or restore a backup.
+            </Typography>
+          )}
+          <TextField
+            select
+            size="small"
+            label="Target"
+            value={target.id}
+            onChange={(e) => handleSelect(targets.find((t) => t.id === Number(e.target.value)))}
+          >
+            {targets.map((t) => (
+              <MenuItem key={t.id} value={t.id}>
+                {t.name}
+              </MenuItem>
+            ))}
+            <MenuItem value={0}>New target…</MenuItem>
+          </TextField>
+          <Stack direction="row" spacing={1}>
+            <TextField
+              size="small"
+              label="Name"
+              value={target.name}
+              onChange={(e) => update({ name: e.target.value })}
+            />
+            <TextField
+              select
+              size="small"
+              label="Kind"
+              value={target.kind}
+              onChange={(e) => update({ kind: e.target.value })}
+              sx={{ minWidth: 260 }}
+            >
+              {Object.values(BackupKind).map((value) => (
+                <MenuItem key={value} value={value}>
+                  {backupKindNames[value]}
+                </MenuItem>
+              ))}
+            </TextField>
+          </Stack>
+          <TextField
+            size="small"
+            label={isS3 ? "Endpoint" : "WebDAV URL"}
+            placeholder={endpointPlaceholders[target.kind]}
+            value={target.endpoint}
+            onChange={(e) => update({ endpoint: e.target.value })}
+          />
+          <Stack direction="row" spacing={1}>
+            {isS3 && (
+              <>
+                <TextField
+                  size="small"
+                  label="Bucket"
+                  value={target.bucket}
+                  onChange={(e) => update({ bucket: e.target.value })}
+                />
+                <TextField
+                  size="small"
+                  label="Region"
+                  placeholder="us-east-1"
+                  value={target.region}
+                  onChange={(e) => update({ region: e.target.value })}
+                />
+              </>
+            )}
+            <TextField
+              size="small"
+              label="Folder"
+              value={target.folder}
+              onChange={(e) => update({ folder: e.target.value })}
+            />
+          </Stack>
+          <Stack direction="row" spacing={1}>
+            <TextField
+              size="small"
+              label={isS3 ? "Access key" : "Username"}
+              value={target.username}
+              onChange={(e) => update({ username: e.target.value })}
+            />
+            <TextField
+              size="small"
+              type="password"
+              label={isS3 ? "Secret key" : "Password"}
+              helperText={target.password_set ? "Leave empty to keep the saved one" : undefined}
+              value={target.password}
+              onChange={(e) => update({ password: e.target.value })}
+            />
+          </Stack>
+          <TextField
+            size="small"
+            type="password"
+            label="Backup passphrase"
+            helperText={
+              target.passphrase_set
+                ? "Leave empty to keep the saved one. Backups made before a change still need the passphrase they were made with"
+                : "Backups made before a change still need the passphrase they were made with"
+            }
+            value={target.passphrase}
+            onChange={(e) => update({ passphrase: e.target.value })}
+          />
+          <Stack direction="row" spacing={1} alignItems="center">
+            <TextField
+              type="number"
+              size="small"
+              label="Every (hours)"
+              helperText="0 backs up on demand only"
+              value={target.interval_hours}
+              onChange={(e) => update({ interval_hours: Number(e.target.value) })}
+            />
+            <TextField
+              type="number"
+              size="small"
+              label="Keep"
+              helperText="0 keeps all backups"
+              value={target.keep_count}
+              onChange={(e) => update({ keep_count: Number(e.target.value) })}
+            />
+            <FormControlLabel
+              label="Enabled"
+              control={<Checkbox checked={target.enabled} onChange={(e) => update({ enabled: e.target.checked })} />}
+            />
+          </Stack>
+          {target.id === 0 && (
+            <Typography variant="body2" color="text.secondary">
+              On a new installation, fill in the settings of the target the backups were made to and find them to
+              restore one, the restored database brings its targets along.
+            </Typography>
+          )}
+          {target.id !== 0 && (
+            <>
+              <Typography variant="body2" color="text.secondary">
+                Last backup: {target.last_backup_at ? new Date(target.last_backup_at).toLocaleString() : "never"}
+              </Typography>
+              {target.last_error && <Typography color="error">{target.last_error}</Typography>}
+            </>
+          )}
+          {(target.id !== 0 || backups.length > 0) && (
+            <>
+              <List dense>
+                {backups.map((backup) => (
+                  <ListItem
+                    key={backup.name}
+                    secondaryAction={
+                      <>
+                        {target.id !== 0 && (
+                          <Button size="small" disabled={busy} onClick={() => handleVerify(backup)}>
+                            Verify
+                          </Button>
+                        )}
+                        <Button
+                          size="small"
+                          color="warning"
+                          disabled={busy || !encrypted}
+                          onClick={() => handleRestore(backup)}
+                        >
+                          Restore
+                        </Button>
+                      </>
+                    }
+                  >
+                    <ListItemText
+                      primary={new Date(backup.created_at).toLocaleString()}
+                      secondary={[
+                        backup.name,
+                        formatSize(backup.size),
+                        backup.verified_at && `verified ${new Date(backup.verified_at).toLocaleString()}`,
+                      ]
+                        .filter(Boolean)
+                        .join(" · ")}
+                    />
+                  </ListItem>
+                ))}
+                {backups.length === 0 && (
+                  <Typography variant="body2" color="text.secondary">
+                    No backups on this target yet.
+                  </Typography>
+                )}
+              </List>
+              {target.id !== 0 && (
+                <TextField
+                  size="small"
+                  type="password"
+                  label="Passphrase of older backups"
+                  helperText="Only needed to verify or restore a backup made with another passphrase"
+                  value={oldPassphrase}
+                  onChange={(e) => setOldPassphrase(e.target.value)}
+                />
+              )}
+            </>
+          )}
+        </Stack>
+      </DialogContent>
+      <DialogActions>
+        {target.id !== 0 && (
+          <Button color="error" onClick={handleDelete}>
+            Delete
+          </Button>
+        )}
+        {target.id === 0 && (
+          <Button onClick={handleFind} disabled={busy}>
+            Find backups
+          </Button>
+        )}
+        <Button onClick={handleSave} disabled={!encrypted}>
+          Save
+        </Button>
+        <Button onClick={handleRun} disabled={target.id === 0 || busy}>
+          Back up now
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default BackupsDialog;
@@ -0,0 +1,237 @@
+import { ArchiveFilter, errorMessage } from "@/utils/utils";
+import {
+  DeleteClient,
+  ExportClientByMonth,
+  ExportClientByYear,
+  GetClients,
+  GetOrganizations,
+  SaveClient,
+  SetOrganizationClient,
+} from "@go/main/App";
+import { main } from "@go/models";
+import DeleteIcon from "@mui/icons-material/Delete";
+import {
+  Button,
+  Checkbox,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  FormControlLabel,
+  Grid2,
+  IconButton,
+  List,
+  ListItemButton,
+  ListItemText,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum ExportType {
+  CSV = "csv",
+  PDF = "pdf",
+}
+
+const emptyClient = () => main.Client.createFrom({ id: 0, name: "", billing_address: "", notes: "", contacts: [] });
+
+interface ClientsDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const ClientsDialog: React.FC<ClientsDialogProps> = ({ open, setOpen }) => {
+  const [clients, setClients] = useState<main.Client[]>([]);
+  const [organizations, setOrganizations] = useState<main.Organization[]>([]);
+  const [client, setClient] = useState<main.Client>(emptyClient());
+
+  const load = async () => {
+    const [allClients, allOrgs] = await Promise.all([GetClients(), GetOrganizations(ArchiveFilter.All)]);
+    setClients(allClients);
+    setOrganizations(allOrgs);
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    load().catch((err) => toast.error(`Failed to load clients: ${errorMessage(err)}`));
+  }, [open]);
+
+  const updateContact = (index: number, changes: Partial<main.ClientContact>) => {
+    const contacts = [...client.contacts];
+    contacts[index] = main.ClientContact.createFrom({ ...contacts[index], ...changes });
+    setClient(main.Client.createFrom({ ...client, contacts }));
+  };
+
+  const handleSave = () => {
+    SaveClient(client)
+      .then(async (saved) => {
+        setClient(saved);
+        await load();
+        toast.success(`${saved.name} saved`);
+      })
+      .catch((err) => toast.error(`Failed to save ${client.name}: ${errorMessage(err)}`));
+  };
+
+  const handleDelete = () => {
+    DeleteClient(client.id)
+      .then(async () => {
+        setClient(emptyClient());
+        await load();
+      })
+      .catch((err) => toast.error(`Failed to delete ${client.name}: ${errorMessage(err)}`));
+  };
+
+  const toggleOrganization = (organization: main.Organization, checked: boolean) => {
+    SetOrganizationClient(organization.id, checked ? client.id : 0)
+      .then(load)
+      .catch((err) => toast.error(`Failed to update ${organization.name}: ${errorMessage(err)}`));
+  };
+
+  const handleExport = (type: ExportType, monthly: boolean) => {
+    const now = new Date();
+    const exported = monthly
+      ? ExportClientByMonth(type, client.id, now.getFullYear(), now.getMonth() + 1)
+      : ExportClientByYear(type, client.id, now.getFullYear());
+    exported
+      .then((path) => path && toast.success(`Exported to ${path}`))
+      .catch((err) => toast.error(`Failed to export ${client.name}: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="md">
+      <DialogTitle>Clients</DialogTitle>
+      <DialogContent>
+        <Grid2 container spacing={2}>
+          <Grid2 size={4}>
+            <List dense>
+              <ListItemButton selected={client.id === 0} onClick={() => setClient(emptyClient())}>
+                <ListItemText primary="New client" />
+              </ListItemButton>
+              {clients.map((c) => (
+                <ListItemButton key={c.id} selected={c.id === client.id} onClick={() => setClient(c)}>
+                  <ListItemText primary={c.name} />
+                </ListItemButton>
+              ))}
+            </List>
+          </Grid2>
+          <Grid2 size={8}>
+            <Stack spacing={2} sx={{ mt: 1 }}>
+              <TextField
+                label="Name"
+                size="small"
+                value={client.name}
+                onChange={(e) => setClient(main.Client.createFrom({ ...client, name: e.target.value }))}
+              />
+              <TextField
+                label="Billing address"
+                size="small"
+                multiline
+                minRows={3}
+                value={client.billing_address}
+                onChange={(e) => setClient(main.Client.createFrom({ ...client, billing_address: e.target.value }))}
+              />
+              <TextField
+                label="Notes"
+                size="small"
+                multiline
+                minRows={2}
+                value={client.notes}
+                onChange={(e) => setClient(main.Client.createFrom({ ...client, notes: e.target.value }))}
+              />
+
+              <Typography variant="subtitle1">Contacts</Typography>
+              {client.contacts.map((contact, index) => (
+                <Stack key={index} direction="row" spacing={1}>
+                  <TextField
+                    label="Name"
+                    size="small"
+                    value={contact.name}
+                    onChange={(e) => updateContact(index, { name: e.target.value })}
+                  />
+                  <TextField
+                    label="Role"
+                    size="small"
+                    value={contact.role}
+                    onChange={(e) => updateContact(index, { role: e.target.value })}
+                  />
+                  <TextField
+                    label="Email"
+                    size="small"
+                    value={contact.email}
+                    onChange={(e) => updateContact(index, { email: e.target.value })}
+                  />
+                  <TextField
+                    label="Phone"
+                    size="small"
+                    value={contact.phone}
+                    onChange={(e) => updateContact(index, { phone: e.target.value })}
+                  />
+                  <IconButton
+                    onClick={() =>
+                      setClient(main.Client.createFrom({ ...client, contacts: client.contacts.filter((_, i) => i !== index) }))
+                    }
+                  >
+                    <DeleteIcon fontSize="small" />
+                  </IconButton>
+                </Stack>
+              ))}
+              <Button
+                onClick={() =>
+                  setClient(
+                    main.Client.createFrom({
+                      ...client,
+                      contacts: [...client.contacts, main.ClientContact.createFrom({ name: "", role: "", email: "", phone: "" })],
+                    }),
+                  )
+                }
+              >
+                Add contact
+              </Button>
+
+              {client.id !== 0 && (
+                <>
+                  <Typography variant="subtitle1">Organizations billed to {client.name}</Typography>
+                  {organizations.map((organization) => (
+                    <FormControlLabel
+                      key={organization.id}
+                      label={organization.name}
+                      control={
+                        <Checkbox
+                          checked={organization.client_id === client.id}
+                          onChange={(e) => toggleOrganization(organization, e.target.checked)}
+                        />
+                      }
+                    />
+                  ))}
+
+                  <Typography variant="subtitle1">Export hours across organizations</Typography>
+                  <Stack direction="row" spacing={1}>
+                    <Button onClick={() => handleExport(ExportType.CSV, true)}>This month (CSV)</Button>
+                    <Button onClick={() => handleExport(ExportType.PDF, true)}>This month (PDF)</Button>
+                    <Button onClick={() => handleExport(ExportType.CSV, false)}>This year (CSV)</Button>
+                    <Button onClick={() => handleExport(ExportType.PDF, false)}>This year (PDF)</Button>
+                  </Stack>
+                </>
+              )}
+            </Stack>
+          </Grid2>
+        </Grid2>
+      </DialogContent>
+      <DialogActions>
+        {client.id !== 0 && (
+          <Button color="error" onClick={handleDelete}>
+            Delete
+          </Button>
+        )}
+        <Button onClick={handleSave} disabled={!client.name.trim()}>
+          Save
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default ClientsDialog;
@@ -0,0 +1,132 @@
+import { useAppStore } from "@/stores/main";
+import { dateString, errorMessage } from "@/utils/utils";
+import { GetComplianceReport, GetComplianceRules, SaveComplianceRules } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Checkbox,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  FormControlLabel,
+  Grid2,
+  List,
+  ListItem,
+  ListItemText,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+type LimitField =
+  | "max_daily_minutes"
+  | "max_weekly_minutes"
+  | "min_rest_minutes"
+  | "break_after_minutes"
+  | "min_break_minutes";
+
+const limits: { field: LimitField; label: string; unit: "hours" | "minutes" }[] = [
+  { field: "max_daily_minutes", label: "Daily maximum", unit: "hours" },
+  { field: "max_weekly_minutes", label: "Weekly maximum", unit: "hours" },
+  { field: "min_rest_minutes", label: "Rest between days", unit: "hours" },
+  { field: "break_after_minutes", label: "Break due after", unit: "hours" },
+  { field: "min_break_minutes", label: "Shortest break", unit: "minutes" },
+];
+
+interface ComplianceDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const ComplianceDialog: React.FC<ComplianceDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const [rules, setRules] = useState<main.ComplianceRules>();
+  const [violations, setViolations] = useState<main.ComplianceViolation[]>([]);
+
+  const loadViolations = (organizationID: number) => {
+    const now = new Date();
+    const firstOfMonth = new Date(now.getFullYear(), now.getMonth(), 1);
+    GetComplianceReport(organizationID, dateString(firstOfMonth), dateString(now)).then(setViolations);
+  };
+
+  useEffect(() => {
+    if (!open || !activeOrg) return;
+    GetComplianceRules(activeOrg.id)
+      .then(setRules)
+      .catch((err) => toast.error(`Failed to load working-time rules: ${errorMessage(err)}`));
+    loadViolations(activeOrg.id);
+  }, [open, activeOrg]);
+
+  const update = (changes: Partial<main.ComplianceRules>) => {
+    if (!rules) return;
+    setRules(main.ComplianceRules.createFrom({ ...rules, ...changes }));
+  };
+
+  const handleSave = () => {
+    if (!rules) return;
+    SaveComplianceRules(rules)
+      .then((saved) => {
+        setRules(saved);
+        loadViolations(saved.organization_id);
+        toast.success("Working-time rules saved");
+      })
+      .catch((err) => toast.error(`Failed to save working-time rules: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Working-Time Rules ({activeOrg?.name})</DialogTitle>
+      <DialogContent>
+        {rules && (
+          <Stack spacing={2} sx={{ mt: 1 }}>
+            <FormControlLabel
+              label="Check tracked time against these rules"
+              control={<Checkbox checked={rules.enabled} onChange={(e) => update({ enabled: e.target.checked })} />}
+            />
+            <Typography variant="body2" color="text.secondary">
+              Use 0 to turn a rule off. Warnings are shown while the timer runs.
+            </Typography>
+            <Grid2 container spacing={2}>
+              {limits.map(({ field, label, unit }) => (
+                <Grid2 key={field} size={6}>
+                  <TextField
+                    fullWidth
+                    type="number"
+                    size="small"
+                    label={`${label} (${unit})`}
+                    value={unit === "hours" ? rules[field] / 60 : rules[field]}
+                    inputProps={{ min: 0, step: unit === "hours" ? 0.5 : 5 }}
+                    onChange={(e) => {
+                      const value = Number(e.target.value);
+                      update({ [field]: Math.round(unit === "hours" ? value * 60 : value) });
+                    }}
+                  />
+                </Grid2>
+              ))}
+            </Grid2>
+            <Typography variant="subtitle1">This month</Typography>
+            {violations.length === 0 && <Typography color="text.secondary">No violations</Typography>}
+            <List dense>
+              {violations.map((violation, index) => (
+                <ListItem key={index}>
+                  <ListItemText primary={violation.message} secondary={violation.date} />
+                </ListItem>
+              ))}
+            </List>
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={handleSave} disabled={!rules}>
+          Save
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default ComplianceDialog;
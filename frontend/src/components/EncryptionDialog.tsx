@@ -0,0 +1,120 @@
+import { errorMessage } from "@/utils/utils";
+import { GetEncryptionStatus, SetEncryption } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum EncryptionMode {
+  None = "none",
+  Passphrase = "passphrase",
+  Keyring = "keyring",
+}
+
+interface EncryptionDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const EncryptionDialog: React.FC<EncryptionDialogProps> = ({ open, setOpen }) => {
+  const [status, setStatus] = useState<main.EncryptionStatus>();
+  const [mode, setMode] = useState<string>(EncryptionMode.None);
+  const [passphrase, setPassphrase] = useState("");
+  const [confirm, setConfirm] = useState("");
+  const [current, setCurrent] = useState("");
+
+  useEffect(() => {
+    if (!open) return;
+    GetEncryptionStatus().then((loaded) => {
+      setStatus(loaded);
+      setMode(loaded.mode);
+    });
+    setPassphrase("");
+    setConfirm("");
+    setCurrent("");
+  }, [open]);
+
+  const handleSave = () => {
+    if (mode === EncryptionMode.Passphrase && passphrase !== confirm) {
+      toast.error("The passphrases don't match");
+      return;
+    }
+    SetEncryption(mode, passphrase, current)
+      .then((saved) => {
+        setStatus(saved);
+        toast.success(
+          saved.mode === EncryptionMode.None ? "The database is no longer encrypted" : "The database is encrypted",
+        );
+        setOpen(false);
+      })
+      .catch((err) => toast.error(`Failed to change the encryption: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Database Encryption</DialogTitle>
+      <DialogContent>
+        {status && (
+          <Stack spacing={2} sx={{ mt: 1 }}>
+            <Typography variant="body2" color="text.secondary">
+              An encrypted database can only be read with its passphrase, or on this computer when its key is kept in
+              the system keyring. A forgotten passphrase can't be recovered.
+            </Typography>
+            <TextField select size="small" label="Encryption" value={mode} onChange={(e) => setMode(e.target.value)}>
+              <MenuItem value={EncryptionMode.None}>Not encrypted</MenuItem>
+              <MenuItem value={EncryptionMode.Passphrase}>Passphrase, asked on every start</MenuItem>
+              <MenuItem value={EncryptionMode.Keyring} disabled={!status.keyring_available}>
+                Key in the system keyring
+              </MenuItem>
+            </TextField>
+            {mode === EncryptionMode.Passphrase && (
+              <>
+                <TextField
+                  size="small"
+                  type="password"
+                  label="New passphrase"
+                  value={passphrase}
+                  onChange={(e) => setPassphrase(e.target.value)}
+                />
+                <TextField
+                  size="small"
+                  type="password"
+                  label="Repeat the new passphrase"
+                  value={confirm}
+                  onChange={(e) => setConfirm(e.target.value)}
+                />
+              </>
+            )}
+            {status.mode === EncryptionMode.Passphrase && (
+              <TextField
+                size="small"
+                type="password"
+                label="Current passphrase"
+                value={current}
+                onChange={(e) => setCurrent(e.target.value)}
+              />
+            )}
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={handleSave} disabled={!status}>
+          Save
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default EncryptionDialog;
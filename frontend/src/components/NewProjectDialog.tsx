@@ -1,7 +1,9 @@
 import { useAppStore } from "@/stores/main";
+import { errorMessage } from "@/utils/utils";
 import { NewProject, SetProject } from "@go/main/App";
 import { Button, Dialog, DialogActions, DialogContent, DialogContentText, DialogTitle, TextField } from "@mui/material";
 import { SubmitHandler, useForm } from "react-hook-form";
+import { toast } from "react-toastify";
 
 interface NewProjectDialogProps {
   openNewProj: boolean;
@@ -35,6 +37,7 @@ const NewProjectDialog: React.FC<NewProjectDialogProps> = ({ openNewProj, setOpe
       reset();
     } catch (e) {
       console.error(e);
+      toast.error(`Failed to create project: ${errorMessage(e)}`);
     }
   };
 
@@ -0,0 +1,117 @@
+import { useAppStore } from "@/stores/main";
+import { errorMessage, handleSort } from "@/utils/utils";
+import { GetParallelTimers, StartParallelTimer, StopParallelTimer } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  List,
+  ListItem,
+  ListItemText,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+const formatElapsed = (seconds: number) => {
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.floor((seconds % 3600) / 60);
+  return `${hours}h ${minutes.toString().padStart(2, "0")}m`;
+};
+
+interface ParallelTimersDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const ParallelTimersDialog: React.FC<ParallelTimersDialogProps> = ({ open, setOpen }) => {
+  const projects = useAppStore((state) => state.projects);
+  const [timers, setTimers] = useState<main.ParallelTimer[]>([]);
+  const [projectID, setProjectID] = useState(0);
+
+  const load = () => {
+    GetParallelTimers().then(setTimers);
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    load();
+    const interval = setInterval(load, 30 * 1000);
+    return () => clearInterval(interval);
+  }, [open]);
+
+  const handleStart = () => {
+    StartParallelTimer(projectID, 0)
+      .then(() => {
+        setProjectID(0);
+        load();
+      })
+      .catch((err) => toast.error(`Failed to start parallel timer: ${errorMessage(err)}`));
+  };
+
+  const handleStop = (timer: main.ParallelTimer) => {
+    StopParallelTimer(timer.project.id)
+      .then(load)
+      .catch((err) => toast.error(`Failed to save ${timer.project.name}: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Parallel Timers</DialogTitle>
+      <DialogContent>
+        <Typography variant="body2" color="text.secondary">
+          Parallel timers run next to the main timer. Overlapping time is counted for every project and listed
+          separately in the monthly exports.
+        </Typography>
+        <Stack direction="row" spacing={2} sx={{ mt: 2 }}>
+          <TextField
+            select
+            fullWidth
+            size="small"
+            label="Project"
+            value={projectID}
+            onChange={(e) => setProjectID(Number(e.target.value))}
+          >
+            <MenuItem value={0} disabled>
+              Select a project
+            </MenuItem>
+            {[...projects].sort(handleSort).map((project) => (
+              <MenuItem key={project.id} value={project.id}>
+                {project.name}
+              </MenuItem>
+            ))}
+          </TextField>
+          <Button variant="contained" onClick={handleStart} disabled={!projectID}>
+            Start
+          </Button>
+        </Stack>
+        {timers.length === 0 && (
+          <Typography color="text.secondary" sx={{ mt: 2 }}>
+            No parallel timers running
+          </Typography>
+        )}
+        <List dense>
+          {timers.map((timer) => (
+            <ListItem key={timer.project.id} secondaryAction={<Button onClick={() => handleStop(timer)}>Stop</Button>}>
+              <ListItemText
+                primary={`${timer.organization.name} / ${timer.project.name}`}
+                secondary={formatElapsed(timer.timeElapsed)}
+              />
+            </ListItem>
+          ))}
+        </List>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default ParallelTimersDialog;
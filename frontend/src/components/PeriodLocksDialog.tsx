@@ -0,0 +1,175 @@
+import { useAppStore } from "@/stores/main";
+import { dateString, errorMessage } from "@/utils/utils";
+import { GetPeriodLocks, LockPeriod, SetPeriodStatus, UnlockPeriod } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  List,
+  ListItem,
+  ListItemText,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum PeriodKind {
+  Week = "week",
+  Month = "month",
+}
+
+enum PeriodStatus {
+  Locked = "locked",
+  Submitted = "submitted",
+  Approved = "approved",
+}
+
+const statusNames: Record<string, string> = {
+  [PeriodStatus.Locked]: "Locked",
+  [PeriodStatus.Submitted]: "Submitted",
+  [PeriodStatus.Approved]: "Approved",
+};
+
+interface PeriodLocksDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const PeriodLocksDialog: React.FC<PeriodLocksDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const [year, setYear] = useState(new Date().getFullYear());
+  const [locks, setLocks] = useState<main.PeriodLock[]>([]);
+  const [kind, setKind] = useState<string>(PeriodKind.Month);
+  const [date, setDate] = useState(dateString());
+  const [note, setNote] = useState("");
+
+  const load = () => {
+    if (!activeOrg) return;
+    GetPeriodLocks(activeOrg.id, `${year}-01-01`, `${year}-12-31`)
+      .then(setLocks)
+      .catch((err) => toast.error(`Failed to load locked periods: ${errorMessage(err)}`));
+  };
+
+  useEffect(() => {
+    if (open) load();
+  }, [open, activeOrg, year]);
+
+  const handleLock = () => {
+    if (!activeOrg) return;
+    LockPeriod(activeOrg.id, kind, date, note)
+      .then(() => {
+        setNote("");
+        load();
+      })
+      .catch((err) => toast.error(`Failed to lock the period: ${errorMessage(err)}`));
+  };
+
+  const handleStatus = (lock: main.PeriodLock, status: PeriodStatus) => {
+    SetPeriodStatus(lock.id, status)
+      .then(load)
+      .catch((err) => toast.error(`Failed to update the period: ${errorMessage(err)}`));
+  };
+
+  const handleUnlock = (lock: main.PeriodLock) => {
+    UnlockPeriod(lock.id)
+      .then(load)
+      .catch((err) => toast.error(`Failed to unlock the period: ${errorMessage(err)}`));
+  };
+
+  const actions = (lock: main.PeriodLock) => {
+    switch (lock.status) {
+      case PeriodStatus.Locked:
+        return (
+          <>
+            <Button size="small" onClick={() => handleStatus(lock, PeriodStatus.Submitted)}>
+              Submit
+            </Button>
+            <Button size="small" color="error" onClick={() => handleUnlock(lock)}>
+              Unlock
+            </Button>
+          </>
+        );
+      case PeriodStatus.Submitted:
+        return (
+          <>
+            <Button size="small" onClick={() => handleStatus(lock, PeriodStatus.Approved)}>
+              Approve
+            </Button>
+            <Button size="small" onClick={() => handleStatus(lock, PeriodStatus.Locked)}>
+              Withdraw
+            </Button>
+          </>
+        );
+      default:
+        return (
+          <Button size="small" onClick={() => handleStatus(lock, PeriodStatus.Submitted)}>
+            Reopen
+          </Button>
+        );
+    }
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Timesheet Locks ({activeOrg?.name})</DialogTitle>
+      <DialogContent>
+        <Stack spacing={2} sx={{ mt: 1 }}>
+          <Typography variant="body2" color="text.secondary">
+            Time in a locked week or month can't be tracked, edited, moved or deleted until the period is unlocked.
+          </Typography>
+          <Stack direction="row" spacing={1}>
+            <TextField select size="small" label="Period" value={kind} onChange={(e) => setKind(e.target.value)}>
+              <MenuItem value={PeriodKind.Week}>Week</MenuItem>
+              <MenuItem value={PeriodKind.Month}>Month</MenuItem>
+            </TextField>
+            <TextField
+              type="date"
+              size="small"
+              label="Containing"
+              value={date}
+              InputLabelProps={{ shrink: true }}
+              onChange={(e) => setDate(e.target.value)}
+            />
+            <TextField size="small" label="Note" value={note} onChange={(e) => setNote(e.target.value)} />
+            <Button variant="contained" onClick={handleLock}>
+              Lock
+            </Button>
+          </Stack>
+
+          <Stack direction="row" spacing={2} alignItems="center">
+            <Typography variant="subtitle1">Locked in</Typography>
+            <TextField
+              type="number"
+              size="small"
+              value={year}
+              onChange={(e) => setYear(Number(e.target.value))}
+              sx={{ width: 100 }}
+            />
+          </Stack>
+          {locks.length === 0 && <Typography color="text.secondary">No locked periods</Typography>}
+          <List dense>
+            {locks.map((lock) => (
+              <ListItem key={lock.id} secondaryAction={actions(lock)}>
+                <ListItemText
+                  primary={`${lock.start_date} – ${lock.end_date} · ${statusNames[lock.status]}`}
+                  secondary={lock.note}
+                />
+              </ListItem>
+            ))}
+          </List>
+        </Stack>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default PeriodLocksDialog;
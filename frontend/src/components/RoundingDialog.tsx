@@ -0,0 +1,114 @@
+import { useAppStore } from "@/stores/main";
+import { errorMessage } from "@/utils/utils";
+import { GetRoundingPolicy, SaveRoundingPolicy } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  MenuItem,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+enum RoundingMode {
+  Up = "up",
+  Down = "down",
+  Nearest = "nearest",
+}
+
+enum RoundingLevel {
+  Session = "session",
+  Day = "day",
+  Period = "period",
+}
+
+interface RoundingDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const RoundingDialog: React.FC<RoundingDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const [policy, setPolicy] = useState<main.RoundingPolicy>();
+
+  useEffect(() => {
+    if (!open || !activeOrg) return;
+    GetRoundingPolicy(activeOrg.id)
+      .then(setPolicy)
+      .catch((err) => toast.error(`Failed to load rounding policy: ${errorMessage(err)}`));
+  }, [open, activeOrg]);
+
+  const update = (changes: Partial<main.RoundingPolicy>) => {
+    if (!policy) return;
+    setPolicy(main.RoundingPolicy.createFrom({ ...policy, ...changes }));
+  };
+
+  const handleSave = () => {
+    if (!policy) return;
+    SaveRoundingPolicy(policy)
+      .then(() => {
+        toast.success("Rounding policy saved");
+        setOpen(false);
+      })
+      .catch((err) => toast.error(`Failed to save rounding policy: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="xs">
+      <DialogTitle>Billing Rounding ({activeOrg?.name})</DialogTitle>
+      <DialogContent>
+        <Typography variant="body2" color="text.secondary">
+          Exports show the tracked and the billable time side by side. Use an increment of 0 to bill the exact time.
+        </Typography>
+        {policy && (
+          <Stack spacing={2} sx={{ mt: 2 }}>
+            <TextField
+              type="number"
+              size="small"
+              label="Increment (minutes)"
+              value={policy.increment_minutes}
+              inputProps={{ min: 0, max: 1440 }}
+              onChange={(e) => update({ increment_minutes: Number(e.target.value) })}
+            />
+            <TextField
+              select
+              size="small"
+              label="Round"
+              value={policy.mode}
+              onChange={(e) => update({ mode: e.target.value })}
+            >
+              <MenuItem value={RoundingMode.Up}>Up</MenuItem>
+              <MenuItem value={RoundingMode.Down}>Down</MenuItem>
+              <MenuItem value={RoundingMode.Nearest}>To nearest</MenuItem>
+            </TextField>
+            <TextField
+              select
+              size="small"
+              label="Apply to"
+              value={policy.level}
+              onChange={(e) => update({ level: e.target.value })}
+            >
+              <MenuItem value={RoundingLevel.Session}>Each work session</MenuItem>
+              <MenuItem value={RoundingLevel.Day}>Each day per project</MenuItem>
+              <MenuItem value={RoundingLevel.Period}>Each month per project</MenuItem>
+            </TextField>
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={handleSave} disabled={!policy}>
+          Save
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default RoundingDialog;
@@ -0,0 +1,144 @@
+import { useAppStore } from "@/stores/main";
+import { dateString, errorMessage } from "@/utils/utils";
+import { GetOvertime, GetWorkSchedule, SaveWorkSchedule } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Checkbox,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  FormControlLabel,
+  Grid2,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+type WeekdayField =
+  | "monday_minutes"
+  | "tuesday_minutes"
+  | "wednesday_minutes"
+  | "thursday_minutes"
+  | "friday_minutes"
+  | "saturday_minutes"
+  | "sunday_minutes";
+
+const weekdays: { field: WeekdayField; label: string }[] = [
+  { field: "monday_minutes", label: "Mon" },
+  { field: "tuesday_minutes", label: "Tue" },
+  { field: "wednesday_minutes", label: "Wed" },
+  { field: "thursday_minutes", label: "Thu" },
+  { field: "friday_minutes", label: "Fri" },
+  { field: "saturday_minutes", label: "Sat" },
+  { field: "sunday_minutes", label: "Sun" },
+];
+
+const formatHours = (seconds: number) => `${seconds < 0 ? "-" : "+"}${(Math.abs(seconds) / 3600).toFixed(2)}h`;
+
+interface ScheduleDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const ScheduleDialog: React.FC<ScheduleDialogProps> = ({ open, setOpen }) => {
+  const activeOrg = useAppStore((state) => state.activeOrg);
+  const [schedule, setSchedule] = useState<main.WorkSchedule>();
+  const [overtime, setOvertime] = useState<main.OvertimeSummary>();
+
+  const loadOvertime = (organizationID: number) => {
+    const now = new Date();
+    const firstOfMonth = new Date(now.getFullYear(), now.getMonth(), 1);
+    GetOvertime(organizationID, dateString(firstOfMonth), dateString(now)).then(setOvertime);
+  };
+
+  useEffect(() => {
+    if (!open || !activeOrg) return;
+    GetWorkSchedule(activeOrg.id)
+      .then(setSchedule)
+      .catch((err) => toast.error(`Failed to load work schedule: ${errorMessage(err)}`));
+    loadOvertime(activeOrg.id);
+  }, [open, activeOrg]);
+
+  const update = (changes: Partial<main.WorkSchedule>) => {
+    if (!schedule) return;
+    setSchedule(main.WorkSchedule.createFrom({ ...schedule, ...changes }));
+  };
+
+  const handleSave = () => {
+    if (!schedule) return;
+    SaveWorkSchedule(schedule)
+      .then((saved) => {
+        setSchedule(saved);
+        loadOvertime(saved.organization_id);
+        toast.success("Work schedule saved");
+      })
+      .catch((err) => toast.error(`Failed to save work schedule: ${errorMessage(err)}`));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Work Schedule ({activeOrg?.name})</DialogTitle>
+      <DialogContent>
+        {schedule && (
+          <Stack spacing={2} sx={{ mt: 1 }}>
+            <FormControlLabel
+              label="Track overtime against this schedule"
+              control={<Checkbox checked={schedule.enabled} onChange={(e) => update({ enabled: e.target.checked })} />}
+            />
+            <Grid2 container spacing={1}>
+              {weekdays.map(({ field, label }) => (
+                <Grid2 key={field} size="grow">
+                  <TextField
+                    type="number"
+                    size="small"
+                    label={label}
+                    value={schedule[field] / 60}
+                    inputProps={{ min: 0, max: 24, step: 0.25 }}
+                    onChange={(e) => update({ [field]: Math.round(Number(e.target.value) * 60) })}
+                  />
+                </Grid2>
+              ))}
+            </Grid2>
+            <Stack direction="row" spacing={2}>
+              <TextField
+                type="date"
+                size="small"
+                label="Count from"
+                value={schedule.start_date}
+                InputLabelProps={{ shrink: true }}
+                onChange={(e) => update({ start_date: e.target.value })}
+              />
+              <TextField
+                type="number"
+                size="small"
+                label="Opening balance (hours)"
+                value={schedule.opening_balance_seconds / 3600}
+                inputProps={{ step: 0.25 }}
+                onChange={(e) => update({ opening_balance_seconds: Math.round(Number(e.target.value) * 3600) })}
+              />
+            </Stack>
+            {overtime?.enabled && (
+              <Typography variant="body2">
+                This month: {(overtime.actual_seconds / 3600).toFixed(2)}h of{" "}
+                {(overtime.expected_seconds / 3600).toFixed(2)}h expected ({formatHours(overtime.difference_seconds)}).
+                Balance: {formatHours(overtime.balance_seconds)}
+              </Typography>
+            )}
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={handleSave} disabled={!schedule}>
+          Save
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default ScheduleDialog;
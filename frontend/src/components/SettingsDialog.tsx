@@ -1,7 +1,18 @@
 import { NumberInput } from "@/components/styled/NumberInput";
 import { useAppStore } from "@/stores/main";
+import { errorMessage } from "@/utils/utils";
+import {
+  ExportSettings,
+  GetSettings,
+  ImportSettings,
+  SelectExportDir,
+  SendTestEmail,
+  UpdateSettings,
+} from "@go/main/App";
+import { main } from "@go/models";
 import CloseIcon from "@mui/icons-material/Close";
 import {
+  Button,
   Checkbox,
   Dialog,
   DialogContent,
@@ -11,8 +22,13 @@ import {
   FormHelperText,
   IconButton,
   InputLabel,
+  MenuItem,
+  Select,
+  Stack,
+  TextField,
+  Typography,
 } from "@mui/material";
-import { useRef } from "react";
+import { useEffect, useRef, useState } from "react";
 
 import { toast } from "react-toastify";
 
@@ -48,6 +64,39 @@ const SettingsDialog: React.FC<SettingsDialogProps> = ({ showSettings, setShowSe
       }
     }
   };
+  const [exportSettings, setExportSettings] = useState<main.Settings | null>(null);
+  useEffect(() => {
+    if (showSettings) GetSettings().then(setExportSettings);
+  }, [showSettings]);
+  const saveExportSettings = (changes: Partial<main.Settings>) => {
+    if (!exportSettings) return;
+    UpdateSettings(main.Settings.createFrom({ ...exportSettings, ...changes }))
+      .then(setExportSettings)
+      .catch((err) => toast.error(`Invalid export settings: ${errorMessage(err)}`));
+  };
+  const handleSelectExportDir = () => {
+    SelectExportDir().then((dir) => {
+      if (dir) saveExportSettings({ export_dir: dir });
+    });
+  };
+  const [testRecipient, setTestRecipient] = useState("");
+  const handleTestEmail = () => {
+    SendTestEmail(testRecipient)
+      .then(() => toast.success(`Test email sent to ${testRecipient}`))
+      .catch((err) => toast.error(`Failed to send test email: ${errorMessage(err)}`));
+  };
+  const handleExport = () => {
+    ExportSettings()
+      .then((filePath) => {
+        if (filePath) toast.success(`Settings exported to ${filePath}`);
+      })
+      .catch((err) => toast.error(`Failed to export settings: ${errorMessage(err)}`));
+  };
+  const handleImport = () => {
+    ImportSettings()
+      .then(() => toast.success("Settings imported"))
+      .catch((err) => toast.error(`Failed to import settings: ${errorMessage(err)}`));
+  };
   const handleClose = () => {
     setShowSettings(false);
     checkForAlertTimeChange();
@@ -95,6 +144,176 @@ const SettingsDialog: React.FC<SettingsDialogProps> = ({ showSettings, setShowSe
             labelPlacement="start"
           />
         </FormControl>
+
+        {exportSettings && (
+          <FormControl sx={{ mt: 2 }}>
+            <FormControlLabel
+              value="start"
+              control={
+                <Checkbox
+                  checked={exportSettings.allow_parallel_timers}
+                  onChange={(event) => saveExportSettings({ allow_parallel_timers: event.target.checked })}
+                />
+              }
+              label="Allow parallel timers"
+              labelPlacement="start"
+            />
+            <FormHelperText>
+              Overlapping time is counted for every project and listed separately in the monthly exports
+            </FormHelperText>
+          </FormControl>
+        )}
+
+        {exportSettings && (
+          <>
+            <Typography variant="h6" sx={{ mt: 2 }}>
+              Exports
+            </Typography>
+            <Stack direction="row" spacing={1} alignItems="center" sx={{ mt: 1 }}>
+              <TextField
+                fullWidth
+                size="small"
+                label="Export directory"
+                value={exportSettings.export_dir}
+                placeholder="Default save directory"
+                InputProps={{ readOnly: true }}
+              />
+              <Button onClick={handleSelectExportDir}>Browse</Button>
+              <Button onClick={() => saveExportSettings({ export_dir: "" })}>Reset</Button>
+            </Stack>
+            <TextField
+              fullWidth
+              size="small"
+              sx={{ mt: 2 }}
+              label="Monthly filename template"
+              defaultValue={exportSettings.monthly_export_template}
+              onBlur={(event) => saveExportSettings({ monthly_export_template: event.target.value })}
+            />
+            <TextField
+              fullWidth
+              size="small"
+              sx={{ mt: 2 }}
+              label="Yearly filename template"
+              defaultValue={exportSettings.yearly_export_template}
+              onBlur={(event) => saveExportSettings({ yearly_export_template: event.target.value })}
+            />
+            <FormHelperText>
+              Placeholders: {"{type}"} {"{org}"} {"{project}"} {"{year}"} {"{month}"} {"{period}"} {"{timestamp}"}.
+              {" {project}"} is "all" when the export covers several projects, {"{month}"} only fits monthly templates.
+            </FormHelperText>
+            <FormControl fullWidth size="small" sx={{ mt: 2 }}>
+              <InputLabel id="export-conflict-select">When the file already exists</InputLabel>
+              <Select
+                labelId="export-conflict-select"
+                label="When the file already exists"
+                value={exportSettings.export_conflict}
+                onChange={(event) => saveExportSettings({ export_conflict: event.target.value })}
+              >
+                <MenuItem value="overwrite">Overwrite it</MenuItem>
+                <MenuItem value="version">Save a new version</MenuItem>
+              </Select>
+            </FormControl>
+            <FormControl sx={{ mt: 1 }}>
+              <FormControlLabel
+                value="start"
+                control={
+                  <Checkbox
+                    checked={exportSettings.export_use_save_dialog}
+                    onChange={(event) => saveExportSettings({ export_use_save_dialog: event.target.checked })}
+                  />
+                }
+                label="Ask where to save each export"
+                labelPlacement="start"
+              />
+            </FormControl>
+
+            <Typography variant="h6" sx={{ mt: 2 }}>
+              Email
+            </Typography>
+            <Stack direction="row" spacing={1} sx={{ mt: 1 }}>
+              <TextField
+                fullWidth
+                size="small"
+                label="SMTP server"
+                placeholder="Leave empty to disable email"
+                defaultValue={exportSettings.smtp_host}
+                onBlur={(event) => saveExportSettings({ smtp_host: event.target.value.trim() })}
+              />
+              <TextField
+                size="small"
+                type="number"
+                label="Port"
+                sx={{ width: 120 }}
+                defaultValue={exportSettings.smtp_port}
+                onBlur={(event) => saveExportSettings({ smtp_port: Number(event.target.value) })}
+              />
+            </Stack>
+            <FormControl fullWidth size="small" sx={{ mt: 2 }}>
+              <InputLabel id="smtp-security-select">Security</InputLabel>
+              <Select
+                labelId="smtp-security-select"
+                label="Security"
+                value={exportSettings.smtp_security}
+                onChange={(event) => saveExportSettings({ smtp_security: event.target.value })}
+              >
+                <MenuItem value="starttls">STARTTLS</MenuItem>
+                <MenuItem value="tls">TLS</MenuItem>
+                <MenuItem value="none">None</MenuItem>
+              </Select>
+            </FormControl>
+            <Stack direction="row" spacing={1} sx={{ mt: 2 }}>
+              <TextField
+                fullWidth
+                size="small"
+                label="Username"
+                defaultValue={exportSettings.smtp_username}
+                onBlur={(event) => saveExportSettings({ smtp_username: event.target.value })}
+              />
+              <TextField
+                fullWidth
+                size="small"
+                type="password"
+                label="Password"
+                helperText={
+                  exportSettings.smtp_password_set
+                    ? "Leave empty to keep the saved one"
+                    : "Only stored in an encrypted database (Database Encryption in the menu)"
+                }
+                defaultValue={exportSettings.smtp_password}
+                onBlur={(event) => saveExportSettings({ smtp_password: event.target.value })}
+              />
+            </Stack>
+            <TextField
+              fullWidth
+              size="small"
+              sx={{ mt: 2 }}
+              label="From address"
+              defaultValue={exportSettings.smtp_from}
+              onBlur={(event) => saveExportSettings({ smtp_from: event.target.value.trim() })}
+            />
+            <Stack direction="row" spacing={1} alignItems="center" sx={{ mt: 2 }}>
+              <TextField
+                fullWidth
+                size="small"
+                label="Send a test email to"
+                value={testRecipient}
+                onChange={(event) => setTestRecipient(event.target.value)}
+              />
+              <Button onClick={handleTestEmail} disabled={!exportSettings.smtp_host || !testRecipient}>
+                Send
+              </Button>
+            </Stack>
+          </>
+        )}
+
+        <Stack direction="row" spacing={2} sx={{ mt: 2 }}>
+          <Button variant="outlined" onClick={handleImport}>
+            Import settings
+          </Button>
+          <Button variant="outlined" onClick={handleExport}>
+            Export settings
+          </Button>
+        </Stack>
       </DialogContent>
     </Dialog>
   );
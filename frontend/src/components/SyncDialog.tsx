@@ -0,0 +1,114 @@
+import { errorMessage } from "@/utils/utils";
+import { GetSyncConfig, SaveSyncConfig, SelectSyncFolder, SyncNow } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  List,
+  ListItem,
+  ListItemText,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+interface SyncDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const SyncDialog: React.FC<SyncDialogProps> = ({ open, setOpen }) => {
+  const [config, setConfig] = useState<main.SyncConfig>();
+  const [report, setReport] = useState<main.SyncReport>();
+  const [syncing, setSyncing] = useState(false);
+
+  useEffect(() => {
+    if (!open) return;
+    GetSyncConfig()
+      .then(setConfig)
+      .catch((err) => toast.error(`Failed to load the sync setup: ${errorMessage(err)}`));
+  }, [open]);
+
+  const save = (folder: string, intervalMinutes: number) => {
+    SaveSyncConfig(folder, intervalMinutes)
+      .then(setConfig)
+      .catch((err) => toast.error(`Failed to save the sync setup: ${errorMessage(err)}`));
+  };
+
+  const handleSelectFolder = () => {
+    if (!config) return;
+    SelectSyncFolder().then((folder) => {
+      if (folder) save(folder, config.interval_minutes);
+    });
+  };
+
+  const handleSync = () => {
+    setSyncing(true);
+    SyncNow()
+      .then((result) => {
+        setReport(result);
+        toast.success(`Synced: ${result.exported} sent, ${result.applied} received`);
+        return GetSyncConfig().then(setConfig);
+      })
+      .catch((err) => toast.error(`Sync failed: ${errorMessage(err)}`))
+      .finally(() => setSyncing(false));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Sync Between Devices</DialogTitle>
+      <DialogContent>
+        {config && (
+          <Stack spacing={2} sx={{ mt: 1 }}>
+            <Typography variant="body2" color="text.secondary">
+              Pick a folder that a tool like Syncthing or Dropbox keeps in sync on all your devices. Each device writes
+              its changes to its own file in the folder and reads the others.
+            </Typography>
+            <Stack direction="row" spacing={1}>
+              <TextField fullWidth size="small" label="Sync folder" value={config.folder} InputProps={{ readOnly: true }} />
+              <Button onClick={handleSelectFolder}>Browse</Button>
+              {config.folder && <Button onClick={() => save("", config.interval_minutes)}>Turn off</Button>}
+            </Stack>
+            <TextField
+              type="number"
+              size="small"
+              label="Sync every (minutes)"
+              defaultValue={config.interval_minutes}
+              inputProps={{ min: 1, max: 1440 }}
+              onBlur={(e) => save(config.folder, Number(e.target.value))}
+              sx={{ width: 200 }}
+            />
+            <Typography variant="body2" color="text.secondary">
+              This device: {config.device_id}
+              <br />
+              Last sync: {config.last_sync_at ? new Date(config.last_sync_at).toLocaleString() : "never"}
+            </Typography>
+            {config.last_error && <Typography color="error">{config.last_error}</Typography>}
+            {report && report.skipped?.length > 0 && (
+              <List dense>
+                {report.skipped.map((reason, index) => (
+                  <ListItem key={index}>
+                    <ListItemText primary={reason} />
+                  </ListItem>
+                ))}
+              </List>
+            )}
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={handleSync} disabled={!config?.folder || syncing}>
+          Sync now
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default SyncDialog;
@@ -0,0 +1,194 @@
+import { useAppStore } from "@/stores/main";
+import { errorMessage } from "@/utils/utils";
+import { DeleteTask, GetActiveTimer, GetTaskTimes, GetTasks, NewTask, SetTask, UpdateTask } from "@go/main/App";
+import { main } from "@go/models";
+import DeleteIcon from "@mui/icons-material/Delete";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  IconButton,
+  MenuItem,
+  Stack,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableRow,
+  TextField,
+  Tooltip,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+const statuses = [
+  { value: "todo", label: "To do" },
+  { value: "in_progress", label: "In progress" },
+  { value: "done", label: "Done" },
+];
+
+const formatHours = (seconds: number) => (seconds / 3600).toFixed(2);
+
+interface TasksDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const TasksDialog: React.FC<TasksDialogProps> = ({ open, setOpen }) => {
+  const activeProj = useAppStore((state) => state.activeProj);
+  const [tasks, setTasks] = useState<main.Task[]>([]);
+  const [times, setTimes] = useState<Record<number, main.TaskTime>>({});
+  const [trackedTaskID, setTrackedTaskID] = useState(0);
+  const [timerRunning, setTimerRunning] = useState(false);
+  const [name, setName] = useState("");
+  const [parentID, setParentID] = useState(0);
+  const [estimateHours, setEstimateHours] = useState("");
+
+  const loadTasks = async () => {
+    if (!activeProj) return;
+    const [projectTasks, taskTimes, timer] = await Promise.all([
+      GetTasks(activeProj.id),
+      // All time tracked on the tasks so far
+      GetTaskTimes(activeProj.id, "0000-01-01", "9999-12-31"),
+      GetActiveTimer(),
+    ]);
+    setTasks(projectTasks);
+    setTimes(Object.fromEntries(taskTimes.map((time) => [time.task_id, time])));
+    setTrackedTaskID(timer.task?.id ?? 0);
+    setTimerRunning(timer.isRunning);
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    loadTasks().catch((err) => toast.error(`Failed to load tasks: ${errorMessage(err)}`));
+  }, [open, activeProj]);
+
+  const handleAdd = () => {
+    if (!activeProj) return;
+    NewTask(activeProj.id, parentID, name, Math.round(Number(estimateHours || 0) * 3600))
+      .then(() => {
+        setName("");
+        setEstimateHours("");
+        return loadTasks();
+      })
+      .catch((err) => toast.error(`Failed to add task: ${errorMessage(err)}`));
+  };
+
+  const handleUpdate = (task: main.Task, changes: Partial<main.Task>) => {
+    UpdateTask(main.Task.createFrom({ ...task, ...changes }))
+      .then(loadTasks)
+      .catch((err) => toast.error(`Failed to update ${task.name}: ${errorMessage(err)}`));
+  };
+
+  const handleDelete = (task: main.Task) => {
+    DeleteTask(task.id)
+      .then(loadTasks)
+      .catch((err) => toast.error(`Failed to delete ${task.name}: ${errorMessage(err)}`));
+  };
+
+  const handleTrack = (taskID: number) => {
+    SetTask(taskID)
+      .then(() => setTrackedTaskID(taskID))
+      .catch((err) => toast.error(`Failed to select task: ${errorMessage(err)}`));
+  };
+
+  const sortedTasks = [...tasks].sort((a, b) => (times[a.id]?.path ?? a.name).localeCompare(times[b.id]?.path ?? b.name));
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="md">
+      <DialogTitle>Tasks of {activeProj?.name}</DialogTitle>
+      <DialogContent>
+        <Stack direction="row" spacing={2} sx={{ mt: 1, mb: 2 }}>
+          <TextField label="Task" size="small" value={name} onChange={(e) => setName(e.target.value)} />
+          <TextField
+            select
+            label="Parent"
+            size="small"
+            value={parentID}
+            onChange={(e) => setParentID(Number(e.target.value))}
+            sx={{ minWidth: 160 }}
+          >
+            <MenuItem value={0}>None</MenuItem>
+            {sortedTasks.map((task) => (
+              <MenuItem key={task.id} value={task.id}>
+                {times[task.id]?.path ?? task.name}
+              </MenuItem>
+            ))}
+          </TextField>
+          <TextField
+            label="Estimate (hours)"
+            size="small"
+            type="number"
+            value={estimateHours}
+            onChange={(e) => setEstimateHours(e.target.value)}
+          />
+          <Button variant="contained" onClick={handleAdd} disabled={!name.trim()}>
+            Add
+          </Button>
+        </Stack>
+
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Task</TableCell>
+              <TableCell>Status</TableCell>
+              <TableCell>Tracked (h)</TableCell>
+              <TableCell>Estimate (h)</TableCell>
+              <TableCell />
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {sortedTasks.map((task) => (
+              <TableRow key={task.id} selected={task.id === trackedTaskID}>
+                <TableCell>{times[task.id]?.path ?? task.name}</TableCell>
+                <TableCell>
+                  <TextField
+                    select
+                    size="small"
+                    variant="standard"
+                    value={task.status}
+                    onChange={(e) => handleUpdate(task, { status: e.target.value })}
+                  >
+                    {statuses.map((status) => (
+                      <MenuItem key={status.value} value={status.value}>
+                        {status.label}
+                      </MenuItem>
+                    ))}
+                  </TextField>
+                </TableCell>
+                <TableCell>{formatHours(times[task.id]?.total_seconds ?? 0)}</TableCell>
+                <TableCell>
+                  {times[task.id]?.estimate_seconds ? formatHours(times[task.id].estimate_seconds) : "-"}
+                </TableCell>
+                <TableCell align="right">
+                  {/* Switching tasks would stop the running timer */}
+                  {task.id === trackedTaskID ? (
+                    <Button size="small" disabled={timerRunning} onClick={() => handleTrack(0)}>
+                      Untrack
+                    </Button>
+                  ) : (
+                    <Button size="small" disabled={timerRunning} onClick={() => handleTrack(task.id)}>
+                      Track
+                    </Button>
+                  )}
+                  <Tooltip title="Delete task and its subtasks">
+                    <IconButton size="small" onClick={() => handleDelete(task)}>
+                      <DeleteIcon fontSize="small" />
+                    </IconButton>
+                  </Tooltip>
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default TasksDialog;
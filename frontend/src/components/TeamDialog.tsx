@@ -0,0 +1,107 @@
+import { errorMessage } from "@/utils/utils";
+import { GetTeamConfig, PushNow, SaveTeamConfig } from "@go/main/App";
+import { main } from "@go/models";
+import { Button, Dialog, DialogActions, DialogContent, DialogTitle, Stack, TextField, Typography } from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+interface TeamDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const TeamDialog: React.FC<TeamDialogProps> = ({ open, setOpen }) => {
+  const [config, setConfig] = useState<main.TeamConfig>();
+  const [serverURL, setServerURL] = useState("");
+  const [token, setToken] = useState("");
+  const [pushing, setPushing] = useState(false);
+
+  const load = (loaded: main.TeamConfig) => {
+    setConfig(loaded);
+    setServerURL(loaded.server_url);
+    setToken("");
+  };
+
+  useEffect(() => {
+    if (!open) return;
+    GetTeamConfig()
+      .then(load)
+      .catch((err) => toast.error(`Failed to load the team server setup: ${errorMessage(err)}`));
+  }, [open]);
+
+  const handleSave = (url: string, apiToken: string) => {
+    SaveTeamConfig(url, apiToken)
+      .then((saved) => {
+        load(saved);
+        toast.success(url ? "Team server saved" : "Pushing to the team server turned off");
+      })
+      .catch((err) => toast.error(`Failed to save the team server: ${errorMessage(err)}`));
+  };
+
+  const handlePush = () => {
+    setPushing(true);
+    PushNow()
+      .then((result) => {
+        load(result);
+        toast.success("Pushed to the team server");
+      })
+      .catch((err) => {
+        toast.error(`Push failed: ${errorMessage(err)}`);
+        return GetTeamConfig().then(load);
+      })
+      .finally(() => setPushing(false));
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Team Server</DialogTitle>
+      <DialogContent>
+        {config && (
+          <Stack spacing={2} sx={{ mt: 1 }}>
+            <Typography variant="body2" color="text.secondary">
+              Push your organizations, projects and sessions to your team's server so your lead can see the team's
+              hours. Changes are queued while the server can't be reached and pushed once it is back.
+            </Typography>
+            <TextField
+              size="small"
+              label="Server URL"
+              placeholder="https://tracker.example.com"
+              value={serverURL}
+              onChange={(e) => setServerURL(e.target.value)}
+            />
+            <TextField
+              size="small"
+              type="password"
+              label="API token"
+              helperText={
+                config.token_set
+                  ? "Leave empty to keep the saved one"
+                  : "Only stored in an encrypted database (Database Encryption in the menu)"
+              }
+              value={token}
+              onChange={(e) => setToken(e.target.value)}
+            />
+            <Typography variant="body2" color="text.secondary">
+              Waiting to be pushed: {config.pending}
+              <br />
+              Last push: {config.last_push_at ? new Date(config.last_push_at).toLocaleString() : "never"}
+            </Typography>
+            {config.last_error && <Typography color="error">{config.last_error}</Typography>}
+          </Stack>
+        )}
+      </DialogContent>
+      <DialogActions>
+        {config?.server_url && <Button onClick={() => handleSave("", "")}>Turn off</Button>}
+        <Button onClick={() => handleSave(serverURL, token)} disabled={!config}>
+          Save
+        </Button>
+        <Button onClick={handlePush} disabled={!config?.server_url || pushing}>
+          Push now
+        </Button>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default TeamDialog;
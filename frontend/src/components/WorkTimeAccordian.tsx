@@ -40,7 +40,7 @@ import { AdapterDayjs } from "@mui/x-date-pickers/AdapterDayjs";
 import type { Dayjs } from "dayjs";
 import dayjs from "dayjs";
 import { useTimerStore } from "../stores/timer";
-import { formatTime, getMonth, months } from "../utils/utils";
+import { errorMessage, formatTime, getMonth, months } from "../utils/utils";
 
 enum ExportType {
   CSV = "csv",
@@ -140,6 +140,8 @@ const WorkTimeAccordion: React.FC<WorkTimeAccordionProps> = ({ activeOrganizatio
   const exportYearly = (type: ExportType) => {
     ExportByYear(type, activeOrganization.name, selectedYear)
       .then((path) => {
+        // Empty path means the save dialog was cancelled
+        if (!path) return;
         toast.success(
           <div>
             <strong>Yearly {type.toUpperCase()} export complete!</strong> <br />
@@ -152,7 +154,7 @@ const WorkTimeAccordion: React.FC<WorkTimeAccordionProps> = ({ activeOrganizatio
         toast.error(
           <div>
             <strong>Yearly {type.toUpperCase()} export failed!</strong> <br />
-            {err}
+            {errorMessage(err)}
           </div>,
         );
       });
@@ -161,6 +163,8 @@ const WorkTimeAccordion: React.FC<WorkTimeAccordionProps> = ({ activeOrganizatio
   const exportMonthly = (type: ExportType, month: number) => {
     ExportByMonth(type, activeOrganization.name, selectedYear, month)
       .then((path) => {
+        // Empty path means the save dialog was cancelled
+        if (!path) return;
         toast.success(
           <div>
             <strong>Monthly {type.toUpperCase()} export complete!</strong> <br />
@@ -173,7 +177,7 @@ const WorkTimeAccordion: React.FC<WorkTimeAccordionProps> = ({ activeOrganizatio
         toast.error(
           <div>
             <strong>Monthly {type.toUpperCase()} export failed!</strong> <br />
-            {err}
+            {errorMessage(err)}
           </div>,
         );
       });
@@ -0,0 +1,119 @@
+import { errorMessage } from "@/utils/utils";
+import { ConfirmAction, CreateWorkspace, DeleteWorkspace, GetWorkspaces, SwitchWorkspace } from "@go/main/App";
+import { main } from "@go/models";
+import {
+  Button,
+  Dialog,
+  DialogActions,
+  DialogContent,
+  DialogTitle,
+  List,
+  ListItem,
+  ListItemText,
+  Stack,
+  TextField,
+  Typography,
+} from "@mui/material";
+import React, { useEffect, useState } from "react";
+import { toast } from "react-toastify";
+
+const defaultWorkspace = "default";
+
+interface WorkspacesDialogProps {
+  open: boolean;
+  setOpen: (value: boolean) => void;
+}
+
+const WorkspacesDialog: React.FC<WorkspacesDialogProps> = ({ open, setOpen }) => {
+  const [workspaces, setWorkspaces] = useState<main.Workspace[]>([]);
+  const [name, setName] = useState("");
+
+  const load = () => {
+    GetWorkspaces()
+      .then(setWorkspaces)
+      .catch((err) => toast.error(`Failed to load workspaces: ${errorMessage(err)}`));
+  };
+
+  useEffect(() => {
+    if (open) load();
+  }, [open]);
+
+  const handleCreate = () => {
+    CreateWorkspace(name.trim())
+      .then(() => {
+        setName("");
+        load();
+      })
+      .catch((err) => toast.error(`Failed to create the workspace: ${errorMessage(err)}`));
+  };
+
+  const handleSwitch = (workspace: main.Workspace) => {
+    SwitchWorkspace(workspace.name)
+      // Reload so every view shows the data of the new workspace, an encrypted one asks for its passphrase
+      .then(() => window.location.reload())
+      .catch((err) => toast.error(`Failed to switch workspaces: ${errorMessage(err)}`));
+  };
+
+  const handleDelete = (workspace: main.Workspace) => {
+    ConfirmAction(
+      `Delete ${workspace.name}`,
+      "Are you sure you want to delete this workspace? All its data will be deleted.",
+    ).then((confirmed) => {
+      if (!confirmed) return;
+      DeleteWorkspace(workspace.name)
+        .then(load)
+        .catch((err) => toast.error(`Failed to delete the workspace: ${errorMessage(err)}`));
+    });
+  };
+
+  return (
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth="sm">
+      <DialogTitle>Workspaces</DialogTitle>
+      <DialogContent>
+        <Stack spacing={2} sx={{ mt: 1 }}>
+          <Typography variant="body2" color="text.secondary">
+            Each workspace has its own organizations, settings and exports. Running timers are stopped when you switch.
+          </Typography>
+          <List dense>
+            {workspaces.map((workspace) => (
+              <ListItem
+                key={workspace.name}
+                secondaryAction={
+                  workspace.active ? (
+                    <Typography variant="body2" color="text.secondary">
+                      Active
+                    </Typography>
+                  ) : (
+                    <>
+                      <Button size="small" onClick={() => handleSwitch(workspace)}>
+                        Switch
+                      </Button>
+                      {workspace.name !== defaultWorkspace && (
+                        <Button size="small" color="error" onClick={() => handleDelete(workspace)}>
+                          Delete
+                        </Button>
+                      )}
+                    </>
+                  )
+                }
+              >
+                <ListItemText primary={workspace.name} secondary={workspace.dir} />
+              </ListItem>
+            ))}
+          </List>
+          <Stack direction="row" spacing={1}>
+            <TextField size="small" label="New workspace" value={name} onChange={(e) => setName(e.target.value)} />
+            <Button variant="contained" onClick={handleCreate} disabled={!name.trim()}>
+              Create
+            </Button>
+          </Stack>
+        </Stack>
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>Close</Button>
+      </DialogActions>
+    </Dialog>
+  );
+};
+
+export default WorkspacesDialog;
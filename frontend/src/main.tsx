@@ -11,6 +11,7 @@ import { createHashRouter, RouterProvider } from "react-router-dom";
 import { ToastContainer } from "react-toastify";
 import "react-toastify/dist/ReactToastify.css";
 import ActiveConfirmationDialog from "./components/ActiveConfirmationDialog";
+import AppLock from "./components/AppLock";
 import AppFooter from "./components/AppFooter";
 import App from "./routes/App";
 import Charts from "./routes/Charts";
@@ -165,10 +166,13 @@ const AppWithTheme = () => {
       <CssBaseline />
       {/* Notifcation container */}
       <ToastContainer />
-      {/* Handle confirming user still active */}
-      <ActiveConfirmationDialog />
-      <RouterProvider router={router} />
-      <AppFooter />
+      {/* Ask for the passphrase of an encrypted database first */}
+      <AppLock>
+        {/* Handle confirming user still active */}
+        <ActiveConfirmationDialog />
+        <RouterProvider router={router} />
+        <AppFooter />
+      </AppLock>
     </ThemeProvider>
   );
 };
@@ -1,9 +1,23 @@
 import { useEffect, useState } from "react";
 
+import AbsencesDialog from "@/components/AbsencesDialog";
+import ArchivedDialog from "@/components/ArchivedDialog";
+import ClientsDialog from "@/components/ClientsDialog";
+import ComplianceDialog from "@/components/ComplianceDialog";
+import PeriodLocksDialog from "@/components/PeriodLocksDialog";
+import SyncDialog from "@/components/SyncDialog";
+import TeamDialog from "@/components/TeamDialog";
+import EncryptionDialog from "@/components/EncryptionDialog";
+import WorkspacesDialog from "@/components/WorkspacesDialog";
+import BackupsDialog from "@/components/BackupsDialog";
 import EditOrganizationDialog from "@/components/EditOrganizationDialog";
 import NewOrganizationDialog from "@/components/NewOrganizationDialog";
 import NewProjectDialog from "@/components/NewProjectDialog";
+import ParallelTimersDialog from "@/components/ParallelTimersDialog";
+import RoundingDialog from "@/components/RoundingDialog";
+import ScheduleDialog from "@/components/ScheduleDialog";
 import SettingsDialog from "@/components/SettingsDialog";
+import TasksDialog from "@/components/TasksDialog";
 import { toast } from "react-toastify";
 
 import ActiveSession from "@/components/ActiveSession";
@@ -15,8 +29,10 @@ import AppBar from "@/components/ui/AppBar";
 import WorkTimeListing from "@/components/WorkTimeListing";
 import { useAppStore } from "@/stores/main";
 import { useTimerStore } from "@/stores/timer";
-import { dateString, getCurrentWeekOfMonth, getMonth, handleSort, months } from "@/utils/utils";
+import { AppError, ArchiveFilter, dateString, errorMessage, getCurrentWeekOfMonth, getMonth, handleSort, months } from "@/utils/utils";
 import {
+  ArchiveOrganization,
+  ArchiveProject,
   CheckForUpdates,
   ConfirmAction,
   DeleteOrganization,
@@ -30,9 +46,11 @@ import {
   GetProjWorkTimeByWeek,
   GetWorkTime,
   GetWorkTimeByProject,
+  ReturnToPreviousTimer,
   SetOrganization,
   SetProject,
   StopTimer,
+  SwitchTimer,
   ToggleFavoriteOrganization,
   ToggleFavoriteProject,
 } from "@go/main/App";
@@ -106,6 +124,20 @@ function App() {
   const [openEditOrg, setOpenEditOrg] = useState(false);
   const [openEditProj, setOpenEditProj] = useState(false);
   const [openRangeView, setOpenRangeView] = useState(false);
+  const [openArchived, setOpenArchived] = useState(false);
+  const [openTasks, setOpenTasks] = useState(false);
+  const [openClients, setOpenClients] = useState(false);
+  const [openParallelTimers, setOpenParallelTimers] = useState(false);
+  const [openRounding, setOpenRounding] = useState(false);
+  const [openSchedule, setOpenSchedule] = useState(false);
+  const [openAbsences, setOpenAbsences] = useState(false);
+  const [openCompliance, setOpenCompliance] = useState(false);
+  const [openPeriodLocks, setOpenPeriodLocks] = useState(false);
+  const [openSync, setOpenSync] = useState(false);
+  const [openTeam, setOpenTeam] = useState(false);
+  const [openEncryption, setOpenEncryption] = useState(false);
+  const [openWorkspaces, setOpenWorkspaces] = useState(false);
+  const [openBackups, setOpenBackups] = useState(false);
   const [anchorEl, setAnchorEl] = useState<null | HTMLElement>(null);
 
   // Editables
@@ -143,7 +175,7 @@ function App() {
     if (timerRunning) {
       await stopTimer();
     }
-    const projs = await GetProjects(org.id);
+    const projs = await GetProjects(org.id, ArchiveFilter.Active);
     projs.sort(handleSort);
     const project = projs[0];
     await SetOrganization(org.id);
@@ -155,13 +187,20 @@ function App() {
   const setProject = async (project: main.Project) => {
     if (!project) return;
     if (timerRunning) {
-      await stopTimer();
+      // Keep the timer running on the new project, the previous one can be returned to
+      SwitchTimer(project.id, 0).catch((err) => toast.error(`Failed to switch to ${project.name}: ${errorMessage(err)}`));
+      return;
     }
     SetProject(project.id).then(() => {
       setSelectedProject(project);
     });
   };
 
+  const handleReturnToPrevious = () => {
+    handleMenuClose();
+    ReturnToPreviousTimer().catch((err) => toast.error(errorMessage(err)));
+  };
+
   const handleOpenSettings = () => {
     setAnchorEl(null);
     setShowSettings(true);
@@ -240,7 +279,7 @@ function App() {
             const newSelectedOrganization = organizations.sort(handleSort)[0];
             setSelectedOrganization(newSelectedOrganization);
 
-            GetProjects(newSelectedOrganization.id).then(async (projs) => {
+            GetProjects(newSelectedOrganization.id, ArchiveFilter.Active).then(async (projs) => {
               setProjects(projs);
               const newSelectedProject = projs.sort(handleSort)[0];
               setSelectedProject(newSelectedProject);
@@ -283,6 +322,62 @@ function App() {
     });
   };
 
+  const handleArchiveOrganization = (organizationID?: number) => {
+    const organization = organizations.find((org) => org.id === organizationID);
+    if (!organization) return;
+    handleMenuClose();
+    if (organizations.length === 1) {
+      toast.error("You cannot archive the last organization");
+      return;
+    }
+    ArchiveOrganization(organization.id)
+      .then(() => {
+        const newOrgs = organizations.filter((org) => org.id !== organization.id);
+        setOrganizations(newOrgs);
+
+        if (organization.id === activeOrg?.id) {
+          const newSelectedOrganization = newOrgs.sort(handleSort)[0];
+          setSelectedOrganization(newSelectedOrganization);
+
+          GetProjects(newSelectedOrganization.id, ArchiveFilter.Active).then(async (projs) => {
+            setProjects(projs);
+            const newSelectedProject = projs.sort(handleSort)[0];
+            setSelectedProject(newSelectedProject);
+            await SetOrganization(newSelectedOrganization.id);
+            await SetProject(newSelectedProject.id);
+          });
+        }
+        toast.success(`${organization.name} archived`);
+      })
+      .catch((err) => toast.error(`Failed to archive ${organization.name}: ${errorMessage(err)}`));
+  };
+
+  const handleArchiveProject = (projectID?: number) => {
+    const project = projects.find((proj) => proj.id === projectID);
+    if (!project) return;
+    handleMenuClose();
+    if (projects.length === 1) {
+      toast.error("You cannot archive the last project");
+      return;
+    }
+    ArchiveProject(project.id)
+      .then(() => {
+        const newProjs = projects.filter((proj) => proj.id !== project.id);
+        setProjects(newProjs);
+
+        if (project.id === activeProj?.id) {
+          const newSelectedProject = newProjs.sort(handleSort)[0];
+          setSelectedProject(newSelectedProject);
+
+          SetProject(newSelectedProject.id).then(() => {
+            GetWorkTimeByProject(newSelectedProject.id, dateString()).then(setProjDayTotal);
+          });
+        }
+        toast.success(`${project.name} archived`);
+      })
+      .catch((err) => toast.error(`Failed to archive ${project.name}: ${errorMessage(err)}`));
+  };
+
   /**
    * on page load
    * Get organizations defined in database when the app loads
@@ -291,7 +386,7 @@ function App() {
   useEffect(() => {
     setShowMiniTimer(false);
     getCurrentWeekOfMonth().then(setCurrentWeek);
-    GetOrganizations().then(async (orgs) => {
+    GetOrganizations(ArchiveFilter.Active).then(async (orgs) => {
       if (orgs.length === 0) {
         setOpenNewOrg(true);
       } else {
@@ -308,7 +403,7 @@ function App() {
             }
           }
 
-          GetProjects(active.organization.id).then((projs) => {
+          GetProjects(active.organization.id, ArchiveFilter.Active).then((projs) => {
             projs.sort(handleSort);
             setProjects(projs);
           });
@@ -324,7 +419,7 @@ function App() {
           if (localOrgId && localProjId) {
             const org = orgs.find((org) => org.id === localOrgId);
             if (org) {
-              const proj = await GetProjects(org.id);
+              const proj = await GetProjects(org.id, ArchiveFilter.Active);
               proj.sort(handleSort);
               const project = proj.find((p) => p.id === localProjId);
               if (project) {
@@ -341,7 +436,7 @@ function App() {
         }
         console.debug("No active timer found");
         const organization = orgs[0];
-        const projs = await GetProjects(organization.id);
+        const projs = await GetProjects(organization.id, ArchiveFilter.Active);
         projs.sort(handleSort);
         const project = projs[0];
         await SetOrganization(organization.id);
@@ -356,6 +451,27 @@ function App() {
       setDateStr(dateString());
     });
 
+    const timerErrorEvent = EventsOn("timer-error", (err: AppError) => {
+      toast.error(`Failed to save tracked time: ${err.message}`);
+    });
+
+    const complianceWarningEvent = EventsOn("compliance-warning", (violation: main.ComplianceViolation) => {
+      toast.warning(`Working-time rule: ${violation.message}`);
+    });
+
+    const syncAppliedEvent = EventsOn("sync-applied", (report: main.SyncReport) => {
+      toast.info(`${report.applied} change(s) received from your other devices`);
+    });
+
+    const timerSwitchedEvent = EventsOn("timer-switched", (active: main.ActiveTimer) => {
+      if (active.organization.id !== useAppStore.getState().activeOrg?.id) {
+        GetProjects(active.organization.id, ArchiveFilter.Active).then((projs) => setProjects(projs.sort(handleSort)));
+      }
+      setActiveInfo(active.organization, active.project);
+      useTimerStore.getState().setRunning(active.isRunning);
+      useTimerStore.getState().setElapsedTime(active.timeElapsed);
+    });
+
     const daySubscription = useAppStore.subscribe(
       (state) => state.dateStr,
       (curr, prev) => {
@@ -382,6 +498,10 @@ function App() {
       setShowMiniTimer(true);
       daySubscription(); // cleanup
       newDayEvent(); // cleanup
+      timerErrorEvent(); // cleanup
+      timerSwitchedEvent(); // cleanup
+      complianceWarningEvent(); // cleanup
+      syncAppliedEvent(); // cleanup
       // renderCount.current = 0;
     };
   }, []);
@@ -405,7 +525,7 @@ function App() {
    */
   useEffect(() => {
     if (!activeOrg) return;
-    GetProjects(activeOrg.id).then(async (projs) => {
+    GetProjects(activeOrg.id, ArchiveFilter.Active).then(async (projs) => {
       setProjects(projs);
       projs.sort(handleSort);
       const proj = projs[0];
@@ -493,6 +613,124 @@ function App() {
             <MenuItem onClick={handleOpenRangeView}>Open Range View</MenuItem>
             <MenuItem onClick={() => handleOpenEditOrg(activeOrg?.id)}>Edit Current Organization</MenuItem>
             <MenuItem onClick={() => handleDeleteOrganization(activeOrg?.id)}>Delete Current Organization</MenuItem>
+            <Divider />
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenTasks(true);
+              }}
+            >
+              Manage Tasks
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenClients(true);
+              }}
+            >
+              Manage Clients
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenRounding(true);
+              }}
+            >
+              Billing Rounding
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenSchedule(true);
+              }}
+            >
+              Work Schedule and Overtime
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenAbsences(true);
+              }}
+            >
+              Absences and Leave
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenCompliance(true);
+              }}
+            >
+              Working-Time Rules
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenPeriodLocks(true);
+              }}
+            >
+              Timesheet Locks
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenSync(true);
+              }}
+            >
+              Sync Between Devices
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenTeam(true);
+              }}
+            >
+              Team Server
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenEncryption(true);
+              }}
+            >
+              Database Encryption
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenWorkspaces(true);
+              }}
+            >
+              Workspaces
+            </MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenBackups(true);
+              }}
+            >
+              Remote Backups
+            </MenuItem>
+            <Divider />
+            <MenuItem onClick={handleReturnToPrevious}>Return to Previous Project</MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenParallelTimers(true);
+              }}
+            >
+              Parallel Timers
+            </MenuItem>
+            <Divider />
+            <MenuItem onClick={() => handleArchiveOrganization(activeOrg?.id)}>Archive Current Organization</MenuItem>
+            <MenuItem onClick={() => handleArchiveProject(activeProj?.id)}>Archive Current Project</MenuItem>
+            <MenuItem
+              onClick={() => {
+                handleMenuClose();
+                setOpenArchived(true);
+              }}
+            >
+              Archived Organizations and Projects
+            </MenuItem>
           </Menu>
 
           <NavBar />
@@ -587,6 +825,20 @@ function App() {
       </Box>
 
       {/* Handle settings dialog */}
+      <ArchivedDialog open={openArchived} setOpen={setOpenArchived} />
+      <TasksDialog open={openTasks} setOpen={setOpenTasks} />
+      <ClientsDialog open={openClients} setOpen={setOpenClients} />
+      <ParallelTimersDialog open={openParallelTimers} setOpen={setOpenParallelTimers} />
+      <RoundingDialog open={openRounding} setOpen={setOpenRounding} />
+      <ScheduleDialog open={openSchedule} setOpen={setOpenSchedule} />
+      <AbsencesDialog open={openAbsences} setOpen={setOpenAbsences} />
+      <ComplianceDialog open={openCompliance} setOpen={setOpenCompliance} />
+      <PeriodLocksDialog open={openPeriodLocks} setOpen={setOpenPeriodLocks} />
+      <SyncDialog open={openSync} setOpen={setOpenSync} />
+      <TeamDialog open={openTeam} setOpen={setOpenTeam} />
+      <EncryptionDialog open={openEncryption} setOpen={setOpenEncryption} />
+      <WorkspacesDialog open={openWorkspaces} setOpen={setOpenWorkspaces} />
+      <BackupsDialog open={openBackups} setOpen={setOpenBackups} />
       <SettingsDialog showSettings={showSettings} setShowSettings={setShowSettings} handleMenuClose={handleMenuClose} />
 
       {/* Handle RangeView - hacky way to sum total worktime between two dates without being limited by month or weeks */}
@@ -2,7 +2,7 @@ import NavBar from "@/components/NavBar";
 import AppBar from "@/components/ui/AppBar";
 import { useAppStore } from "@/stores/main";
 import { useTimerStore } from "@/stores/timer";
-import { formatTime, getMonth, months } from "@/utils/utils";
+import { ArchiveFilter, formatTime, getMonth, months } from "@/utils/utils";
 import { GetDailyWorkTimeByMonth, GetProjects } from "@go/main/App";
 import { main } from "@go/models";
 import { FormControl, InputLabel, MenuItem, Paper, Select, Stack, Toolbar } from "@mui/material";
@@ -130,7 +130,7 @@ function Charts() {
 
   useEffect(() => {
     if (!selectedOrganization) return;
-    GetProjects(selectedOrganization.id).then((projs) => {
+    GetProjects(selectedOrganization.id, ArchiveFilter.All).then((projs) => {
       setProjects(projs);
     });
   }, [selectedOrganization]);
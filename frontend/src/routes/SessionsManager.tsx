@@ -47,7 +47,7 @@ function DeleteSessionActionItem({
       >
         <DialogTitle id="alert-dialog-title">Delete this session?</DialogTitle>
         <DialogContent>
-          <DialogContentText id="alert-dialog-description">This action cannot be undone.</DialogContentText>
+          <DialogContentText id="alert-dialog-description">You can revert this with Undo.</DialogContentText>
         </DialogContent>
         <DialogActions>
           <Button onClick={() => setOpen(false)}>Cancel</Button>
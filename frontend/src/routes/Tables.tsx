@@ -1,7 +1,7 @@
 import NavBar from "@/components/NavBar";
 import AppBar from "@/components/ui/AppBar";
 import { useAppStore } from "@/stores/main";
-import { handleSort } from "@/utils/utils";
+import { ArchiveFilter, handleSort } from "@/utils/utils";
 import { GetProjects } from "@go/main/App";
 import { main } from "@go/models";
 import StarIcon from "@mui/icons-material/Star";
@@ -17,7 +17,7 @@ function Tables() {
 
   useEffect(() => {
     if (!activeOrganization) return;
-    GetProjects(activeOrganization.id).then((projs) => {
+    GetProjects(activeOrganization.id, ArchiveFilter.All).then((projs) => {
       setProjects(projs);
     });
   }, [activeOrganization]);
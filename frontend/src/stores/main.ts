@@ -1,9 +1,15 @@
 import { dateString } from "@/utils/utils";
+import { GetSettings, UpdateSettings } from "@go/main/App";
 import { main } from "@go/models";
+import { EventsOn } from "@runtime/runtime";
 import { create } from "zustand";
 import { createJSONStorage, persist, subscribeWithSelector } from "zustand/middleware";
 
 interface Store {
+  settingsLoaded: boolean;
+  loadSettings: () => Promise<void>;
+  applySettings: (settings: main.Settings) => void;
+  saveSettings: (changes: Partial<main.Settings>) => Promise<void>;
   appMode: "normal" | "widget";
   setAppMode: (mode: "normal" | "widget") => void;
   appTheme: "light" | "dark";
@@ -60,26 +66,67 @@ interface Store {
 export const useAppStore = create(
   persist(
     subscribeWithSelector<Store>((set, get) => ({
+      settingsLoaded: false,
+      loadSettings: async () => {
+        let settings = await GetSettings();
+        // Settings used to live in localStorage, move them to the backend once
+        const legacy = ["alertTime", "appTheme", "enableColorOnDark"];
+        if (legacy.some((key) => localStorage.getItem(key) !== null)) {
+          settings = await UpdateSettings(
+            main.Settings.createFrom({
+              ...settings,
+              alert_time: Number(localStorage.getItem("alertTime") ?? settings.alert_time),
+              app_theme: localStorage.getItem("appTheme") ?? settings.app_theme,
+              enable_color_on_dark: JSON.parse(
+                localStorage.getItem("enableColorOnDark") ?? JSON.stringify(settings.enable_color_on_dark)
+              ),
+            })
+          );
+          for (const key of legacy) localStorage.removeItem(key);
+        }
+        get().applySettings(settings);
+        if (!get().settingsLoaded) {
+          EventsOn("settings-updated", (updated: main.Settings) => get().applySettings(updated));
+          set({ settingsLoaded: true });
+        }
+      },
+      applySettings: (settings: main.Settings) => {
+        set({
+          alertTime: settings.alert_time,
+          appTheme: settings.app_theme as Store["appTheme"],
+          enableColorOnDark: settings.enable_color_on_dark,
+        });
+      },
+      saveSettings: async (changes: Partial<main.Settings>) => {
+        const current = await GetSettings();
+        try {
+          await UpdateSettings(main.Settings.createFrom({ ...current, ...changes }));
+        } catch (err) {
+          console.error(err);
+          get().applySettings(current);
+        }
+      },
       appMode: "normal",
       setAppMode: (mode: "normal" | "widget") => {
         if (mode === get().appMode) return;
         set({ appMode: mode });
       },
-      appTheme: (localStorage.getItem("appTheme") as Store["appTheme"]) ?? "dark",
+      appTheme: "dark",
       setAppTheme: (theme: "light" | "dark") => {
         if (theme === get().appTheme) return;
-        localStorage.setItem("appTheme", theme);
         set({ appTheme: theme });
+        get().saveSettings({ app_theme: theme });
       },
       toggleAppTheme: () => {
         const theme = get().appTheme === "dark" ? "light" : "dark";
-        localStorage.setItem("appTheme", theme);
         set({ appTheme: theme });
+        get().saveSettings({ app_theme: theme });
       },
-      enableColorOnDark: JSON.parse(localStorage.getItem("enableColorOnDark") ?? "false"),
+      enableColorOnDark: false,
       toggleEnableColorOnDark: () => {
-        localStorage.setItem("enableColorOnDark", JSON.stringify(!get().enableColorOnDark));
-        set((state) => ({ enableColorOnDark: !state.enableColorOnDark }));
+        const enableColorOnDark = !get().enableColorOnDark;
+        set({ enableColorOnDark });
+        get().saveSettings({ enable_color_on_dark: enableColorOnDark });
       },
       organizations: [],
       getOrganizations: () => JSON.parse(JSON.stringify(get().organizations)),
@@ -143,12 +190,11 @@ export const useAppStore = create(
           activeProj: project,
         }));
       },
-      alertTime: Number(localStorage.getItem("alertTime")) || 30,
+      alertTime: 30,
       setAlertTime: (time: number) => {
         if (time === get().alertTime) return;
-        // temp fix so this value persists
-        localStorage.setItem("alertTime", time.toString());
         set({ alertTime: time });
+        get().saveSettings({ alert_time: time });
       },
       orgDayTotal: 0,
       setOrgDayTotal: (value: number) => {
@@ -228,6 +274,11 @@ export const useAppStore = create(
     {
       name: "store",
       storage: createJSONStorage(() => sessionStorage),
+      partialize: (state) => {
+        // settingsLoaded tracks the event subscription of this page load, never persist it
+        const { settingsLoaded, ...rest } = state;
+        return rest;
+      },
     }
   )
 );
@@ -1,4 +1,6 @@
+import { errorMessage } from "@/utils/utils";
 import { StartTimer, StopTimer } from "@go/main/App";
+import { toast } from "react-toastify";
 import { create } from "zustand";
 import { createJSONStorage, persist, subscribeWithSelector } from "zustand/middleware";
 import { useAppStore } from "./main";
@@ -37,9 +39,11 @@ export const useTimerStore = create(
         if (!selectedOrganization) return;
         const selectedProject = useAppStore.getState().activeProj;
         if (!selectedProject) return;
-        StartTimer(selectedOrganization, selectedProject).then(() => {
-          set({ running: true, elapsedTime: 0 });
-        });
+        StartTimer(selectedOrganization, selectedProject)
+          .then(() => {
+            set({ running: true, elapsedTime: 0 });
+          })
+          .catch((err) => toast.error(`Failed to start the timer: ${errorMessage(err)}`));
       },
       stopTimer: () => {
         StopTimer().then(() => {
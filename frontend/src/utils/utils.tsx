@@ -16,6 +16,35 @@ export const months: Record<number, string> = {
   12: "December",
 };
 
+/**
+ * Archived state filter of GetOrganizations and GetProjects, see archive.go
+ */
+export enum ArchiveFilter {
+  Active = "active",
+  Archived = "archived",
+  All = "all",
+}
+
+/**
+ * Error returned by the backend, see AppError in errors.go
+ */
+export type AppError = {
+  code: "not_found" | "conflict" | "locked" | "validation" | "storage" | "internal";
+  message: string;
+};
+
+export const isAppError = (err: unknown): err is AppError =>
+  typeof err === "object" && err !== null && "code" in err && "message" in err;
+
+/**
+ * Get a displayable message from a rejected backend call
+ */
+export const errorMessage = (err: unknown): string => {
+  if (isAppError(err)) return err.message;
+  if (err instanceof Error) return err.message;
+  return String(err);
+};
+
 export const formatTime = (timeInSeconds: number) => {
   let hours = String(Math.floor(timeInSeconds / 3600)).padStart(2, "0");
   let minutes = String(Math.floor((timeInSeconds % 3600) / 60)).padStart(2, "0");
@@ -3,33 +3,97 @@
 import {main} from '../models';
 import {time} from '../models';
 
+export function AddAbsence(arg1:number,arg2:string,arg3:string,arg4:main.AbsenceType,arg5:string,arg6:number):Promise<Array<main.Absence>>;
+
+export function ArchiveOrganization(arg1:number):Promise<main.Organization>;
+
+export function ArchiveProject(arg1:number):Promise<main.Project>;
+
 export function CheckForUpdates():Promise<boolean>;
 
+export function CheckWorkHours():Promise<main.ReconciliationReport>;
+
 export function ConfirmAction(arg1:string,arg2:string):Promise<boolean>;
 
+export function CreateWorkspace(arg1:string):Promise<main.Workspace>;
+
+export function DeleteAbsence(arg1:number):Promise<void>;
+
+export function DeleteBackupTarget(arg1:number):Promise<void>;
+
+export function DeleteClient(arg1:number):Promise<void>;
+
 export function DeleteOrganization(arg1:number):Promise<void>;
 
 export function DeleteProject(arg1:number):Promise<void>;
 
+export function DeleteReportSchedule(arg1:number):Promise<void>;
+
+export function DeleteTask(arg1:number):Promise<void>;
+
 export function DeleteWorkSession(arg1:number):Promise<void>;
 
+export function DeleteWorkspace(arg1:string):Promise<void>;
+
+export function EmailReport(arg1:number,arg2:main.ExportType,arg3:string,arg4:string):Promise<main.MailDelivery>;
+
+export function ExportAuditLog(arg1:main.AuditFilter):Promise<string>;
+
 export function ExportByMonth(arg1:main.ExportType,arg2:string,arg3:number,arg4:time.Month):Promise<string>;
 
 export function ExportByYear(arg1:main.ExportType,arg2:string,arg3:number):Promise<string>;
 
+export function ExportClientByMonth(arg1:main.ExportType,arg2:number,arg3:number,arg4:time.Month):Promise<string>;
+
+export function ExportClientByYear(arg1:main.ExportType,arg2:number,arg3:number):Promise<string>;
+
+export function ExportSettings():Promise<string>;
+
+export function FindRemoteBackups(arg1:main.BackupTarget):Promise<Array<main.RemoteBackup>>;
+
+export function GetAbsences(arg1:number,arg2:string,arg3:string):Promise<Array<main.Absence>>;
+
 export function GetActiveTimer():Promise<main.ActiveTimer>;
 
 export function GetAllProjects():Promise<Array<main.Project>>;
 
+export function GetAuditLog(arg1:main.AuditFilter):Promise<Array<main.AuditEntry>>;
+
+export function GetBackupRuns(arg1:number):Promise<Array<main.BackupRun>>;
+
+export function GetBackupTargets():Promise<Array<main.BackupTarget>>;
+
+export function GetClientWorkTimeForRange(arg1:string,arg2:string,arg3:number):Promise<{[key: string]: number}>;
+
+export function GetClients():Promise<Array<main.Client>>;
+
+export function GetComplianceReport(arg1:number,arg2:string,arg3:string):Promise<Array<main.ComplianceViolation>>;
+
+export function GetComplianceRules(arg1:number):Promise<main.ComplianceRules>;
+
 export function GetDailyWorkTimeByMonth(arg1:number,arg2:time.Month,arg3:number):Promise<{[key: string]: {[key: string]: number}}>;
 
+export function GetEncryptionStatus():Promise<main.EncryptionStatus>;
+
+export function GetJournalState():Promise<main.JournalState>;
+
+export function GetLeaveBalances(arg1:number,arg2:number):Promise<Array<main.LeaveBalance>>;
+
+export function GetMailDeliveries(arg1:number):Promise<Array<main.MailDelivery>>;
+
 export function GetMonthlyWorkTime(arg1:number,arg2:number):Promise<{[key: number]: {[key: string]: number}}>;
 
 export function GetOrgWorkTimeByMonth(arg1:number,arg2:time.Month,arg3:number):Promise<number>;
 
 export function GetOrgWorkTimeByWeek(arg1:number,arg2:time.Month,arg3:number,arg4:number):Promise<number>;
 
-export function GetOrganizations():Promise<Array<main.Organization>>;
+export function GetOrganizations(arg1:main.ArchiveFilter):Promise<Array<main.Organization>>;
+
+export function GetOvertime(arg1:number,arg2:string,arg3:string):Promise<main.OvertimeSummary>;
+
+export function GetParallelTimers():Promise<Array<main.ParallelTimer>>;
+
+export function GetPeriodLocks(arg1:number,arg2:string,arg3:string):Promise<Array<main.PeriodLock>>;
 
 export function GetProjWorkTimeByMonth(arg1:number,arg2:time.Month,arg3:number):Promise<number>;
 
@@ -37,7 +101,37 @@ export function GetProjWorkTimeByWeek(arg1:number,arg2:time.Month,arg3:number,ar
 
 export function GetProjectWorkTimeForRange(arg1:string,arg2:string,arg3:number):Promise<number>;
 
-export function GetProjects(arg1:number):Promise<Array<main.Project>>;
+export function GetProjects(arg1:number,arg2:main.ArchiveFilter):Promise<Array<main.Project>>;
+
+export function GetRemoteBackups(arg1:number):Promise<Array<main.RemoteBackup>>;
+
+export function GetReportMailing(arg1:number):Promise<main.ReportMailing>;
+
+export function GetReportRuns(arg1:number):Promise<Array<main.ReportRun>>;
+
+export function GetReportSchedules(arg1:number):Promise<Array<main.ReportSchedule>>;
+
+export function GetReportTemplate(arg1:number):Promise<main.ReportTemplate>;
+
+export function GetRoundingPolicy(arg1:number):Promise<main.RoundingPolicy>;
+
+export function GetSettings():Promise<main.Settings>;
+
+export function GetSyncConfig():Promise<main.SyncConfig>;
+
+export function GetTaskTimes(arg1:number,arg2:string,arg3:string):Promise<Array<main.TaskTime>>;
+
+export function GetTasks(arg1:number):Promise<Array<main.Task>>;
+
+export function GetTeamConfig():Promise<main.TeamConfig>;
+
+export function GetTimerOverlaps(arg1:string,arg2:string,arg3:number):Promise<Array<main.TimerOverlap>>;
+
+export function GetTimerStack():Promise<Array<main.TimerFrame>>;
+
+export function GetToday(arg1:number):Promise<string>;
+
+export function GetTrash():Promise<main.Trash>;
 
 export function GetVersion():Promise<string>;
 
@@ -45,6 +139,8 @@ export function GetWeekOfMonth(arg1:number,arg2:time.Month,arg3:number):Promise<
 
 export function GetWeeklyWorkTime(arg1:number,arg2:time.Month,arg3:number):Promise<{[key: number]: {[key: string]: number}}>;
 
+export function GetWorkSchedule(arg1:number):Promise<main.WorkSchedule>;
+
 export function GetWorkSessions():Promise<Array<main.WorkSession>>;
 
 export function GetWorkSessionsByDate(arg1:string):Promise<Array<main.WorkSession>>;
@@ -61,34 +157,124 @@ export function GetWorkTimeByWeek(arg1:number,arg2:time.Month,arg3:number,arg4:n
 
 export function GetWorkTimeForRange(arg1:string,arg2:string,arg3:number):Promise<{[key: string]: number}>;
 
+export function GetWorkspaces():Promise<Array<main.Workspace>>;
+
 export function GetYearlyWorkTime(arg1:number,arg2:number):Promise<number>;
 
 export function GetYearlyWorkTimeByProject(arg1:number,arg2:number):Promise<{[key: string]: number}>;
 
+export function ImportHolidays(arg1:number):Promise<number>;
+
+export function ImportSettings():Promise<main.Settings>;
+
+export function LockPeriod(arg1:number,arg2:main.PeriodKind,arg3:string,arg4:string):Promise<main.PeriodLock>;
+
 export function MinimizeWindow():Promise<void>;
 
 export function NewOrganization(arg1:string,arg2:string):Promise<main.NewOrgRet>;
 
 export function NewProject(arg1:string,arg2:string):Promise<main.Project>;
 
+export function NewTask(arg1:number,arg2:number,arg3:string,arg4:number):Promise<main.Task>;
+
 export function NewWorkSession(arg1:number,arg2:number):Promise<main.WorkSession>;
 
 export function NormalizeWindow():Promise<void>;
 
+export function PushNow():Promise<main.TeamConfig>;
+
+export function Redo():Promise<main.JournalState>;
+
 export function RenameOrganization(arg1:number,arg2:string):Promise<main.Organization>;
 
 export function RenameProject(arg1:number,arg2:string):Promise<main.Project>;
 
+export function RepairWorkHours(arg1:boolean):Promise<main.ReconciliationReport>;
+
+export function ResetReportTemplate(arg1:number):Promise<main.ReportTemplate>;
+
+export function RestoreBackup(arg1:number,arg2:string,arg3:string):Promise<void>;
+
+export function RestoreOrganization(arg1:number):Promise<void>;
+
+export function RestoreProject(arg1:number):Promise<void>;
+
+export function RestoreRemoteBackup(arg1:main.BackupTarget,arg2:string):Promise<void>;
+
+export function RestoreWorkHours(arg1:number):Promise<void>;
+
+export function RetryMailDelivery(arg1:number):Promise<main.MailDelivery>;
+
+export function ReturnToPreviousTimer():Promise<main.ActiveTimer>;
+
+export function RunBackup(arg1:number):Promise<main.BackupRun>;
+
+export function RunReportSchedule(arg1:number):Promise<main.ReportRun>;
+
+export function SaveBackupTarget(arg1:main.BackupTarget):Promise<main.BackupTarget>;
+
+export function SaveClient(arg1:main.Client):Promise<main.Client>;
+
+export function SaveComplianceRules(arg1:main.ComplianceRules):Promise<main.ComplianceRules>;
+
+export function SaveLeaveAllowance(arg1:main.LeaveAllowance):Promise<main.LeaveAllowance>;
+
+export function SaveReportMailing(arg1:main.ReportMailing):Promise<main.ReportMailing>;
+
+export function SaveReportSchedule(arg1:main.ReportSchedule):Promise<main.ReportSchedule>;
+
+export function SaveReportTemplate(arg1:main.ReportTemplate):Promise<main.ReportTemplate>;
+
+export function SaveRoundingPolicy(arg1:main.RoundingPolicy):Promise<main.RoundingPolicy>;
+
+export function SaveSyncConfig(arg1:string,arg2:number):Promise<main.SyncConfig>;
+
+export function SaveTeamConfig(arg1:string,arg2:string):Promise<main.TeamConfig>;
+
+export function SaveWorkSchedule(arg1:main.WorkSchedule):Promise<main.WorkSchedule>;
+
+export function SelectExportDir():Promise<string>;
+
+export function SelectReportLogo():Promise<string>;
+
+export function SelectSyncFolder():Promise<string>;
+
+export function SendTestEmail(arg1:string):Promise<void>;
+
+export function SetEncryption(arg1:main.EncryptionMode,arg2:string,arg3:string):Promise<main.EncryptionStatus>;
+
 export function SetOrganization(arg1:number):Promise<void>;
 
+export function SetOrganizationClient(arg1:number,arg2:number):Promise<main.Organization>;
+
+export function SetOrganizationTimezone(arg1:number,arg2:string):Promise<main.Organization>;
+
+export function SetPeriodStatus(arg1:number,arg2:main.PeriodStatus):Promise<main.PeriodLock>;
+
 export function SetProject(arg1:number):Promise<void>;
 
+export function SetProjectClient(arg1:number,arg2:number):Promise<main.Project>;
+
+export function SetTask(arg1:number):Promise<void>;
+
+export function SetWorkSessionTask(arg1:number,arg2:number):Promise<main.WorkSession>;
+
 export function ShowWindow():Promise<void>;
 
+export function StartParallelTimer(arg1:number,arg2:number):Promise<main.ParallelTimer>;
+
 export function StartTimer(arg1:main.Organization,arg2:main.Project):Promise<void>;
 
+export function StopParallelTimer(arg1:number):Promise<void>;
+
 export function StopTimer():Promise<void>;
 
+export function SwitchTimer(arg1:number,arg2:number):Promise<main.ActiveTimer>;
+
+export function SwitchWorkspace(arg1:string):Promise<main.EncryptionStatus>;
+
+export function SyncNow():Promise<main.SyncReport>;
+
 export function TimeElapsed():Promise<number>;
 
 export function TimerRunning():Promise<boolean>;
@@ -99,4 +285,20 @@ export function ToggleFavoriteProject(arg1:number):Promise<void>;
 
 export function TransferWorkSession(arg1:number,arg2:number):Promise<void>;
 
+export function UnarchiveOrganization(arg1:number):Promise<main.Organization>;
+
+export function UnarchiveProject(arg1:number):Promise<main.Project>;
+
+export function Undo():Promise<main.JournalState>;
+
+export function Unlock(arg1:string):Promise<void>;
+
+export function UnlockPeriod(arg1:number):Promise<void>;
+
 export function UpdateAvailable():Promise<boolean>;
+
+export function UpdateSettings(arg1:main.Settings):Promise<main.Settings>;
+
+export function UpdateTask(arg1:main.Task):Promise<main.Task>;
+
+export function VerifyBackup(arg1:number,arg2:string,arg3:string):Promise<void>;
@@ -2,14 +2,46 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAbsence(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['AddAbsence'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function ArchiveOrganization(arg1) {
+  return window['go']['main']['App']['ArchiveOrganization'](arg1);
+}
+
+export function ArchiveProject(arg1) {
+  return window['go']['main']['App']['ArchiveProject'](arg1);
+}
+
 export function CheckForUpdates() {
   return window['go']['main']['App']['CheckForUpdates']();
 }
 
+export function CheckWorkHours() {
+  return window['go']['main']['App']['CheckWorkHours']();
+}
+
 export function ConfirmAction(arg1, arg2) {
   return window['go']['main']['App']['ConfirmAction'](arg1, arg2);
 }
 
+export function CreateWorkspace(arg1) {
+  return window['go']['main']['App']['CreateWorkspace'](arg1);
+}
+
+export function DeleteAbsence(arg1) {
+  return window['go']['main']['App']['DeleteAbsence'](arg1);
+}
+
+export function DeleteBackupTarget(arg1) {
+  return window['go']['main']['App']['DeleteBackupTarget'](arg1);
+}
+
+export function DeleteClient(arg1) {
+  return window['go']['main']['App']['DeleteClient'](arg1);
+}
+
 export function DeleteOrganization(arg1) {
   return window['go']['main']['App']['DeleteOrganization'](arg1);
 }
@@ -18,10 +50,30 @@ export function DeleteProject(arg1) {
   return window['go']['main']['App']['DeleteProject'](arg1);
 }
 
+export function DeleteReportSchedule(arg1) {
+  return window['go']['main']['App']['DeleteReportSchedule'](arg1);
+}
+
+export function DeleteTask(arg1) {
+  return window['go']['main']['App']['DeleteTask'](arg1);
+}
+
 export function DeleteWorkSession(arg1) {
   return window['go']['main']['App']['DeleteWorkSession'](arg1);
 }
 
+export function DeleteWorkspace(arg1) {
+  return window['go']['main']['App']['DeleteWorkspace'](arg1);
+}
+
+export function EmailReport(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['EmailReport'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportAuditLog(arg1) {
+  return window['go']['main']['App']['ExportAuditLog'](arg1);
+}
+
 export function ExportByMonth(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['ExportByMonth'](arg1, arg2, arg3, arg4);
 }
@@ -30,6 +82,26 @@ export function ExportByYear(arg1, arg2, arg3) {
   return window['go']['main']['App']['ExportByYear'](arg1, arg2, arg3);
 }
 
+export function ExportClientByMonth(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportClientByMonth'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportClientByYear(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportClientByYear'](arg1, arg2, arg3);
+}
+
+export function ExportSettings() {
+  return window['go']['main']['App']['ExportSettings']();
+}
+
+export function FindRemoteBackups(arg1) {
+  return window['go']['main']['App']['FindRemoteBackups'](arg1);
+}
+
+export function GetAbsences(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetAbsences'](arg1, arg2, arg3);
+}
+
 export function GetActiveTimer() {
   return window['go']['main']['App']['GetActiveTimer']();
 }
@@ -38,10 +110,54 @@ export function GetAllProjects() {
   return window['go']['main']['App']['GetAllProjects']();
 }
 
+export function GetAuditLog(arg1) {
+  return window['go']['main']['App']['GetAuditLog'](arg1);
+}
+
+export function GetBackupRuns(arg1) {
+  return window['go']['main']['App']['GetBackupRuns'](arg1);
+}
+
+export function GetBackupTargets() {
+  return window['go']['main']['App']['GetBackupTargets']();
+}
+
+export function GetClientWorkTimeForRange(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetClientWorkTimeForRange'](arg1, arg2, arg3);
+}
+
+export function GetClients() {
+  return window['go']['main']['App']['GetClients']();
+}
+
+export function GetComplianceReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetComplianceReport'](arg1, arg2, arg3);
+}
+
+export function GetComplianceRules(arg1) {
+  return window['go']['main']['App']['GetComplianceRules'](arg1);
+}
+
 export function GetDailyWorkTimeByMonth(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetDailyWorkTimeByMonth'](arg1, arg2, arg3);
 }
 
+export function GetEncryptionStatus() {
+  return window['go']['main']['App']['GetEncryptionStatus']();
+}
+
+export function GetJournalState() {
+  return window['go']['main']['App']['GetJournalState']();
+}
+
+export function GetLeaveBalances(arg1, arg2) {
+  return window['go']['main']['App']['GetLeaveBalances'](arg1, arg2);
+}
+
+export function GetMailDeliveries(arg1) {
+  return window['go']['main']['App']['GetMailDeliveries'](arg1);
+}
+
 export function GetMonthlyWorkTime(arg1, arg2) {
   return window['go']['main']['App']['GetMonthlyWorkTime'](arg1, arg2);
 }
@@ -54,8 +170,20 @@ export function GetOrgWorkTimeByWeek(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['GetOrgWorkTimeByWeek'](arg1, arg2, arg3, arg4);
 }
 
-export function GetOrganizations() {
-  return window['go']['main']['App']['GetOrganizations']();
+export function GetOrganizations(arg1) {
+  return window['go']['main']['App']['GetOrganizations'](arg1);
+}
+
+export function GetOvertime(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetOvertime'](arg1, arg2, arg3);
+}
+
+export function GetParallelTimers() {
+  return window['go']['main']['App']['GetParallelTimers']();
+}
+
+export function GetPeriodLocks(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetPeriodLocks'](arg1, arg2, arg3);
 }
 
 export function GetProjWorkTimeByMonth(arg1, arg2, arg3) {
@@ -70,8 +198,68 @@ export function GetProjectWorkTimeForRange(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetProjectWorkTimeForRange'](arg1, arg2, arg3);
 }
 
-export function GetProjects(arg1) {
-  return window['go']['main']['App']['GetProjects'](arg1);
+export function GetProjects(arg1, arg2) {
+  return window['go']['main']['App']['GetProjects'](arg1, arg2);
+}
+
+export function GetRemoteBackups(arg1) {
+  return window['go']['main']['App']['GetRemoteBackups'](arg1);
+}
+
+export function GetReportMailing(arg1) {
+  return window['go']['main']['App']['GetReportMailing'](arg1);
+}
+
+export function GetReportRuns(arg1) {
+  return window['go']['main']['App']['GetReportRuns'](arg1);
+}
+
+export function GetReportSchedules(arg1) {
+  return window['go']['main']['App']['GetReportSchedules'](arg1);
+}
+
+export function GetReportTemplate(arg1) {
+  return window['go']['main']['App']['GetReportTemplate'](arg1);
+}
+
+export function GetRoundingPolicy(arg1) {
+  return window['go']['main']['App']['GetRoundingPolicy'](arg1);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetSyncConfig() {
+  return window['go']['main']['App']['GetSyncConfig']();
+}
+
+export function GetTaskTimes(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTaskTimes'](arg1, arg2, arg3);
+}
+
+export function GetTasks(arg1) {
+  return window['go']['main']['App']['GetTasks'](arg1);
+}
+
+export function GetTeamConfig() {
+  return window['go']['main']['App']['GetTeamConfig']();
+}
+
+export function GetTimerOverlaps(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTimerOverlaps'](arg1, arg2, arg3);
+}
+
+export function GetTimerStack() {
+  return window['go']['main']['App']['GetTimerStack']();
+}
+
+export function GetToday(arg1) {
+  return window['go']['main']['App']['GetToday'](arg1);
+}
+
+export function GetTrash() {
+  return window['go']['main']['App']['GetTrash']();
 }
 
 export function GetVersion() {
@@ -86,6 +274,10 @@ export function GetWeeklyWorkTime(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetWeeklyWorkTime'](arg1, arg2, arg3);
 }
 
+export function GetWorkSchedule(arg1) {
+  return window['go']['main']['App']['GetWorkSchedule'](arg1);
+}
+
 export function GetWorkSessions() {
   return window['go']['main']['App']['GetWorkSessions']();
 }
@@ -118,6 +310,10 @@ export function GetWorkTimeForRange(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetWorkTimeForRange'](arg1, arg2, arg3);
 }
 
+export function GetWorkspaces() {
+  return window['go']['main']['App']['GetWorkspaces']();
+}
+
 export function GetYearlyWorkTime(arg1, arg2) {
   return window['go']['main']['App']['GetYearlyWorkTime'](arg1, arg2);
 }
@@ -126,6 +322,18 @@ export function GetYearlyWorkTimeByProject(arg1, arg2) {
   return window['go']['main']['App']['GetYearlyWorkTimeByProject'](arg1, arg2);
 }
 
+export function ImportHolidays(arg1) {
+  return window['go']['main']['App']['ImportHolidays'](arg1);
+}
+
+export function ImportSettings() {
+  return window['go']['main']['App']['ImportSettings']();
+}
+
+export function LockPeriod(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['LockPeriod'](arg1, arg2, arg3, arg4);
+}
+
 export function MinimizeWindow() {
   return window['go']['main']['App']['MinimizeWindow']();
 }
@@ -138,6 +346,10 @@ export function NewProject(arg1, arg2) {
   return window['go']['main']['App']['NewProject'](arg1, arg2);
 }
 
+export function NewTask(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['NewTask'](arg1, arg2, arg3, arg4);
+}
+
 export function NewWorkSession(arg1, arg2) {
   return window['go']['main']['App']['NewWorkSession'](arg1, arg2);
 }
@@ -146,6 +358,14 @@ export function NormalizeWindow() {
   return window['go']['main']['App']['NormalizeWindow']();
 }
 
+export function PushNow() {
+  return window['go']['main']['App']['PushNow']();
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
 export function RenameOrganization(arg1, arg2) {
   return window['go']['main']['App']['RenameOrganization'](arg1, arg2);
 }
@@ -154,26 +374,178 @@ export function RenameProject(arg1, arg2) {
   return window['go']['main']['App']['RenameProject'](arg1, arg2);
 }
 
+export function RepairWorkHours(arg1) {
+  return window['go']['main']['App']['RepairWorkHours'](arg1);
+}
+
+export function ResetReportTemplate(arg1) {
+  return window['go']['main']['App']['ResetReportTemplate'](arg1);
+}
+
+export function RestoreBackup(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RestoreBackup'](arg1, arg2, arg3);
+}
+
+export function RestoreOrganization(arg1) {
+  return window['go']['main']['App']['RestoreOrganization'](arg1);
+}
+
+export function RestoreProject(arg1) {
+  return window['go']['main']['App']['RestoreProject'](arg1);
+}
+
+export function RestoreRemoteBackup(arg1, arg2) {
+  return window['go']['main']['App']['RestoreRemoteBackup'](arg1, arg2);
+}
+
+export function RestoreWorkHours(arg1) {
+  return window['go']['main']['App']['RestoreWorkHours'](arg1);
+}
+
+export function RetryMailDelivery(arg1) {
+  return window['go']['main']['App']['RetryMailDelivery'](arg1);
+}
+
+export function ReturnToPreviousTimer() {
+  return window['go']['main']['App']['ReturnToPreviousTimer']();
+}
+
+export function RunBackup(arg1) {
+  return window['go']['main']['App']['RunBackup'](arg1);
+}
+
+export function RunReportSchedule(arg1) {
+  return window['go']['main']['App']['RunReportSchedule'](arg1);
+}
+
+export function SaveBackupTarget(arg1) {
+  return window['go']['main']['App']['SaveBackupTarget'](arg1);
+}
+
+export function SaveClient(arg1) {
+  return window['go']['main']['App']['SaveClient'](arg1);
+}
+
+export function SaveComplianceRules(arg1) {
+  return window['go']['main']['App']['SaveComplianceRules'](arg1);
+}
+
+export function SaveLeaveAllowance(arg1) {
+  return window['go']['main']['App']['SaveLeaveAllowance'](arg1);
+}
+
+export function SaveReportMailing(arg1) {
+  return window['go']['main']['App']['SaveReportMailing'](arg1);
+}
+
+export function SaveReportSchedule(arg1) {
+  return window['go']['main']['App']['SaveReportSchedule'](arg1);
+}
+
+export function SaveReportTemplate(arg1) {
+  return window['go']['main']['App']['SaveReportTemplate'](arg1);
+}
+
+export function SaveRoundingPolicy(arg1) {
+  return window['go']['main']['App']['SaveRoundingPolicy'](arg1);
+}
+
+export function SaveSyncConfig(arg1, arg2) {
+  return window['go']['main']['App']['SaveSyncConfig'](arg1, arg2);
+}
+
+export function SaveTeamConfig(arg1, arg2) {
+  return window['go']['main']['App']['SaveTeamConfig'](arg1, arg2);
+}
+
+export function SaveWorkSchedule(arg1) {
+  return window['go']['main']['App']['SaveWorkSchedule'](arg1);
+}
+
+export function SelectExportDir() {
+  return window['go']['main']['App']['SelectExportDir']();
+}
+
+export function SelectReportLogo() {
+  return window['go']['main']['App']['SelectReportLogo']();
+}
+
+export function SelectSyncFolder() {
+  return window['go']['main']['App']['SelectSyncFolder']();
+}
+
+export function SendTestEmail(arg1) {
+  return window['go']['main']['App']['SendTestEmail'](arg1);
+}
+
+export function SetEncryption(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetEncryption'](arg1, arg2, arg3);
+}
+
 export function SetOrganization(arg1) {
   return window['go']['main']['App']['SetOrganization'](arg1);
 }
 
+export function SetOrganizationClient(arg1, arg2) {
+  return window['go']['main']['App']['SetOrganizationClient'](arg1, arg2);
+}
+
+export function SetOrganizationTimezone(arg1, arg2) {
+  return window['go']['main']['App']['SetOrganizationTimezone'](arg1, arg2);
+}
+
+export function SetPeriodStatus(arg1, arg2) {
+  return window['go']['main']['App']['SetPeriodStatus'](arg1, arg2);
+}
+
 export function SetProject(arg1) {
   return window['go']['main']['App']['SetProject'](arg1);
 }
 
+export function SetProjectClient(arg1, arg2) {
+  return window['go']['main']['App']['SetProjectClient'](arg1, arg2);
+}
+
+export function SetTask(arg1) {
+  return window['go']['main']['App']['SetTask'](arg1);
+}
+
+export function SetWorkSessionTask(arg1, arg2) {
+  return window['go']['main']['App']['SetWorkSessionTask'](arg1, arg2);
+}
+
 export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
 
+export function StartParallelTimer(arg1, arg2) {
+  return window['go']['main']['App']['StartParallelTimer'](arg1, arg2);
+}
+
 export function StartTimer(arg1, arg2) {
   return window['go']['main']['App']['StartTimer'](arg1, arg2);
 }
 
+export function StopParallelTimer(arg1) {
+  return window['go']['main']['App']['StopParallelTimer'](arg1);
+}
+
 export function StopTimer() {
   return window['go']['main']['App']['StopTimer']();
 }
 
+export function SwitchTimer(arg1, arg2) {
+  return window['go']['main']['App']['SwitchTimer'](arg1, arg2);
+}
+
+export function SwitchWorkspace(arg1) {
+  return window['go']['main']['App']['SwitchWorkspace'](arg1);
+}
+
+export function SyncNow() {
+  return window['go']['main']['App']['SyncNow']();
+}
+
 export function TimeElapsed() {
   return window['go']['main']['App']['TimeElapsed']();
 }
@@ -194,6 +566,38 @@ export function TransferWorkSession(arg1, arg2) {
   return window['go']['main']['App']['TransferWorkSession'](arg1, arg2);
 }
 
+export function UnarchiveOrganization(arg1) {
+  return window['go']['main']['App']['UnarchiveOrganization'](arg1);
+}
+
+export function UnarchiveProject(arg1) {
+  return window['go']['main']['App']['UnarchiveProject'](arg1);
+}
+
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
+export function Unlock(arg1) {
+  return window['go']['main']['App']['Unlock'](arg1);
+}
+
+export function UnlockPeriod(arg1) {
+  return window['go']['main']['App']['UnlockPeriod'](arg1);
+}
+
 export function UpdateAvailable() {
   return window['go']['main']['App']['UpdateAvailable']();
 }
+
+export function UpdateSettings(arg1) {
+  return window['go']['main']['App']['UpdateSettings'](arg1);
+}
+
+export function UpdateTask(arg1) {
+  return window['go']['main']['App']['UpdateTask'](arg1);
+}
+
+export function VerifyBackup(arg1, arg2, arg3) {
+  return window['go']['main']['App']['VerifyBackup'](arg1, arg2, arg3);
+}
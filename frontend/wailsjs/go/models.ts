@@ -38,6 +38,104 @@ export namespace gorm {
 
 export namespace main {
 	
+	export class Absence {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    deleted_at: gorm.DeletedAt;
+	    organization_id: number;
+	    date: string;
+	    type: string;
+	    name: string;
+	    minutes: number;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Absence(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.organization_id = source["organization_id"];
+	        this.date = source["date"];
+	        this.type = source["type"];
+	        this.name = source["name"];
+	        this.minutes = source["minutes"];
+	        this.source = source["source"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Task {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    deleted_at: gorm.DeletedAt;
+	    name: string;
+	    project_id: number;
+	    parent_id?: number;
+	    status: string;
+	    estimate_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Task(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.name = source["name"];
+	        this.project_id = source["project_id"];
+	        this.parent_id = source["parent_id"];
+	        this.status = source["status"];
+	        this.estimate_seconds = source["estimate_seconds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class WorkHours {
 	    id: number;
 	    // Go type: time
@@ -89,9 +187,13 @@ export namespace main {
 	    // Go type: time
 	    updated_at: any;
 	    deleted_at: gorm.DeletedAt;
+	    uid: string;
 	    name: string;
 	    organization_id: number;
 	    favorite: boolean;
+	    // Go type: time
+	    archived_at?: any;
+	    client_id?: number;
 	    work_hours: WorkHours[];
 	
 	    static createFrom(source: any = {}) {
@@ -104,9 +206,12 @@ export namespace main {
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.uid = source["uid"];
 	        this.name = source["name"];
 	        this.organization_id = source["organization_id"];
 	        this.favorite = source["favorite"];
+	        this.archived_at = this.convertValues(source["archived_at"], null);
+	        this.client_id = source["client_id"];
 	        this.work_hours = this.convertValues(source["work_hours"], WorkHours);
 	    }
 	
@@ -135,8 +240,13 @@ export namespace main {
 	    // Go type: time
 	    updated_at: any;
 	    deleted_at: gorm.DeletedAt;
+	    uid: string;
 	    name: string;
 	    favorite: boolean;
+	    timezone: string;
+	    // Go type: time
+	    archived_at?: any;
+	    client_id?: number;
 	    projects: Project[];
 	
 	    static createFrom(source: any = {}) {
@@ -149,8 +259,12 @@ export namespace main {
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.uid = source["uid"];
 	        this.name = source["name"];
 	        this.favorite = source["favorite"];
+	        this.timezone = source["timezone"];
+	        this.archived_at = this.convertValues(source["archived_at"], null);
+	        this.client_id = source["client_id"];
 	        this.projects = this.convertValues(source["projects"], Project);
 	    }
 	
@@ -175,6 +289,7 @@ export namespace main {
 	export class ActiveTimer {
 	    organization: Organization;
 	    project: Project;
+	    task: Task;
 	    isRunning: boolean;
 	    timeElapsed: number;
 	
@@ -186,6 +301,7 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.organization = this.convertValues(source["organization"], Organization);
 	        this.project = this.convertValues(source["project"], Project);
+	        this.task = this.convertValues(source["task"], Task);
 	        this.isRunning = source["isRunning"];
 	        this.timeElapsed = source["timeElapsed"];
 	    }
@@ -208,18 +324,35 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class NewOrgRet {
-	    organization: Organization;
-	    project: Project;
+	export class AuditEntry {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    entity_type: string;
+	    entity_id: number;
+	    project_id: number;
+	    date: string;
+	    action: string;
+	    source: string;
+	    before: string;
+	    after: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new NewOrgRet(source);
+	        return new AuditEntry(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.organization = this.convertValues(source["organization"], Organization);
-	        this.project = this.convertValues(source["project"], Project);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.entity_type = source["entity_type"];
+	        this.entity_id = source["entity_id"];
+	        this.project_id = source["project_id"];
+	        this.date = source["date"];
+	        this.action = source["action"];
+	        this.source = source["source"];
+	        this.before = source["before"];
+	        this.after = source["after"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -240,22 +373,184 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class AuditFilter {
+	    entity_type: string;
+	    organization_id: number;
+	    project_id: number;
+	    start_date: string;
+	    end_date: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entity_type = source["entity_type"];
+	        this.organization_id = source["organization_id"];
+	        this.project_id = source["project_id"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class BackupRun {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    target_id: number;
+	    object_name: string;
+	    size: number;
+	    sha256: string;
+	    // Go type: time
+	    verified_at?: any;
+	    status: string;
+	    error: string;
 	
+	    static createFrom(source: any = {}) {
+	        return new BackupRun(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.target_id = source["target_id"];
+	        this.object_name = source["object_name"];
+	        this.size = source["size"];
+	        this.sha256 = source["sha256"];
+	        this.verified_at = this.convertValues(source["verified_at"], null);
+	        this.status = source["status"];
+	        this.error = source["error"];
+	    }
 	
-	export class WorkSession {
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupTarget {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    name: string;
+	    kind: string;
+	    endpoint: string;
+	    bucket: string;
+	    region: string;
+	    folder: string;
+	    username: string;
+	    password: string;
+	    passphrase: string;
+	    password_set: boolean;
+	    passphrase_set: boolean;
+	    interval_hours: number;
+	    keep_count: number;
+	    enabled: boolean;
+	    // Go type: time
+	    last_run_at?: any;
+	    // Go type: time
+	    last_backup_at?: any;
+	    last_error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupTarget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.name = source["name"];
+	        this.kind = source["kind"];
+	        this.endpoint = source["endpoint"];
+	        this.bucket = source["bucket"];
+	        this.region = source["region"];
+	        this.folder = source["folder"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.passphrase = source["passphrase"];
+	        this.password_set = source["password_set"];
+	        this.passphrase_set = source["passphrase_set"];
+	        this.interval_hours = source["interval_hours"];
+	        this.keep_count = source["keep_count"];
+	        this.enabled = source["enabled"];
+	        this.last_run_at = this.convertValues(source["last_run_at"], null);
+	        this.last_backup_at = this.convertValues(source["last_backup_at"], null);
+	        this.last_error = source["last_error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ClientContact {
+	    id: number;
+	    client_id: number;
+	    name: string;
+	    role: string;
+	    email: string;
+	    phone: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClientContact(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.client_id = source["client_id"];
+	        this.name = source["name"];
+	        this.role = source["role"];
+	        this.email = source["email"];
+	        this.phone = source["phone"];
+	    }
+	}
+	export class Client {
 	    id: number;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
 	    updated_at: any;
 	    deleted_at: gorm.DeletedAt;
-	    date: string;
-	    seconds: number;
-	    project_id: number;
+	    name: string;
+	    billing_address: string;
+	    notes: string;
+	    contacts: ClientContact[];
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkSession(source);
+	        return new Client(source);
 	    }
 	
 	    constructor(source: any = {}) {
@@ -264,9 +559,61 @@ export namespace main {
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
-	        this.date = source["date"];
-	        this.seconds = source["seconds"];
-	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.billing_address = source["billing_address"];
+	        this.notes = source["notes"];
+	        this.contacts = this.convertValues(source["contacts"], ClientContact);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ComplianceRules {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    enabled: boolean;
+	    max_daily_minutes: number;
+	    max_weekly_minutes: number;
+	    min_rest_minutes: number;
+	    break_after_minutes: number;
+	    min_break_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComplianceRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.enabled = source["enabled"];
+	        this.max_daily_minutes = source["max_daily_minutes"];
+	        this.max_weekly_minutes = source["max_weekly_minutes"];
+	        this.min_rest_minutes = source["min_rest_minutes"];
+	        this.break_after_minutes = source["break_after_minutes"];
+	        this.min_break_minutes = source["min_break_minutes"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -287,6 +634,1212 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class ComplianceViolation {
+	    rule: string;
+	    date: string;
+	    message: string;
+	    actual_seconds: number;
+	    limit_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComplianceViolation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule = source["rule"];
+	        this.date = source["date"];
+	        this.message = source["message"];
+	        this.actual_seconds = source["actual_seconds"];
+	        this.limit_seconds = source["limit_seconds"];
+	    }
+	}
+	export class DayOvertime {
+	    date: string;
+	    expected_seconds: number;
+	    absence_seconds: number;
+	    actual_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayOvertime(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.expected_seconds = source["expected_seconds"];
+	        this.absence_seconds = source["absence_seconds"];
+	        this.actual_seconds = source["actual_seconds"];
+	    }
+	}
+	export class EncryptionStatus {
+	    mode: string;
+	    locked: boolean;
+	    keyring_available: boolean;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.locked = source["locked"];
+	        this.keyring_available = source["keyring_available"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HoursDiscrepancy {
+	    project_id: number;
+	    date: string;
+	    hours_seconds: number;
+	    session_seconds: number;
+	    hours_entries: number;
+	    sessions: number;
+	    repairable: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HoursDiscrepancy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.date = source["date"];
+	        this.hours_seconds = source["hours_seconds"];
+	        this.session_seconds = source["session_seconds"];
+	        this.hours_entries = source["hours_entries"];
+	        this.sessions = source["sessions"];
+	        this.repairable = source["repairable"];
+	        this.locked = source["locked"];
+	    }
+	}
+	export class JournalState {
+	    can_undo: boolean;
+	    undo: string;
+	    can_redo: boolean;
+	    redo: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.can_undo = source["can_undo"];
+	        this.undo = source["undo"];
+	        this.can_redo = source["can_redo"];
+	        this.redo = source["redo"];
+	    }
+	}
+	export class LeaveAllowance {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    year: number;
+	    type: string;
+	    days: number;
+	    carry_over_days: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LeaveAllowance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.year = source["year"];
+	        this.type = source["type"];
+	        this.days = source["days"];
+	        this.carry_over_days = source["carry_over_days"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LeaveBalance {
+	    type: string;
+	    year: number;
+	    allowance_days: number;
+	    carry_over_days: number;
+	    used_days: number;
+	    remaining_days: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LeaveBalance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.year = source["year"];
+	        this.allowance_days = source["allowance_days"];
+	        this.carry_over_days = source["carry_over_days"];
+	        this.used_days = source["used_days"];
+	        this.remaining_days = source["remaining_days"];
+	    }
+	}
+	export class MailDelivery {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    report_run_id?: number;
+	    recipients: string;
+	    cc: string;
+	    subject: string;
+	    body: string;
+	    attachment: string;
+	    status: string;
+	    attempts: number;
+	    last_error: string;
+	    // Go type: time
+	    next_attempt_at: any;
+	    // Go type: time
+	    sent_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new MailDelivery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.report_run_id = source["report_run_id"];
+	        this.recipients = source["recipients"];
+	        this.cc = source["cc"];
+	        this.subject = source["subject"];
+	        this.body = source["body"];
+	        this.attachment = source["attachment"];
+	        this.status = source["status"];
+	        this.attempts = source["attempts"];
+	        this.last_error = source["last_error"];
+	        this.next_attempt_at = this.convertValues(source["next_attempt_at"], null);
+	        this.sent_at = this.convertValues(source["sent_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NewOrgRet {
+	    organization: Organization;
+	    project: Project;
+	
+	    static createFrom(source: any = {}) {
+	        return new NewOrgRet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.organization = this.convertValues(source["organization"], Organization);
+	        this.project = this.convertValues(source["project"], Project);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class OvertimeSummary {
+	    enabled: boolean;
+	    start: string;
+	    end: string;
+	    days: DayOvertime[];
+	    expected_seconds: number;
+	    absence_seconds: number;
+	    actual_seconds: number;
+	    difference_seconds: number;
+	    carry_over_seconds: number;
+	    balance_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OvertimeSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.days = this.convertValues(source["days"], DayOvertime);
+	        this.expected_seconds = source["expected_seconds"];
+	        this.absence_seconds = source["absence_seconds"];
+	        this.actual_seconds = source["actual_seconds"];
+	        this.difference_seconds = source["difference_seconds"];
+	        this.carry_over_seconds = source["carry_over_seconds"];
+	        this.balance_seconds = source["balance_seconds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ParallelTimer {
+	    organization: Organization;
+	    project: Project;
+	    task: Task;
+	    // Go type: time
+	    started_at: any;
+	    timeElapsed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParallelTimer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.organization = this.convertValues(source["organization"], Organization);
+	        this.project = this.convertValues(source["project"], Project);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.timeElapsed = source["timeElapsed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PeriodLock {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    kind: string;
+	    start_date: string;
+	    end_date: string;
+	    status: string;
+	    note: string;
+	    // Go type: time
+	    submitted_at?: any;
+	    // Go type: time
+	    approved_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PeriodLock(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.kind = source["kind"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.status = source["status"];
+	        this.note = source["note"];
+	        this.submitted_at = this.convertValues(source["submitted_at"], null);
+	        this.approved_at = this.convertValues(source["approved_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ReconciliationReport {
+	    // Go type: time
+	    checked_at: any;
+	    discrepancies: HoursDiscrepancy[];
+	    repaired: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconciliationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.checked_at = this.convertValues(source["checked_at"], null);
+	        this.discrepancies = this.convertValues(source["discrepancies"], HoursDiscrepancy);
+	        this.repaired = source["repaired"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RemoteBackup {
+	    name: string;
+	    // Go type: time
+	    created_at: any;
+	    size: number;
+	    sha256: string;
+	    // Go type: time
+	    verified_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteBackup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.size = source["size"];
+	        this.sha256 = source["sha256"];
+	        this.verified_at = this.convertValues(source["verified_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReportMailing {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    recipients: string;
+	    cc: string;
+	    subject_template: string;
+	    body_template: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportMailing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.recipients = source["recipients"];
+	        this.cc = source["cc"];
+	        this.subject_template = source["subject_template"];
+	        this.body_template = source["body_template"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReportRun {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    schedule_id: number;
+	    // Go type: time
+	    scheduled_for: any;
+	    // Go type: time
+	    finished_at: any;
+	    catch_up: boolean;
+	    status: string;
+	    output_path: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.schedule_id = source["schedule_id"];
+	        this.scheduled_for = this.convertValues(source["scheduled_for"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	        this.catch_up = source["catch_up"];
+	        this.status = source["status"];
+	        this.output_path = source["output_path"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReportSchedule {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    deleted_at: gorm.DeletedAt;
+	    organization_id: number;
+	    export_type: string;
+	    period: string;
+	    cron: string;
+	    enabled: boolean;
+	    email_report: boolean;
+	    // Go type: time
+	    last_run_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportSchedule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.organization_id = source["organization_id"];
+	        this.export_type = source["export_type"];
+	        this.period = source["period"];
+	        this.cron = source["cron"];
+	        this.enabled = source["enabled"];
+	        this.email_report = source["email_report"];
+	        this.last_run_at = this.convertValues(source["last_run_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReportTemplate {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    page_size: string;
+	    orientation: string;
+	    font_family: string;
+	    logo_path: string;
+	    header_text: string;
+	    footer_text: string;
+	    primary_color: string;
+	    accent_color: string;
+	    text_color: string;
+	    sections: string;
+	    page_numbers: boolean;
+	    signature_block: boolean;
+	    signature_label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.page_size = source["page_size"];
+	        this.orientation = source["orientation"];
+	        this.font_family = source["font_family"];
+	        this.logo_path = source["logo_path"];
+	        this.header_text = source["header_text"];
+	        this.footer_text = source["footer_text"];
+	        this.primary_color = source["primary_color"];
+	        this.accent_color = source["accent_color"];
+	        this.text_color = source["text_color"];
+	        this.sections = source["sections"];
+	        this.page_numbers = source["page_numbers"];
+	        this.signature_block = source["signature_block"];
+	        this.signature_label = source["signature_label"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RoundingPolicy {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    increment_minutes: number;
+	    mode: string;
+	    level: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoundingPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.increment_minutes = source["increment_minutes"];
+	        this.mode = source["mode"];
+	        this.level = source["level"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Settings {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    alert_time: number;
+	    app_theme: string;
+	    enable_color_on_dark: boolean;
+	    allow_parallel_timers: boolean;
+	    export_dir: string;
+	    monthly_export_template: string;
+	    yearly_export_template: string;
+	    export_conflict: string;
+	    export_use_save_dialog: boolean;
+	    smtp_host: string;
+	    smtp_port: number;
+	    smtp_security: string;
+	    smtp_username: string;
+	    smtp_password: string;
+	    smtp_from: string;
+	    smtp_password_set: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.alert_time = source["alert_time"];
+	        this.app_theme = source["app_theme"];
+	        this.enable_color_on_dark = source["enable_color_on_dark"];
+	        this.allow_parallel_timers = source["allow_parallel_timers"];
+	        this.export_dir = source["export_dir"];
+	        this.monthly_export_template = source["monthly_export_template"];
+	        this.yearly_export_template = source["yearly_export_template"];
+	        this.export_conflict = source["export_conflict"];
+	        this.export_use_save_dialog = source["export_use_save_dialog"];
+	        this.smtp_host = source["smtp_host"];
+	        this.smtp_port = source["smtp_port"];
+	        this.smtp_security = source["smtp_security"];
+	        this.smtp_username = source["smtp_username"];
+	        this.smtp_password = source["smtp_password"];
+	        this.smtp_from = source["smtp_from"];
+	        this.smtp_password_set = source["smtp_password_set"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncConfig {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    device_id: string;
+	    folder: string;
+	    interval_minutes: number;
+	    // Go type: time
+	    last_sync_at?: any;
+	    last_error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.device_id = source["device_id"];
+	        this.folder = source["folder"];
+	        this.interval_minutes = source["interval_minutes"];
+	        this.last_sync_at = this.convertValues(source["last_sync_at"], null);
+	        this.last_error = source["last_error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncReport {
+	    // Go type: time
+	    synced_at: any;
+	    exported: number;
+	    applied: number;
+	    ignored: number;
+	    skipped: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.synced_at = this.convertValues(source["synced_at"], null);
+	        this.exported = source["exported"];
+	        this.applied = source["applied"];
+	        this.ignored = source["ignored"];
+	        this.skipped = source["skipped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskTime {
+	    task_id: number;
+	    path: string;
+	    seconds: number;
+	    total_seconds: number;
+	    estimate_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskTime(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.path = source["path"];
+	        this.seconds = source["seconds"];
+	        this.total_seconds = source["total_seconds"];
+	        this.estimate_seconds = source["estimate_seconds"];
+	    }
+	}
+	export class TeamConfig {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    server_url: string;
+	    token: string;
+	    // Go type: time
+	    last_push_at?: any;
+	    last_error: string;
+	    pending: number;
+	    token_set: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.server_url = source["server_url"];
+	        this.token = source["token"];
+	        this.last_push_at = this.convertValues(source["last_push_at"], null);
+	        this.last_error = source["last_error"];
+	        this.pending = source["pending"];
+	        this.token_set = source["token_set"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TimerFrame {
+	    organization: Organization;
+	    project: Project;
+	    task: Task;
+	    // Go type: time
+	    switched_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimerFrame(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.organization = this.convertValues(source["organization"], Organization);
+	        this.project = this.convertValues(source["project"], Project);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.switched_at = this.convertValues(source["switched_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TimerOverlap {
+	    date: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    ended_at: any;
+	    seconds: number;
+	    projects: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TimerOverlap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.ended_at = this.convertValues(source["ended_at"], null);
+	        this.seconds = source["seconds"];
+	        this.projects = source["projects"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Trash {
+	    organizations: Organization[];
+	    projects: Project[];
+	    work_hours: WorkHours[];
+	    retention_days: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Trash(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.organizations = this.convertValues(source["organizations"], Organization);
+	        this.projects = this.convertValues(source["projects"], Project);
+	        this.work_hours = this.convertValues(source["work_hours"], WorkHours);
+	        this.retention_days = source["retention_days"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class WorkSchedule {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    organization_id: number;
+	    enabled: boolean;
+	    monday_minutes: number;
+	    tuesday_minutes: number;
+	    wednesday_minutes: number;
+	    thursday_minutes: number;
+	    friday_minutes: number;
+	    saturday_minutes: number;
+	    sunday_minutes: number;
+	    start_date: string;
+	    opening_balance_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkSchedule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.organization_id = source["organization_id"];
+	        this.enabled = source["enabled"];
+	        this.monday_minutes = source["monday_minutes"];
+	        this.tuesday_minutes = source["tuesday_minutes"];
+	        this.wednesday_minutes = source["wednesday_minutes"];
+	        this.thursday_minutes = source["thursday_minutes"];
+	        this.friday_minutes = source["friday_minutes"];
+	        this.saturday_minutes = source["saturday_minutes"];
+	        this.sunday_minutes = source["sunday_minutes"];
+	        this.start_date = source["start_date"];
+	        this.opening_balance_seconds = source["opening_balance_seconds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkSession {
+	    id: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    deleted_at: gorm.DeletedAt;
+	    uid: string;
+	    date: string;
+	    seconds: number;
+	    project_id: number;
+	    task_id?: number;
+	    parallel: boolean;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    ended_at: any;
+	    timezone: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], gorm.DeletedAt);
+	        this.uid = source["uid"];
+	        this.date = source["date"];
+	        this.seconds = source["seconds"];
+	        this.project_id = source["project_id"];
+	        this.task_id = source["task_id"];
+	        this.parallel = source["parallel"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.ended_at = this.convertValues(source["ended_at"], null);
+	        this.timezone = source["timezone"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Workspace {
+	    name: string;
+	    dir: string;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Workspace(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.dir = source["dir"];
+	        this.active = source["active"];
+	    }
+	}
 
 }
 
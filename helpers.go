@@ -14,33 +14,90 @@ import (
 	"gorm.io/gorm"
 )
 
+// dataDirFlag is the --data-dir argument, see parseDataDirArgs
+var dataDirFlag string
+
+// portableFlag is the --portable argument, portable mode keeps the data next to the executable
+var portableFlag bool
+
+// getSaveDir returns the directory the app keeps its data in: the --data-dir argument or WORKTRACKER_DATA_DIR,
+// the data folder next to the executable in portable mode, otherwise the user's application data directory.
+// Each workspace has its own directory within it, see workspace.go
 func getSaveDir(environment string) (string, error) {
-	var dataDir string
-	switch runtime.GOOS {
-	case "windows":
-		dataDir = os.Getenv("APPDATA")
-	case "darwin":
-		dataDir = filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
-	default: // Unix-like system
-		dataDir = os.Getenv("XDG_DATA_HOME")
-		if dataDir == "" {
-			dataDir = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	saveDir := dataDirFlag
+	if saveDir == "" {
+		saveDir = os.Getenv("WORKTRACKER_DATA_DIR")
+	}
+	if saveDir == "" && isPortable() {
+		executable, err := os.Executable()
+		if err != nil {
+			return "", err
 		}
+		saveDir = filepath.Join(filepath.Dir(executable), "data")
 	}
 
-	saveDir := filepath.Join(dataDir, "Go-Work-Tracker")
+	if saveDir == "" {
+		var dataDir string
+		switch runtime.GOOS {
+		case "windows":
+			dataDir = os.Getenv("APPDATA")
+		case "darwin":
+			dataDir = filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
+		default: // Unix-like system
+			dataDir = os.Getenv("XDG_DATA_HOME")
+			if dataDir == "" {
+				dataDir = filepath.Join(os.Getenv("HOME"), ".local", "share")
+			}
+		}
 
-	if environment == "development" {
-		Logger.Println("Running in development mode")
-		saveDir = filepath.Join(saveDir, "dev")
+		saveDir = filepath.Join(dataDir, "Go-Work-Tracker")
+
+		if environment == "development" {
+			Logger.Println("Running in development mode")
+			saveDir = filepath.Join(saveDir, "dev")
+		}
 	}
 
-	if _, err := os.Stat(saveDir); os.IsNotExist(err) {
-		os.Mkdir(saveDir, 0755)
+	saveDir, err := filepath.Abs(saveDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return "", err
 	}
 	return saveDir, nil
 }
 
+// isPortable reports whether portable mode is on, with --portable or a file named "portable" next to the executable
+func isPortable() bool {
+	if portableFlag {
+		return true
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(filepath.Dir(executable), "portable"))
+	return err == nil
+}
+
+// parseDataDirArgs reads --data-dir (or --data-dir=) and --portable from the command line, other arguments are left alone
+func parseDataDirArgs(args []string) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--portable" || arg == "-portable":
+			portableFlag = true
+		case (arg == "--data-dir" || arg == "-data-dir") && i+1 < len(args):
+			dataDirFlag = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--data-dir="):
+			dataDirFlag = strings.TrimPrefix(arg, "--data-dir=")
+		case strings.HasPrefix(arg, "-data-dir="):
+			dataDirFlag = strings.TrimPrefix(arg, "-data-dir=")
+		}
+	}
+}
+
 func readVersionConfig(WailsConfigFile []byte) (string, error) {
 	var wailsConfig WailsConfig
 	err := json.Unmarshal(WailsConfigFile, &wailsConfig)
@@ -222,6 +279,13 @@ type MonthlyTotals struct {
 	Dates         []string
 	DateSumTotals map[string]int
 	WeekSumTotals map[int]int
+	TaskTotals    []TaskTotal
+	Overlaps      []TimerOverlap
+	Rounded       RoundedTotals
+	Overtime      OvertimeSummary
+	Absences      []Absence
+	Compliance    []ComplianceViolation
+	Locks         []PeriodLock
 }
 
 func (a *App) GetWeekOfMonth(year int, month time.Month, day int) int {
@@ -279,19 +343,13 @@ func getWeekRanges(year int, month time.Month) map[int]string {
 	return weekRanges
 }
 
-func (a *App) getMonthlyTotals(organizationName string, year int, month time.Month) (MonthlyTotals, error) {
-	// Find the organization
-	var organization Organization
-	if err := a.db.Where(&Organization{Name: organizationName}).First(&organization).Error; err != nil {
-		Logger.Println(err)
-		return MonthlyTotals{}, err
-	}
-
+func (a *App) getMonthlyTotals(scope reportScope, year int, month time.Month) (MonthlyTotals, error) {
 	rows, err := a.db.Table("work_hours").
-		Select("date, projects.name, seconds").
+		Select("date, "+scope.label+", seconds").
 		Joins("JOIN projects ON projects.id = work_hours.project_id").
-		Where("projects.deleted_at IS NULL"). // Ignore deleted projects
-		Where("strftime('%Y-%m', date) = ? AND projects.organization_id = ?", fmt.Sprintf("%04d-%02d", year, month), organization.ID).
+		Where("projects.deleted_at IS NULL AND work_hours.deleted_at IS NULL"). // Ignore deleted projects and merged duplicates
+		Scopes(scope.filter).
+		Where("strftime('%Y-%m', date) = ?", fmt.Sprintf("%04d-%02d", year, month)).
 		Order("date").
 		Rows()
 	if err != nil {
@@ -354,6 +412,37 @@ func (a *App) getMonthlyTotals(organizationName string, year int, month time.Mon
 	sort.Slice(projectTotals, func(i, j int) bool {
 		return projectTotals[i].Seconds > projectTotals[j].Seconds
 	})
+
+	period := fmt.Sprintf("%04d-%02d", year, month)
+	taskTotals, err := a.getTaskTotals(scope, period+"-01", period+"-31")
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	overlaps, err := a.getOverlaps(scope, period+"-01", period+"-31")
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	rounded, err := a.roundMonth(scope, year, month, monthlyTotals)
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	lastOfMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	overtime, err := a.scopeOvertime(scope, period+"-01", lastOfMonth)
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	absences, err := a.scopeAbsences(scope, period+"-01", lastOfMonth)
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	compliance, err := a.scopeCompliance(scope, period+"-01", lastOfMonth)
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
+	locks, err := a.scopeLocks(scope, period+"-01", lastOfMonth)
+	if err != nil {
+		return MonthlyTotals{}, err
+	}
 	return MonthlyTotals{
 		DailyTotals:   dailyTotals,
 		WeeklyTotals:  weeklyTotals,
@@ -362,6 +451,13 @@ func (a *App) getMonthlyTotals(organizationName string, year int, month time.Mon
 		Dates:         dates,
 		DateSumTotals: dateSumTotals,
 		WeekSumTotals: weekSumTotals,
+		TaskTotals:    taskTotals,
+		Overlaps:      overlaps,
+		Rounded:       rounded,
+		Overtime:      overtime,
+		Absences:      absences,
+		Compliance:    compliance,
+		Locks:         locks,
 	}, nil
 }
 
@@ -370,21 +466,20 @@ type YearlyTotals struct {
 	MonthSumTotals map[string]int
 	ProjectTotals  []ProjectTotal
 	YearlyTotal    int
+	Rounded        RoundedTotals
+	Overtime       OvertimeSummary
+	Leave          []LeaveBalance
+	Compliance     []ComplianceViolation
+	Locks          []PeriodLock
 }
 
-func (a *App) getYearlyTotals(organizationName string, year int) (YearlyTotals, error) {
-	// Find the organization
-	var organization Organization
-	if err := a.db.Where(&Organization{Name: organizationName}).First(&organization).Error; err != nil {
-		Logger.Println(err)
-		return YearlyTotals{}, err
-	}
-
+func (a *App) getYearlyTotals(scope reportScope, year int) (YearlyTotals, error) {
 	rows, err := a.db.Table("work_hours").
-		Select("date, projects.name, seconds").
+		Select("date, "+scope.label+", seconds").
 		Joins("JOIN projects ON projects.id = work_hours.project_id").
-		Where("projects.deleted_at IS NULL"). // Ignore deleted projects
-		Where("strftime('%Y', date) = ? AND projects.organization_id = ?", fmt.Sprintf("%04d", year), organization.ID).
+		Where("projects.deleted_at IS NULL AND work_hours.deleted_at IS NULL"). // Ignore deleted projects and merged duplicates
+		Scopes(scope.filter).
+		Where("strftime('%Y', date) = ?", fmt.Sprintf("%04d", year)).
 		Order("date").
 		Rows()
 	if err != nil {
@@ -438,31 +533,82 @@ func (a *App) getYearlyTotals(organizationName string, year int) (YearlyTotals,
 	sort.Slice(projectTotals, func(i, j int) bool {
 		return projectTotals[i].Seconds > projectTotals[j].Seconds
 	})
+	rounded, err := a.roundYear(scope, year, monthlyTotals)
+	if err != nil {
+		return YearlyTotals{}, err
+	}
+	overtime, err := a.scopeOvertime(scope, fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year))
+	if err != nil {
+		return YearlyTotals{}, err
+	}
+	leave, err := a.scopeLeave(scope, year)
+	if err != nil {
+		return YearlyTotals{}, err
+	}
+	compliance, err := a.scopeCompliance(scope, fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year))
+	if err != nil {
+		return YearlyTotals{}, err
+	}
+	locks, err := a.scopeLocks(scope, fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year))
+	if err != nil {
+		return YearlyTotals{}, err
+	}
 	return YearlyTotals{
 		MonthlyTotals:  monthlyTotals,
 		MonthSumTotals: monthSumTotals,
 		ProjectTotals:  projectTotals,
 		YearlyTotal:    yearlyTotal,
+		Rounded:        rounded,
+		Overtime:       overtime,
+		Leave:          leave,
+		Compliance:     compliance,
+		Locks:          locks,
 	}, nil
 }
 
 func (a *App) ExportByMonth(exportType ExportType, organization string, year int, month time.Month) (string, error) {
 	Logger.Println("Exporting by month...", exportType, organization, year, month)
+	return a.exportByMonth(exportType, organization, year, month, true)
+}
+
+func (a *App) ExportByYear(exportType ExportType, organization string, year int) (string, error) {
+	Logger.Println("Exporting by year...", exportType, organization, year)
+	return a.exportByYear(exportType, organization, year, true)
+}
+
+// exportByMonth writes a monthly export. Interactive exports may show a save dialog and
+// open or copy the result, background exports never touch the UI
+func (a *App) exportByMonth(exportType ExportType, organization string, year int, month time.Month, interactive bool) (string, error) {
+	scope, err := a.organizationScope(organization)
+	if err != nil {
+		return "", err
+	}
+	return a.exportScopeByMonth(exportType, scope, year, month, interactive)
+}
+
+func (a *App) exportByYear(exportType ExportType, organization string, year int, interactive bool) (string, error) {
+	scope, err := a.organizationScope(organization)
+	if err != nil {
+		return "", err
+	}
+	return a.exportScopeByYear(exportType, scope, year, interactive)
+}
+
+func (a *App) exportScopeByMonth(exportType ExportType, scope reportScope, year int, month time.Month, interactive bool) (string, error) {
 	if exportType == CSV {
-		return a.exportCSVByMonth(organization, year, month)
+		return a.exportCSVByMonth(scope, year, month, interactive)
 	} else if exportType == PDF {
-		return a.exportPDFByMonth(organization, year, month)
+		return a.exportPDFByMonth(scope, year, month, interactive)
 	} else {
 		return "", fmt.Errorf("invalid export type")
 	}
 }
 
-func (a *App) ExportByYear(exportType ExportType, organization string, year int) (string, error) {
-	Logger.Println("Exporting by year...", exportType, organization, year)
+func (a *App) exportScopeByYear(exportType ExportType, scope reportScope, year int, interactive bool) (string, error) {
 	if exportType == CSV {
-		return a.exportCSVByYear(organization, year)
+		return a.exportCSVByYear(scope, year, interactive)
 	} else if exportType == PDF {
-		return a.exportPDFByYear(organization, year)
+		return a.exportPDFByYear(scope, year, interactive)
 	} else {
 		return "", fmt.Errorf("invalid export type")
 	}
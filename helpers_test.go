@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Skips the upgrade of databases from before 0.6.0, test databases start empty
+	os.Setenv("BUILDING", "true")
+	os.Exit(m.Run())
+}
+
+// newTestApp returns an app with an empty database in a temporary directory
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	dir := t.TempDir()
+	a := &App{saveDir: dir, workspaceName: defaultWorkspace, dbDir: dir}
+	if err := a.openDatabase(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(a.closeDatabase)
+	return a
+}
+
+// newEncryptedTestApp returns an app whose database is encrypted with the passphrase "correct horse",
+// credentials are only stored in such a database
+func newEncryptedTestApp(t *testing.T) *App {
+	t.Helper()
+	a := newTestApp(t)
+	if _, err := a.SetEncryption(EncryptionPassphrase, "correct horse", ""); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s is not available: %v", name, err)
+	}
+	return loc
+}
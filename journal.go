@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+// Soft deleted rows stay in the trash this long before cleanupSoftDeletedRecords purges them
+const trashRetentionDays = 30
+
+// Rows deleted by the same operation are deleted within this window of each other
+const deletedTogetherWindow = time.Minute
+
+// journalRows lists row IDs by table
+type journalRows struct {
+	Organizations []uint `json:"organizations,omitempty"`
+	Projects      []uint `json:"projects,omitempty"`
+	WorkHours     []uint `json:"work_hours,omitempty"`
+	WorkSessions  []uint `json:"work_sessions,omitempty"`
+}
+
+// hoursAdjustment is a number of seconds added to a project's WorkHours for a date
+type hoursAdjustment struct {
+	ProjectID uint   `json:"project_id"`
+	Date      string `json:"date"`
+	Seconds   int    `json:"seconds"`
+}
+
+type sessionTransfer struct {
+	WorkSessionID uint `json:"work_session_id"`
+	FromProjectID uint `json:"from_project_id"`
+	ToProjectID   uint `json:"to_project_id"`
+	// Tasks belong to a project, the transferred session loses its task until it is undone
+	FromTaskID *uint `json:"from_task_id,omitempty"`
+}
+
+// journalChanges describes an operation in a way that can be applied in both directions
+type journalChanges struct {
+	Deleted     journalRows       `json:"deleted"`
+	Restored    journalRows       `json:"restored"`
+	Adjustments []hoursAdjustment `json:"adjustments,omitempty"`
+	Transfer    *sessionTransfer  `json:"transfer,omitempty"`
+}
+
+// JournalEntry records an operation so it can be undone and redone
+type JournalEntry struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Description string    `json:"description"`
+	Changes     string    `json:"changes"` // JSON encoded journalChanges
+	Undone      bool      `gorm:"index" json:"undone"`
+}
+
+// JournalState tells the frontend what Undo and Redo would do
+type JournalState struct {
+	CanUndo bool   `json:"can_undo"`
+	Undo    string `json:"undo"`
+	CanRedo bool   `json:"can_redo"`
+	Redo    string `json:"redo"`
+}
+
+// Trash lists the soft deleted rows that can still be restored
+type Trash struct {
+	Organizations []Organization `json:"organizations"`
+	Projects      []Project      `json:"projects"`
+	WorkHours     []WorkHours    `json:"work_hours"`
+	RetentionDays int            `json:"retention_days"`
+}
+
+func softDeleteRows(tx *gorm.DB, source AuditSource, rows journalRows) error {
+	if err := deleteAudited[WorkSession](tx, source, rows.WorkSessions); err != nil {
+		return err
+	}
+	if err := deleteAudited[WorkHours](tx, source, rows.WorkHours); err != nil {
+		return err
+	}
+	if err := deleteAudited[Project](tx, source, rows.Projects); err != nil {
+		return err
+	}
+	return deleteAudited[Organization](tx, source, rows.Organizations)
+}
+
+func restoreRows(tx *gorm.DB, source AuditSource, rows journalRows) error {
+	if err := restoreAudited[Organization](tx, source, rows.Organizations); err != nil {
+		return err
+	}
+	if err := restoreAudited[Project](tx, source, rows.Projects); err != nil {
+		return err
+	}
+	if err := restoreAudited[WorkHours](tx, source, rows.WorkHours); err != nil {
+		return err
+	}
+	return restoreAudited[WorkSession](tx, source, rows.WorkSessions)
+}
+
+func adjustHours(tx *gorm.DB, source AuditSource, adjustment hoursAdjustment, sign int) error {
+	return addWorkHours(tx, source, adjustment.ProjectID, adjustment.Date, sign*adjustment.Seconds)
+}
+
+// apply performs the changes, or reverts them when forward is false
+func (c journalChanges) apply(tx *gorm.DB, source AuditSource, forward bool) error {
+	days, err := c.projectDays(tx)
+	if err != nil {
+		return err
+	}
+	if err := ensureUnlocked(tx, days...); err != nil {
+		return err
+	}
+
+	deleted, restored, sign := c.Deleted, c.Restored, 1
+	if !forward {
+		deleted, restored, sign = c.Restored, c.Deleted, -1
+	}
+
+	if err := restoreRows(tx, source, restored); err != nil {
+		return err
+	}
+	if err := softDeleteRows(tx, source, deleted); err != nil {
+		return err
+	}
+	for _, adjustment := range c.Adjustments {
+		if err := adjustHours(tx, source, adjustment, sign); err != nil {
+			return err
+		}
+	}
+	if c.Transfer != nil {
+		projectID, taskID := c.Transfer.ToProjectID, (*uint)(nil)
+		if !forward {
+			projectID, taskID = c.Transfer.FromProjectID, c.Transfer.FromTaskID
+		}
+		var workSession WorkSession
+		if err := tx.Unscoped().Where("id = ?", c.Transfer.WorkSessionID).First(&workSession).Error; err != nil {
+			return err
+		}
+		before := workSession
+		workSession.ProjectID = projectID
+		workSession.TaskID = taskID
+		if err := tx.Unscoped().Save(&workSession).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, source, AuditUpdate, before, workSession); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// perform applies the changes and records them in the journal, discarding anything that could be redone
+func (a *App) perform(description string, source AuditSource, changes journalChanges) error {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := changes.apply(tx, source, true); err != nil {
+			return err
+		}
+		if err := tx.Where("undone = ?", true).Delete(&JournalEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&JournalEntry{Description: description, Changes: string(data)}).Error
+	})
+	if err != nil {
+		return err
+	}
+	a.emitJournalState()
+	return nil
+}
+
+// replay undoes or redoes a journal entry
+func (a *App) replay(entry JournalEntry, forward bool) error {
+	var changes journalChanges
+	if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+		return fmt.Errorf("journal entry %d is corrupt: %w", entry.ID, err)
+	}
+
+	source := AuditUndo
+	if forward {
+		source = AuditRedo
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := changes.apply(tx, source, forward); err != nil {
+			return err
+		}
+		return tx.Model(&entry).Update("undone", !forward).Error
+	})
+	if err != nil {
+		return err
+	}
+	a.emitJournalState()
+	return nil
+}
+
+func (a *App) emitJournalState() {
+	if a.ctx == nil {
+		return
+	}
+	state, err := a.GetJournalState()
+	if err != nil {
+		Logger.Println(err)
+		return
+	}
+	runtime.EventsEmit(a.ctx, "journal-updated", state)
+}
+
+// lastApplied returns the entry Undo would revert
+func (a *App) lastApplied() (entry JournalEntry, found bool, err error) {
+	err = a.db.Where("undone = ?", false).Order("id DESC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, false, nil
+	}
+	return entry, err == nil, err
+}
+
+// firstUndone returns the entry Redo would apply again
+func (a *App) firstUndone() (entry JournalEntry, found bool, err error) {
+	err = a.db.Where("undone = ?", true).Order("id ASC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, false, nil
+	}
+	return entry, err == nil, err
+}
+
+// GetJournalState returns whether there is anything to undo or redo
+func (a *App) GetJournalState() (JournalState, error) {
+	var state JournalState
+	undo, found, err := a.lastApplied()
+	if err != nil {
+		return JournalState{}, err
+	}
+	state.CanUndo, state.Undo = found, undo.Description
+
+	redo, found, err := a.firstUndone()
+	if err != nil {
+		return JournalState{}, err
+	}
+	state.CanRedo, state.Redo = found, redo.Description
+	return state, nil
+}
+
+// Undo reverts the most recent operation
+func (a *App) Undo() (JournalState, error) {
+	entry, found, err := a.lastApplied()
+	if err != nil {
+		return JournalState{}, err
+	}
+	if !found {
+		return JournalState{}, conflictError("nothing to undo")
+	}
+	if err := a.replay(entry, false); err != nil {
+		return JournalState{}, err
+	}
+	return a.GetJournalState()
+}
+
+// Redo applies the most recently undone operation again
+func (a *App) Redo() (JournalState, error) {
+	entry, found, err := a.firstUndone()
+	if err != nil {
+		return JournalState{}, err
+	}
+	if !found {
+		return JournalState{}, conflictError("nothing to redo")
+	}
+	if err := a.replay(entry, true); err != nil {
+		return JournalState{}, err
+	}
+	return a.GetJournalState()
+}
+
+// deletedTogether reports whether a row was deleted by the same operation as its parent
+func deletedTogether(child, parent gorm.DeletedAt) bool {
+	if !child.Valid || !parent.Valid {
+		return false
+	}
+	diff := parent.Time.Sub(child.Time)
+	return diff >= -deletedTogetherWindow && diff <= deletedTogetherWindow
+}
+
+// deletedHours returns the IDs of the project's hours deleted together with it
+func deletedHours(db *gorm.DB, project Project) ([]uint, error) {
+	var workHours []WorkHours
+	err := db.Unscoped().
+		Where("project_id = ? AND deleted_at IS NOT NULL", project.ID).
+		Find(&workHours).Error
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, hours := range workHours {
+		if deletedTogether(hours.DeletedAt, project.DeletedAt) {
+			ids = append(ids, hours.ID)
+		}
+	}
+	return ids, nil
+}
+
+// GetTrash returns the deleted organizations, projects and hours that haven't been purged yet
+func (a *App) GetTrash() (Trash, error) {
+	trash := Trash{RetentionDays: trashRetentionDays}
+	if err := a.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&trash.Organizations).Error; err != nil {
+		return Trash{}, err
+	}
+	if err := a.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&trash.Projects).Error; err != nil {
+		return Trash{}, err
+	}
+	if err := a.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&trash.WorkHours).Error; err != nil {
+		return Trash{}, err
+	}
+	return trash, nil
+}
+
+// RestoreOrganization restores a deleted organization with the projects and hours deleted along with it
+func (a *App) RestoreOrganization(organizationID uint) error {
+	var organization Organization
+	err := a.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", organizationID).First(&organization).Error
+	if err != nil {
+		return err
+	}
+
+	changes := journalChanges{Restored: journalRows{Organizations: []uint{organization.ID}}}
+	var projects []Project
+	err = a.db.Unscoped().Where("organization_id = ? AND deleted_at IS NOT NULL", organization.ID).Find(&projects).Error
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if !deletedTogether(project.DeletedAt, organization.DeletedAt) {
+			continue
+		}
+		hours, err := deletedHours(a.db, project)
+		if err != nil {
+			return err
+		}
+		changes.Restored.Projects = append(changes.Restored.Projects, project.ID)
+		changes.Restored.WorkHours = append(changes.Restored.WorkHours, hours...)
+	}
+
+	return a.perform(fmt.Sprintf("Restore organization %s", organization.Name), AuditManual, changes)
+}
+
+// RestoreProject restores a deleted project with the hours deleted along with it
+func (a *App) RestoreProject(projectID uint) error {
+	var project Project
+	if err := a.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", projectID).First(&project).Error; err != nil {
+		return err
+	}
+	if _, err := a.getOrganization(project.OrganizationID); err != nil {
+		return conflictError("the project's organization is deleted, restore it first")
+	}
+
+	hours, err := deletedHours(a.db, project)
+	if err != nil {
+		return err
+	}
+	changes := journalChanges{Restored: journalRows{Projects: []uint{project.ID}, WorkHours: hours}}
+	return a.perform(fmt.Sprintf("Restore project %s", project.Name), AuditManual, changes)
+}
+
+// RestoreWorkHours restores a single deleted hours entry
+func (a *App) RestoreWorkHours(workHoursID uint) error {
+	var workHours WorkHours
+	if err := a.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", workHoursID).First(&workHours).Error; err != nil {
+		return err
+	}
+	if _, err := a.getProject(workHours.ProjectID); err != nil {
+		return conflictError("the hours' project is deleted, restore it first")
+	}
+
+	changes := journalChanges{Restored: journalRows{WorkHours: []uint{workHours.ID}}}
+	return a.perform(fmt.Sprintf("Restore hours for %s", workHours.Date), AuditManual, changes)
+}
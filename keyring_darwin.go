@@ -0,0 +1,58 @@
+//go:build darwin
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The database key is a generic password in the login keychain, one per workspace (see keyring_unix.go)
+
+const keyringService = "Go Work Tracker"
+
+func keyringAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+func keyringLookup(account string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w").Output()
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+// keyringSet runs security in interactive mode and sends the command on stdin, the key would be visible to
+// other processes (ps) as a command line argument
+func keyringStore(account string, key []byte) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(keyringService), securityQuote(account), hex.EncodeToString(key)))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return err
+	}
+	// Interactive mode exits with 0 when a command fails, so the entry is read back
+	if stored, err := keyringLookup(account); err != nil || !bytes.Equal(stored, key) {
+		return fmt.Errorf("the key wasn't stored: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for security's interactive mode
+func securityQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func keyringClear(account string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account).Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil // there was no key
+	}
+	return err
+}
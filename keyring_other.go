@@ -0,0 +1,45 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os/exec"
+	"strings"
+)
+
+// The database key is kept by the Secret Service (GNOME Keyring, KWallet) through libsecret's secret-tool,
+// one per workspace (see keyring_unix.go)
+
+const keyringService = "go-work-tracker"
+
+func keyringAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func keyringLookup(account string) ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "database", account).Output()
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func keyringStore(account string, key []byte) error {
+	cmd := exec.Command("secret-tool", "store", "--label=Go Work Tracker database key", "service", keyringService, "database", account)
+	cmd.Stdin = bytes.NewBufferString(hex.EncodeToString(key))
+	return cmd.Run()
+}
+
+func keyringClear(account string) error {
+	if !keyringAvailable() {
+		return nil
+	}
+	err := exec.Command("secret-tool", "clear", "service", keyringService, "database", account).Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil // there was no key
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// On macOS and Linux the keyring entry of a workspace is named after an ID kept in the workspace's folder, so it
+// still matches when the folder is moved or opened with another --data-dir. Workspaces encrypted before the ID
+// existed named their entry after the folder's path, such an entry is moved to the ID when it is read
+
+const keyringIDFileName = "keyring-id"
+
+// keyringAccount returns the ID of a workspace's keyring entry, creating it on first use
+func keyringAccount(dbDir string) (string, error) {
+	path := filepath.Join(dbDir, keyringIDFileName)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	id := newUID()
+	return id, writeFileAtomic(path, []byte(id))
+}
+
+func keyringGet(dbDir string) ([]byte, error) {
+	account, err := keyringAccount(dbDir)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keyringLookup(account)
+	if err == nil {
+		return key, nil
+	}
+	key, legacyErr := keyringLookup(dbDir)
+	if legacyErr != nil {
+		return nil, err
+	}
+	if err := keyringStore(account, key); err != nil {
+		Logger.Println("Failed to move the database key in the keyring:", err)
+	} else if err := keyringClear(dbDir); err != nil {
+		Logger.Println("Failed to remove the database key stored under the workspace path:", err)
+	}
+	return key, nil
+}
+
+func keyringSet(dbDir string, key []byte) error {
+	account, err := keyringAccount(dbDir)
+	if err != nil {
+		return err
+	}
+	return keyringStore(account, key)
+}
+
+// keyringDelete removes the key of a workspace, under its ID and under its path
+func keyringDelete(dbDir string) error {
+	if data, err := os.ReadFile(filepath.Join(dbDir, keyringIDFileName)); err == nil {
+		if err := keyringClear(strings.TrimSpace(string(data))); err != nil {
+			return err
+		}
+	}
+	return keyringClear(dbDir)
+}
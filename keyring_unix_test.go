@@ -0,0 +1,145 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeSecretTool puts a secret-tool on the PATH that keeps each entry in a file of dir named after its
+// account (the last argument), it refuses to store while dir holds a file named "fail"
+func fakeSecretTool(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "darwin" {
+		t.Skip("the keyring of this system isn't secret-tool")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+eval account=\${$#}
+entry="` + dir + `/entry-$(echo "$account" | tr / _)"
+case "$1" in
+store) [ -f "` + dir + `/fail" ] && exit 1; cat > "$entry" ;;
+lookup) cat "$entry" 2>/dev/null || exit 1 ;;
+clear) rm -f "$entry" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestSetEncryptionKeyring(t *testing.T) {
+	keyringDir := fakeSecretTool(t)
+	a := newTestApp(t)
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A keyring that refuses the key leaves the database as it was
+	if err := os.WriteFile(filepath.Join(keyringDir, "fail"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetEncryption(EncryptionKeyring, "", ""); err == nil {
+		t.Fatal("encrypted without a key in the keyring")
+	}
+	if plain, encrypted := dbFiles(t, a.dbDir); !plain || encrypted || a.store != nil {
+		t.Fatalf("after the failed switch: plain %v, encrypted %v", plain, encrypted)
+	}
+	if _, err := a.SetEncryption(EncryptionPassphrase, "correct horse", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetEncryption(EncryptionKeyring, "", "correct horse"); err == nil {
+		t.Fatal("switched to a key that isn't in the keyring")
+	}
+	a.closeDatabase()
+	if err := a.Unlock("correct horse"); err != nil {
+		t.Fatalf("the passphrase no longer opens the database after the failed switch: %v", err)
+	}
+
+	// With a working keyring the database opens without a passphrase
+	if err := os.Remove(filepath.Join(keyringDir, "fail")); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := a.SetEncryption(EncryptionKeyring, "", "correct horse"); err != nil || status.Mode != EncryptionKeyring {
+		t.Fatalf("switching to the keyring: %+v, %v", status, err)
+	}
+	a.closeDatabase()
+	if err := a.openDatabase(""); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if a.db.Model(&Organization{}).Count(&count); count != 1 {
+		t.Errorf("%d organizations, want 1", count)
+	}
+
+	// Decrypting removes the key from the keyring
+	if _, err := a.SetEncryption(EncryptionNone, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if entries := keyringEntries(t, keyringDir); len(entries) != 0 {
+		t.Errorf("the key is still in the keyring: %v", entries)
+	}
+}
+
+// keyringEntries returns the entries kept by fakeSecretTool
+func keyringEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := filepath.Glob(filepath.Join(dir, "entry-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestKeyringFollowsTheWorkspace(t *testing.T) {
+	keyringDir := fakeSecretTool(t)
+	a := newTestApp(t)
+	if _, err := a.SetEncryption(EncryptionKeyring, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	a.closeDatabase()
+
+	// Moving the folder, or opening it with another --data-dir, keeps the key
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(a.dbDir, moved); err != nil {
+		t.Fatal(err)
+	}
+	a.saveDir, a.dbDir = moved, moved
+	if err := a.openDatabase(""); err != nil {
+		t.Fatalf("opening the moved workspace: %v", err)
+	}
+	a.closeDatabase()
+
+	// An entry named after the path, from before the ID, is moved to the ID
+	account, err := keyringAccount(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keyringLookup(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyringClear(account); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(moved, keyringIDFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyringStore(moved, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.openDatabase(""); err != nil {
+		t.Fatalf("opening with the entry named after the path: %v", err)
+	}
+	if _, err := keyringLookup(moved); err == nil {
+		t.Error("the entry named after the path is still there")
+	}
+	if entries := keyringEntries(t, keyringDir); len(entries) != 1 {
+		t.Errorf("keyring entries %v, want only the one of the ID", entries)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// The database key is kept next to the database, protected with DPAPI so only the same Windows user can read it
+
+const keyringFileName = "worktracker.key"
+
+func keyringAvailable() bool {
+	return true
+}
+
+func keyringGet(dbDir string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dbDir, keyringFileName))
+	if err != nil {
+		return nil, err
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte{}, unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+func keyringSet(dbDir string, key []byte) error {
+	in := windows.DataBlob{Size: uint32(len(key)), Data: &key[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return writeFileAtomic(filepath.Join(dbDir, keyringFileName), unsafe.Slice(out.Data, out.Size))
+}
+
+func keyringDelete(dbDir string) error {
+	err := os.Remove(filepath.Join(dbDir, keyringFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
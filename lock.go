@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PeriodKind string
+
+const (
+	LockWeek  PeriodKind = "week"
+	LockMonth PeriodKind = "month"
+)
+
+// PeriodStatus is how far a locked period got, every status keeps the period locked
+type PeriodStatus string
+
+const (
+	PeriodLocked    PeriodStatus = "locked"
+	PeriodSubmitted PeriodStatus = "submitted"
+	PeriodApproved  PeriodStatus = "approved"
+)
+
+// PeriodLock freezes the tracked time of an organization's week or month, e.g. once it is billed.
+// Hours, sessions and absences dated within the period can't be changed until it is unlocked
+type PeriodLock struct {
+	ID             uint         `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	OrganizationID uint         `gorm:"index" json:"organization_id"`
+	Kind           PeriodKind   `json:"kind"`
+	StartDate      string       `gorm:"index" json:"start_date"`
+	EndDate        string       `json:"end_date"` // inclusive
+	Status         PeriodStatus `json:"status"`
+	Note           string       `json:"note"`
+	SubmittedAt    *time.Time   `json:"submitted_at"`
+	ApprovedAt     *time.Time   `json:"approved_at"`
+}
+
+func (l PeriodLock) auditInfo() (string, uint, uint, string) {
+	return "period_lock", l.ID, 0, l.StartDate
+}
+
+var periodStatusNames = map[PeriodStatus]string{
+	PeriodLocked:    "Locked",
+	PeriodSubmitted: "Submitted",
+	PeriodApproved:  "Approved",
+}
+
+// periodTransitions lists the statuses a lock can move to, approved periods have to be reopened before unlocking
+var periodTransitions = map[PeriodStatus][]PeriodStatus{
+	PeriodLocked:    {PeriodSubmitted},
+	PeriodSubmitted: {PeriodLocked, PeriodApproved},
+	PeriodApproved:  {PeriodSubmitted},
+}
+
+// periodBounds returns the first and last day of the week (Monday to Sunday) or month containing the date
+func periodBounds(kind PeriodKind, date string) (string, string, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid date %q", date)
+	}
+	switch kind {
+	case LockWeek:
+		start := weekStart(date)
+		monday, _ := time.Parse("2006-01-02", start)
+		return start, monday.AddDate(0, 0, 6).Format("2006-01-02"), nil
+	case LockMonth:
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02"), nil
+	default:
+		return "", "", fmt.Errorf("invalid period %q", kind)
+	}
+}
+
+func (l PeriodLock) covers(date string) bool {
+	return l.StartDate <= date && date <= l.EndDate
+}
+
+// label names the period, e.g. "October 2026" or "Week of 2026-10-12"
+func (l PeriodLock) label() string {
+	if l.Kind == LockMonth {
+		start, _ := time.Parse("2006-01-02", l.StartDate)
+		return start.Format("January 2006")
+	}
+	return "Week of " + l.StartDate
+}
+
+func (l PeriodLock) lockedError(date string) *AppError {
+	return lockedError(fmt.Sprintf("%s falls in %s (%s) and can't be changed", date, l.label(), l.Status))
+}
+
+// lockedDays returns the lock covering each of the project days that is in a locked period
+func lockedDays(tx *gorm.DB, days []projectDay) (map[projectDay]PeriodLock, error) {
+	locked := make(map[projectDay]PeriodLock)
+	if len(days) == 0 {
+		return locked, nil
+	}
+	var projectIDs []uint
+	for _, day := range days {
+		projectIDs = append(projectIDs, day.projectID)
+	}
+	var projects []Project
+	if err := tx.Unscoped().Where("id IN ?", projectIDs).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	organizations := make(map[uint]uint)
+	var organizationIDs []uint
+	for _, project := range projects {
+		organizations[project.ID] = project.OrganizationID
+		organizationIDs = append(organizationIDs, project.OrganizationID)
+	}
+	var locks []PeriodLock
+	if err := tx.Where("organization_id IN ?", organizationIDs).Find(&locks).Error; err != nil {
+		return nil, err
+	}
+
+	for _, day := range days {
+		for _, lock := range locks {
+			if lock.OrganizationID == organizations[day.projectID] && lock.covers(day.date) {
+				locked[day] = lock
+				break
+			}
+		}
+	}
+	return locked, nil
+}
+
+// ensureUnlocked fails when one of the project days is in a locked period
+func ensureUnlocked(tx *gorm.DB, days ...projectDay) error {
+	locked, err := lockedDays(tx, days)
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		if lock, ok := locked[day]; ok {
+			return lock.lockedError(day.date)
+		}
+	}
+	return nil
+}
+
+// ensureOrganizationUnlocked fails when the date is in a locked period of the organization,
+// or of any organization when organizationID is 0
+func ensureOrganizationUnlocked(tx *gorm.DB, organizationID uint, date string) error {
+	query := tx.Where("start_date <= ? AND end_date >= ?", date, date)
+	if organizationID != 0 {
+		query = query.Where("organization_id = ?", organizationID)
+	}
+	var locks []PeriodLock
+	if err := query.Limit(1).Find(&locks).Error; err != nil {
+		return err
+	}
+	if len(locks) > 0 {
+		return locks[0].lockedError(date)
+	}
+	return nil
+}
+
+// projectDays returns the project days the changes add to, remove from or move between
+func (c journalChanges) projectDays(tx *gorm.DB) ([]projectDay, error) {
+	var days []projectDay
+	for _, adjustment := range c.Adjustments {
+		days = append(days, projectDay{adjustment.ProjectID, adjustment.Date})
+	}
+
+	var hours []WorkHours
+	hourIDs := append(append([]uint{}, c.Deleted.WorkHours...), c.Restored.WorkHours...)
+	if len(hourIDs) > 0 {
+		if err := tx.Unscoped().Where("id IN ?", hourIDs).Find(&hours).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range hours {
+		days = append(days, projectDay{entry.ProjectID, entry.Date})
+	}
+
+	var sessions []WorkSession
+	sessionIDs := append(append([]uint{}, c.Deleted.WorkSessions...), c.Restored.WorkSessions...)
+	if c.Transfer != nil {
+		sessionIDs = append(sessionIDs, c.Transfer.WorkSessionID)
+	}
+	if len(sessionIDs) > 0 {
+		if err := tx.Unscoped().Where("id IN ?", sessionIDs).Find(&sessions).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, session := range sessions {
+		days = append(days, projectDay{session.ProjectID, session.Date})
+		if c.Transfer != nil && c.Transfer.WorkSessionID == session.ID {
+			days = append(days,
+				projectDay{c.Transfer.FromProjectID, session.Date},
+				projectDay{c.Transfer.ToProjectID, session.Date})
+		}
+	}
+	return days, nil
+}
+
+// timerUnlocked fails when today is in a locked period of the organization, a timer couldn't save its time
+func (a *App) timerUnlocked(organizationID uint) error {
+	today := dateIn(time.Now(), a.reportingLocation(organizationID))
+	if err := ensureOrganizationUnlocked(a.db, organizationID, today); err != nil {
+		return toAppError(err)
+	}
+	return nil
+}
+
+func (a *App) getPeriodLocks(organizationID uint, startDate, endDate string) ([]PeriodLock, error) {
+	var locks []PeriodLock
+	err := a.db.Where("organization_id = ? AND start_date <= ? AND end_date >= ?", organizationID, endDate, startDate).
+		Order("start_date").
+		Find(&locks).Error
+	return locks, err
+}
+
+// GetPeriodLocks returns the organization's locked periods that overlap two dates (inclusive)
+func (a *App) GetPeriodLocks(organizationID uint, startDate, endDate string) ([]PeriodLock, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return nil, err
+	}
+	locks, err := a.getPeriodLocks(organizationID, startDate, endDate)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return locks, nil
+}
+
+// LockPeriod locks the week or month containing the date. A period can't be locked while a timer
+// records time in it or when it overlaps a locked period
+func (a *App) LockPeriod(organizationID uint, kind PeriodKind, date string, note string) (PeriodLock, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return PeriodLock{}, err
+	}
+	startDate, endDate, err := periodBounds(kind, date)
+	if err != nil {
+		return PeriodLock{}, invalid(err)
+	}
+
+	today := dateIn(time.Now(), a.reportingLocation(organizationID))
+	if a.isTimedOrganization(organizationID) && startDate <= today && today <= endDate {
+		return PeriodLock{}, conflictError("a timer is running in this period, stop it first")
+	}
+	overlapping, err := a.getPeriodLocks(organizationID, startDate, endDate)
+	if err != nil {
+		return PeriodLock{}, toAppError(err)
+	}
+	if len(overlapping) > 0 {
+		return PeriodLock{}, conflictError(fmt.Sprintf("the period overlaps %s, which is already locked", overlapping[0].label()))
+	}
+
+	lock := PeriodLock{
+		OrganizationID: organizationID,
+		Kind:           kind,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Status:         PeriodLocked,
+		Note:           note,
+	}
+	if err := a.createAudited(AuditManual, &lock); err != nil {
+		return PeriodLock{}, toAppError(err)
+	}
+	return lock, nil
+}
+
+// SetPeriodStatus submits, approves or reopens a locked period
+func (a *App) SetPeriodStatus(lockID uint, status PeriodStatus) (PeriodLock, error) {
+	var lock PeriodLock
+	if err := a.db.First(&lock, lockID).Error; err != nil {
+		return PeriodLock{}, dbError(err, "period lock", lockID)
+	}
+	if _, ok := periodStatusNames[status]; !ok {
+		return PeriodLock{}, validationError(fmt.Sprintf("invalid period status %q", status))
+	}
+	if lock.Status == status {
+		return lock, nil
+	}
+	allowed := false
+	for _, next := range periodTransitions[lock.Status] {
+		allowed = allowed || next == status
+	}
+	if !allowed {
+		return PeriodLock{}, conflictError(fmt.Sprintf("%s is %s and can't be marked %s",
+			lock.label(), lock.Status, status))
+	}
+
+	before := lock
+	now := time.Now().UTC()
+	switch status {
+	case PeriodLocked:
+		lock.SubmittedAt = nil
+	case PeriodSubmitted:
+		lock.SubmittedAt, lock.ApprovedAt = &now, nil
+	case PeriodApproved:
+		lock.ApprovedAt = &now
+	}
+	lock.Status = status
+	if err := a.saveAudited(before, &lock); err != nil {
+		return PeriodLock{}, toAppError(err)
+	}
+	return lock, nil
+}
+
+// UnlockPeriod removes a lock, submitted and approved periods have to be reopened first
+func (a *App) UnlockPeriod(lockID uint) error {
+	var lock PeriodLock
+	if err := a.db.First(&lock, lockID).Error; err != nil {
+		return dbError(err, "period lock", lockID)
+	}
+	if lock.Status != PeriodLocked {
+		return conflictError(fmt.Sprintf("%s is %s, reopen it before unlocking", lock.label(), lock.Status))
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		return deleteAudited[PeriodLock](tx, AuditManual, []uint{lock.ID})
+	})
+	if err != nil {
+		return toAppError(err)
+	}
+	return nil
+}
+
+// lockCells returns the period, status, status date and note of a lock for report rows
+func lockCells(lock PeriodLock) []string {
+	changed := lock.CreatedAt
+	switch {
+	case lock.ApprovedAt != nil:
+		changed = *lock.ApprovedAt
+	case lock.SubmittedAt != nil:
+		changed = *lock.SubmittedAt
+	}
+	return []string{lock.label(), periodStatusNames[lock.Status], changed.In(time.Local).Format("2006-01-02"), lock.Note}
+}
+
+// scopeLocks returns the locked periods in an organization report, other reports have none
+func (a *App) scopeLocks(scope reportScope, startDate, endDate string) ([]PeriodLock, error) {
+	if scope.organizationID == 0 || scope.kind != "organization" {
+		return nil, nil
+	}
+	return a.getPeriodLocks(scope.organizationID, startDate, endDate)
+}
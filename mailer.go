@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+type SMTPSecurity string
+
+const (
+	SMTPNone     SMTPSecurity = "none"
+	SMTPStartTLS SMTPSecurity = "starttls"
+	SMTPTLS      SMTPSecurity = "tls"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySending DeliveryStatus = "sending" // claimed by an attempt, see claimDelivery
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+// An attempt still sending after this long was cut short, e.g. by a crash, and is retried
+const staleSendingAfter = 10 * time.Minute
+
+const (
+	defaultMailSubject = "Work hours for {org} - {period}"
+	defaultMailBody    = "Hello,\n\nPlease find attached the work hours for {org} ({period}).\n"
+)
+
+// Wait before each retry of a failed delivery, the delivery is given up after the last one
+var deliveryBackoff = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	12 * time.Hour,
+}
+
+// ReportMailing holds who receives an organization's reports and how the email looks
+type ReportMailing struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	OrganizationID  uint      `gorm:"uniqueIndex" json:"organization_id"`
+	Recipients      string    `json:"recipients"` // comma separated addresses
+	Cc              string    `json:"cc"`
+	SubjectTemplate string    `json:"subject_template"`
+	BodyTemplate    string    `json:"body_template"`
+}
+
+// MailDelivery is the delivery log entry of a report email
+type MailDelivery struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	OrganizationID uint           `gorm:"index" json:"organization_id"`
+	ReportRunID    *uint          `json:"report_run_id"`
+	Recipients     string         `json:"recipients"`
+	Cc             string         `json:"cc"`
+	Subject        string         `json:"subject"`
+	Body           string         `json:"body"`
+	Attachment     string         `json:"attachment"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	LastError      string         `json:"last_error"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	SentAt         *time.Time     `json:"sent_at"`
+}
+
+func defaultReportMailing(organizationID uint) ReportMailing {
+	return ReportMailing{
+		OrganizationID:  organizationID,
+		SubjectTemplate: defaultMailSubject,
+		BodyTemplate:    defaultMailBody,
+	}
+}
+
+// parseAddressList validates a comma separated list of addresses
+func parseAddressList(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	addresses, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email addresses %q: %w", list, err)
+	}
+	var result []string
+	for _, address := range addresses {
+		result = append(result, address.Address)
+	}
+	return result, nil
+}
+
+func validateSMTPSettings(settings Settings) error {
+	if settings.SMTPHost == "" {
+		return nil
+	}
+	if settings.SMTPPort <= 0 || settings.SMTPPort > 65535 {
+		return fmt.Errorf("invalid SMTP port %d", settings.SMTPPort)
+	}
+	switch settings.SMTPSecurity {
+	case SMTPNone, SMTPStartTLS, SMTPTLS:
+	default:
+		return fmt.Errorf("invalid SMTP security %q", settings.SMTPSecurity)
+	}
+	if _, err := mail.ParseAddress(settings.SMTPFrom); err != nil {
+		return fmt.Errorf("invalid sender address %q", settings.SMTPFrom)
+	}
+	return nil
+}
+
+// expandMailTemplate fills in the {org}, {period}, {type} and {file} placeholders
+func expandMailTemplate(template string, values map[string]string) string {
+	var pairs []string
+	for key, value := range values {
+		pairs = append(pairs, key, value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// buildMailMessage creates a multipart MIME message with an optional attachment
+func buildMailMessage(from string, to, cc []string, subject, body, attachment string) ([]byte, error) {
+	var message bytes.Buffer
+	writer := multipart.NewWriter(&message)
+
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+	}
+	if len(cc) > 0 {
+		headers = append(headers, "Cc: "+strings.Join(cc, ", "))
+	}
+	headers = append(headers,
+		"Subject: "+mime.QEncoding.Encode("utf-8", subject),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary="+writer.Boundary(),
+	)
+	message.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+
+	if attachment != "" {
+		data, err := os.ReadFile(attachment)
+		if err != nil {
+			return nil, err
+		}
+		fileName := filepath.Base(attachment)
+		contentType := mime.TypeByExtension(filepath.Ext(fileName))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=%q", contentType, fileName)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", fileName)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(data)
+		// Keep lines under the 998 character SMTP limit
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// sendMail delivers a message using the SMTP settings
+func sendMail(settings Settings, recipients []string, message []byte) error {
+	if settings.SMTPHost == "" {
+		return errors.New("SMTP server is not configured")
+	}
+	address := net.JoinHostPort(settings.SMTPHost, strconv.Itoa(settings.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: settings.SMTPHost}
+
+	var conn net.Conn
+	var err error
+	if settings.SMTPSecurity == SMTPTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, 30*time.Second)
+	}
+	if err != nil {
+		return err
+	}
+	// A hung server must not outlast the claim on the delivery
+	conn.SetDeadline(time.Now().Add(staleSendingAfter / 2))
+
+	client, err := smtp.NewClient(conn, settings.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if settings.SMTPSecurity == SMTPStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if settings.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", settings.SMTPUsername, settings.SMTPPassword, settings.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	from, err := mail.ParseAddress(settings.SMTPFrom)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (a *App) getReportMailing(organizationID uint) (ReportMailing, error) {
+	var mailing ReportMailing
+	err := a.db.Where(&ReportMailing{OrganizationID: organizationID}).First(&mailing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultReportMailing(organizationID), nil
+	}
+	return mailing, err
+}
+
+// GetReportMailing returns the report email configuration of the specified organization
+func (a *App) GetReportMailing(organizationID uint) (ReportMailing, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return ReportMailing{}, err
+	}
+	return a.getReportMailing(organizationID)
+}
+
+// SaveReportMailing validates and stores the report email configuration of an organization
+func (a *App) SaveReportMailing(mailing ReportMailing) (ReportMailing, error) {
+	if _, err := a.getOrganization(mailing.OrganizationID); err != nil {
+		return ReportMailing{}, err
+	}
+	if _, err := parseAddressList(mailing.Recipients); err != nil {
+		return ReportMailing{}, invalid(err)
+	}
+	if _, err := parseAddressList(mailing.Cc); err != nil {
+		return ReportMailing{}, invalid(err)
+	}
+	if mailing.SubjectTemplate == "" {
+		mailing.SubjectTemplate = defaultMailSubject
+	}
+	if mailing.BodyTemplate == "" {
+		mailing.BodyTemplate = defaultMailBody
+	}
+
+	current, err := a.getReportMailing(mailing.OrganizationID)
+	if err != nil {
+		return ReportMailing{}, err
+	}
+	mailing.ID = current.ID
+	mailing.CreatedAt = current.CreatedAt
+
+	if err := a.db.Save(&mailing).Error; err != nil {
+		return ReportMailing{}, err
+	}
+	return mailing, nil
+}
+
+// queueReportEmail adds an email with the report attached to the delivery log and tries to send it
+func (a *App) queueReportEmail(organizationID uint, reportRunID *uint, period string, exportType ExportType, attachment string) (MailDelivery, error) {
+	organization, err := a.getOrganization(organizationID)
+	if err != nil {
+		return MailDelivery{}, err
+	}
+	mailing, err := a.getReportMailing(organizationID)
+	if err != nil {
+		return MailDelivery{}, err
+	}
+	if strings.TrimSpace(mailing.Recipients) == "" {
+		return MailDelivery{}, validationError(fmt.Sprintf("no report recipients configured for %s", organization.Name))
+	}
+
+	values := map[string]string{
+		"{org}":    organization.Name,
+		"{period}": period,
+		"{type}":   strings.ToUpper(string(exportType)),
+		"{file}":   filepath.Base(attachment),
+	}
+	delivery := MailDelivery{
+		OrganizationID: organizationID,
+		ReportRunID:    reportRunID,
+		Recipients:     mailing.Recipients,
+		Cc:             mailing.Cc,
+		Subject:        expandMailTemplate(mailing.SubjectTemplate, values),
+		Body:           expandMailTemplate(mailing.BodyTemplate, values),
+		Attachment:     attachment,
+		Status:         DeliverySending, // claimed right away, the mail routine must not send it too
+		NextAttemptAt:  time.Now().UTC(),
+	}
+	if err := a.db.Create(&delivery).Error; err != nil {
+		return MailDelivery{}, err
+	}
+	return a.attemptDelivery(delivery), nil
+}
+
+// claimDelivery marks a delivery as sending if it still has one of the statuses, so only one attempt sends it
+func (a *App) claimDelivery(delivery *MailDelivery, statuses ...DeliveryStatus) (bool, error) {
+	result := a.db.Model(&MailDelivery{}).
+		Where("id = ? AND status IN ?", delivery.ID, statuses).
+		Update("status", DeliverySending)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	delivery.Status = DeliverySending
+	return result.RowsAffected == 1, nil
+}
+
+// attemptDelivery sends a claimed delivery once, scheduling a retry or giving up on failure
+func (a *App) attemptDelivery(delivery MailDelivery) MailDelivery {
+	delivery.Attempts++
+	err := func() error {
+		to, err := parseAddressList(delivery.Recipients)
+		if err != nil {
+			return err
+		}
+		cc, err := parseAddressList(delivery.Cc)
+		if err != nil {
+			return err
+		}
+		message, err := buildMailMessage(a.settings.SMTPFrom, to, cc, delivery.Subject, delivery.Body, delivery.Attachment)
+		if err != nil {
+			return err
+		}
+		return sendMail(a.settings, append(to, cc...), message)
+	}()
+
+	if err == nil {
+		sentAt := time.Now().UTC()
+		delivery.Status = DeliverySent
+		delivery.SentAt = &sentAt
+		delivery.LastError = ""
+	} else {
+		log.Printf("Email delivery %d failed (attempt %d): %v", delivery.ID, delivery.Attempts, err)
+		delivery.LastError = err.Error()
+		if delivery.Attempts > len(deliveryBackoff) {
+			delivery.Status = DeliveryFailed
+		} else {
+			delivery.Status = DeliveryPending
+			delivery.NextAttemptAt = time.Now().UTC().Add(deliveryBackoff[delivery.Attempts-1])
+		}
+	}
+
+	if err := a.db.Save(&delivery).Error; err != nil {
+		log.Printf("Error updating email delivery %d: %v", delivery.ID, err)
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "mail-delivery", delivery)
+	}
+	return delivery
+}
+
+// retryPendingDeliveries attempts every pending delivery whose retry time has come
+func (a *App) retryPendingDeliveries() {
+	err := a.db.Model(&MailDelivery{}).
+		Where("status = ? AND updated_at <= ?", DeliverySending, time.Now().UTC().Add(-staleSendingAfter)).
+		Update("status", DeliveryPending).Error
+	if err != nil {
+		log.Printf("Error releasing interrupted email deliveries: %v", err)
+	}
+
+	var deliveries []MailDelivery
+	err = a.db.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, time.Now().UTC()).Find(&deliveries).Error
+	if err != nil {
+		log.Printf("Error loading email deliveries: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		claimed, err := a.claimDelivery(&delivery, DeliveryPending)
+		if err != nil {
+			log.Printf("Error claiming email delivery %d: %v", delivery.ID, err)
+			continue
+		}
+		if claimed {
+			a.attemptDelivery(delivery)
+		}
+	}
+}
+
+func (a *App) mailRoutine() {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		a.withDb(a.retryPendingDeliveries)
+		for range ticker.C {
+			a.withDb(a.retryPendingDeliveries)
+		}
+	}()
+}
+
+// EmailReport sends an exported report file to the organization's report recipients
+func (a *App) EmailReport(organizationID uint, exportType ExportType, period string, filePath string) (MailDelivery, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return MailDelivery{}, err
+	}
+	return a.queueReportEmail(organizationID, nil, period, exportType, filePath)
+}
+
+// GetMailDeliveries returns the email delivery log of the specified organization, most recent first
+func (a *App) GetMailDeliveries(organizationID uint) (deliveries []MailDelivery, err error) {
+	err = a.db.Where(&MailDelivery{OrganizationID: organizationID}).Order("created_at DESC").Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryMailDelivery immediately retries a pending or failed delivery
+func (a *App) RetryMailDelivery(deliveryID uint) (MailDelivery, error) {
+	var delivery MailDelivery
+	if err := a.db.Where(&MailDelivery{ID: deliveryID}).First(&delivery).Error; err != nil {
+		return MailDelivery{}, err
+	}
+	if delivery.Status == DeliverySent {
+		return delivery, conflictError("email was already sent")
+	}
+	failed := delivery.Status == DeliveryFailed
+	claimed, err := a.claimDelivery(&delivery, DeliveryPending, DeliveryFailed)
+	if err != nil {
+		return MailDelivery{}, err
+	}
+	if !claimed {
+		return delivery, conflictError("email is being sent")
+	}
+	if failed {
+		// Give the delivery a fresh set of retries
+		delivery.Attempts = 0
+	}
+	return a.attemptDelivery(delivery), nil
+}
+
+// SendTestEmail sends a message to the given address to check the SMTP settings
+func (a *App) SendTestEmail(recipient string) error {
+	to, err := parseAddressList(recipient)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return validationError("recipient is empty")
+	}
+	message, err := buildMailMessage(a.settings.SMTPFrom, to, nil, "Go Work Tracker test email", "Your SMTP settings are working.\n", "")
+	if err != nil {
+		return err
+	}
+	return sendMail(a.settings, to, message)
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an in-process SMTP server that accepts PLAIN authentication and keeps the messages it receives
+type fakeSMTP struct {
+	listener net.Listener
+	username string
+	password string
+
+	mu       sync.Mutex
+	failures int // sessions still to be refused with a temporary error
+	messages []fakeMessage
+}
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T, username, password string) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTP{listener: listener, username: username, password: password}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTP) settings() Settings {
+	return Settings{
+		SMTPHost:     "127.0.0.1",
+		SMTPPort:     s.listener.Addr().(*net.TCPAddr).Port,
+		SMTPSecurity: SMTPNone,
+		SMTPUsername: s.username,
+		SMTPPassword: s.password,
+		SMTPFrom:     "Work Tracker <tracker@example.com>",
+	}
+}
+
+func (s *fakeSMTP) failNext(sessions int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = sessions
+}
+
+func (s *fakeSMTP) received() []fakeMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeMessage{}, s.messages...)
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost fake ESMTP")
+	var message fakeMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			fields := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			if string(decoded) == "\x00"+s.username+"\x00"+s.password {
+				text.PrintfLine("235 authenticated")
+			} else {
+				text.PrintfLine("535 authentication failed")
+			}
+		case "MAIL":
+			s.mu.Lock()
+			refuse := s.failures > 0
+			if refuse {
+				s.failures--
+			}
+			s.mu.Unlock()
+			if refuse {
+				text.PrintfLine("451 try again later")
+				continue
+			}
+			message = fakeMessage{from: addressOf(line)}
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			message.to = append(message.to, addressOf(line))
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
+
+func addressOf(line string) string {
+	return strings.Trim(line[strings.Index(line, ":")+1:], "<> ")
+}
+
+func TestExpandMailTemplate(t *testing.T) {
+	values := map[string]string{"{org}": "Acme", "{period}": "March 2026", "{type}": "PDF", "{file}": "Acme.pdf"}
+	got := expandMailTemplate("{type} report for {org}, {period}: {file} {unknown}", values)
+	if want := "PDF report for Acme, March 2026: Acme.pdf {unknown}"; got != want {
+		t.Errorf("expandMailTemplate = %q, want %q", got, want)
+	}
+}
+
+func TestBuildMailMessage(t *testing.T) {
+	attachment := filepath.Join(t.TempDir(), "Acme March 2026.pdf")
+	content := []byte(strings.Repeat("%PDF report content ", 20))
+	if err := os.WriteFile(attachment, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := buildMailMessage("tracker@example.com", []string{"a@example.com", "b@example.com"}, []string{"c@example.com"},
+		"Stunden für Acme", "Hello,\nattached.\n", attachment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := message.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := message.Header.Get("Cc"); got != "c@example.com" {
+		t.Errorf("Cc = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "Stunden für Acme" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q (%v)", mediaType, err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	body, err := reader.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, _ := io.ReadAll(body)
+	if string(text) != "Hello,\r\nattached.\r\n" {
+		t.Errorf("body = %q", text)
+	}
+
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.FileName() != "Acme March 2026.pdf" {
+		t.Errorf("attachment file name = %q", part.FileName())
+	}
+	encoded, _ := io.ReadAll(part)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("attachment line of %d characters", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil || string(decoded) != string(content) {
+		t.Errorf("attachment doesn't round trip: %v", err)
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected two parts, got %v", err)
+	}
+
+	// Without Cc and attachment
+	data, err = buildMailMessage("tracker@example.com", []string{"a@example.com"}, nil, "Test", "Body\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Cc:") || strings.Contains(string(data), "attachment") {
+		t.Errorf("unexpected Cc or attachment in %q", data)
+	}
+}
+
+func TestSendMailAuth(t *testing.T) {
+	server := newFakeSMTP(t, "user", "secret")
+	settings := server.settings()
+	message := []byte("Subject: test\r\n\r\nhello\r\n")
+
+	if err := sendMail(settings, []string{"a@example.com", "c@example.com"}, message); err != nil {
+		t.Fatal(err)
+	}
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	if received[0].from != "tracker@example.com" || strings.Join(received[0].to, ",") != "a@example.com,c@example.com" {
+		t.Errorf("envelope = %q -> %q", received[0].from, received[0].to)
+	}
+	if !strings.Contains(received[0].data, "hello") {
+		t.Errorf("data = %q", received[0].data)
+	}
+
+	settings.SMTPPassword = "wrong"
+	if err := sendMail(settings, []string{"a@example.com"}, message); err == nil {
+		t.Error("sending with a wrong password succeeded")
+	}
+	settings.SMTPHost = ""
+	if err := sendMail(settings, []string{"a@example.com"}, message); err == nil {
+		t.Error("sending without a server succeeded")
+	}
+	if len(server.received()) != 1 {
+		t.Error("a failed send delivered a message")
+	}
+}
+
+// newMailingApp returns an app sending through the fake server with an organization that has report recipients
+func newMailingApp(t *testing.T, server *fakeSMTP) (*App, uint) {
+	t.Helper()
+	a := newTestApp(t)
+	a.settings = server.settings()
+	created, err := a.NewOrganization("Acme", "Website")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.SaveReportMailing(ReportMailing{
+		OrganizationID:  created.Organization.ID,
+		Recipients:      "boss@acme.example",
+		Cc:              "me@example.com",
+		SubjectTemplate: "{org} {period} ({type})",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, created.Organization.ID
+}
+
+// makeDue moves a delivery's next attempt into the past
+func makeDue(t *testing.T, a *App, deliveryID uint) {
+	t.Helper()
+	err := a.db.Model(&MailDelivery{ID: deliveryID}).Update("next_attempt_at", time.Now().UTC().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getDelivery(t *testing.T, a *App, deliveryID uint) MailDelivery {
+	t.Helper()
+	var delivery MailDelivery
+	if err := a.db.First(&delivery, deliveryID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliveryRetry(t *testing.T) {
+	server := newFakeSMTP(t, "user", "secret")
+	a, organizationID := newMailingApp(t, server)
+	server.failNext(1)
+
+	delivery, err := a.queueReportEmail(organizationID, nil, "March 2026", PDF, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != DeliveryPending || delivery.Attempts != 1 || delivery.LastError == "" {
+		t.Fatalf("after a refused attempt: status %s, attempts %d, error %q", delivery.Status, delivery.Attempts, delivery.LastError)
+	}
+	if wait := time.Until(delivery.NextAttemptAt); wait < deliveryBackoff[0]-5*time.Second || wait > deliveryBackoff[0] {
+		t.Errorf("next attempt in %s, want %s", wait, deliveryBackoff[0])
+	}
+
+	// Not due yet
+	a.retryPendingDeliveries()
+	if len(server.received()) != 0 {
+		t.Fatal("a delivery was retried before its time")
+	}
+
+	makeDue(t, a, delivery.ID)
+	a.retryPendingDeliveries()
+	delivery = getDelivery(t, a, delivery.ID)
+	if delivery.Status != DeliverySent || delivery.Attempts != 2 || delivery.SentAt == nil || delivery.LastError != "" {
+		t.Fatalf("after the retry: status %s, attempts %d, error %q", delivery.Status, delivery.Attempts, delivery.LastError)
+	}
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	if strings.Join(received[0].to, ",") != "boss@acme.example,me@example.com" {
+		t.Errorf("recipients = %q", received[0].to)
+	}
+	if !strings.Contains(received[0].data, "Subject: Acme March 2026 (PDF)") {
+		t.Errorf("subject template not expanded in %q", received[0].data)
+	}
+
+	// Sent deliveries are left alone
+	makeDue(t, a, delivery.ID)
+	a.retryPendingDeliveries()
+	if _, err := a.RetryMailDelivery(delivery.ID); err == nil {
+		t.Error("retrying a sent delivery succeeded")
+	}
+	if len(server.received()) != 1 {
+		t.Error("a sent delivery was sent again")
+	}
+}
+
+func TestDeliveryGivesUp(t *testing.T) {
+	server := newFakeSMTP(t, "user", "secret")
+	a, organizationID := newMailingApp(t, server)
+	server.failNext(1000)
+
+	delivery, err := a.queueReportEmail(organizationID, nil, "2026", CSV, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range deliveryBackoff {
+		if delivery.Status != DeliveryPending {
+			t.Fatalf("attempt %d: status %s, want pending", delivery.Attempts, delivery.Status)
+		}
+		// Each retry waits longer
+		if i > 0 && deliveryBackoff[i] <= deliveryBackoff[i-1] {
+			t.Fatalf("backoff %d isn't longer than the one before", i)
+		}
+		makeDue(t, a, delivery.ID)
+		a.retryPendingDeliveries()
+		delivery = getDelivery(t, a, delivery.ID)
+	}
+	if delivery.Status != DeliveryFailed || delivery.Attempts != len(deliveryBackoff)+1 {
+		t.Fatalf("status %s after %d attempts, want failed after %d", delivery.Status, delivery.Attempts, len(deliveryBackoff)+1)
+	}
+
+	// Failed deliveries aren't retried in the background
+	makeDue(t, a, delivery.ID)
+	a.retryPendingDeliveries()
+	if getDelivery(t, a, delivery.ID).Attempts != len(deliveryBackoff)+1 {
+		t.Error("a failed delivery was retried")
+	}
+
+	// A manual retry starts over
+	server.failNext(0)
+	delivery, err = a.RetryMailDelivery(delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != DeliverySent || delivery.Attempts != 1 {
+		t.Errorf("manual retry: status %s, attempts %d", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestDeliveryClaim(t *testing.T) {
+	server := newFakeSMTP(t, "user", "secret")
+	a, organizationID := newMailingApp(t, server)
+
+	delivery := MailDelivery{OrganizationID: organizationID, Recipients: "boss@acme.example", Subject: "s", Body: "b",
+		Status: DeliverySending, NextAttemptAt: time.Now().UTC().Add(-time.Minute)}
+	if err := a.db.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// An attempt in progress isn't picked up by the mail routine
+	a.retryPendingDeliveries()
+	if len(server.received()) != 0 {
+		t.Fatal("a delivery being sent was sent again")
+	}
+	if _, err := a.RetryMailDelivery(delivery.ID); err == nil {
+		t.Error("retrying a delivery being sent succeeded")
+	}
+
+	// Only one of two claims wins
+	a.db.Model(&delivery).Update("status", DeliveryPending)
+	first, err := a.claimDelivery(&delivery, DeliveryPending)
+	if err != nil || !first {
+		t.Fatalf("first claim = %v, %v", first, err)
+	}
+	if second, _ := a.claimDelivery(&delivery, DeliveryPending); second {
+		t.Error("second claim succeeded")
+	}
+
+	// An attempt cut short is retried once it is stale
+	stale := time.Now().UTC().Add(-staleSendingAfter - time.Minute)
+	if err := a.db.Model(&delivery).UpdateColumn("updated_at", stale).Error; err != nil {
+		t.Fatal(err)
+	}
+	a.retryPendingDeliveries()
+	if got := getDelivery(t, a, delivery.ID); got.Status != DeliverySent || len(server.received()) != 1 {
+		t.Errorf("stale delivery: status %s, %d messages", got.Status, len(server.received()))
+	}
+}
+
+func TestSMTPPasswordSecret(t *testing.T) {
+	settings := defaultSettings()
+	settings.SMTPHost, settings.SMTPUsername, settings.SMTPPassword = "smtp.example.com", "me", "secret"
+	settings.SMTPFrom = "me@example.com"
+
+	// A plaintext database doesn't take the password
+	plain := newTestApp(t)
+	var appErr *AppError
+	if _, err := plain.UpdateSettings(settings); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("saving the password into a plaintext database: %v, want a conflict", err)
+	}
+
+	a := newEncryptedTestApp(t)
+	saved, err := a.UpdateSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := a.GetSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []Settings{saved, loaded} {
+		if got.SMTPPassword != "" || !got.SMTPPasswordSet {
+			t.Errorf("the frontend gets %q, set %v", got.SMTPPassword, got.SMTPPasswordSet)
+		}
+	}
+	if a.settings.SMTPPassword != "secret" {
+		t.Errorf("the mailer has the password %q", a.settings.SMTPPassword)
+	}
+
+	// Saving the redacted settings keeps the password, another username drops it
+	saved.SMTPPort = 465
+	if _, err := a.UpdateSettings(saved); err != nil || a.settings.SMTPPassword != "secret" {
+		t.Errorf("after saving without the password: %q, %v", a.settings.SMTPPassword, err)
+	}
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("decrypting with an SMTP password: %v, want a conflict", err)
+	}
+	saved.SMTPUsername = "someone else"
+	if _, err := a.UpdateSettings(saved); err != nil || a.settings.SMTPPassword != "" {
+		t.Errorf("after changing the username: %q, %v", a.settings.SMTPPassword, err)
+	}
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); err != nil {
+		t.Errorf("decrypting without an SMTP password: %v", err)
+	}
+}
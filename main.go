@@ -3,6 +3,7 @@ package main
 import (
 	"embed"
 	"fmt"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -25,6 +26,17 @@ const (
 )
 
 func main() {
+	// `go-work-tracker server` runs the team server instead of the app, see server.go
+	if len(os.Args) > 1 && os.Args[1] == "server" {
+		if err := runServer(os.Args[2:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	parseDataDirArgs(os.Args[1:])
+
 	// Create an instance of the app structure
 	app := NewApp()
 	appTitle := "Go Work Tracker"
@@ -45,6 +57,7 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
 		OnShutdown:       app.shutdown,
+		ErrorFormatter:   formatError,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	chartHeight      = 60.0
+	chartLabelHeight = 6.0
+	pieDiameter      = 50.0
+)
+
+type chartPoint struct {
+	label string
+	value float64
+}
+
+// Colors used for pie slices, cycled when there are more slices than colors
+var chartPalette = [][3]int{
+	{54, 162, 235},
+	{255, 99, 132},
+	{255, 159, 64},
+	{75, 192, 192},
+	{153, 102, 255},
+	{255, 205, 86},
+	{201, 203, 207},
+	{46, 139, 87},
+}
+
+// niceCeiling rounds a maximum up to a value that gives readable axis labels
+func niceCeiling(value float64) float64 {
+	if value <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if step*magnitude >= value {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func maxChartValue(points []chartPoint) float64 {
+	maxValue := 0.0
+	for _, point := range points {
+		maxValue = math.Max(maxValue, point.value)
+	}
+	return niceCeiling(maxValue)
+}
+
+// chartArea starts a chart block and returns its drawing area
+func (r *pdfReport) chartArea(title string, height float64) (x, y, width float64) {
+	if r.spaceLeft() < height+2*chartLabelHeight+pdfRowHeight {
+		r.pdf.AddPage()
+	}
+	r.pdf.SetFont(r.template.FontFamily, "", 12)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	r.pdf.Cell(pdfColumnWidth, 10, title)
+	r.pdf.Ln(-1)
+
+	left, _, right, _ := r.pdf.GetMargins()
+	pageWidth, _ := r.pdf.GetPageSize()
+	return left, r.pdf.GetY(), pageWidth - left - right
+}
+
+// valueAxis draws the Y axis with hour labels and the X axis of a chart
+func (r *pdfReport) valueAxis(x, y, width, height, maxValue float64) {
+	r.pdf.SetFont(r.template.FontFamily, "", 7)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	r.setColor(r.template.TextColor, r.pdf.SetDrawColor)
+	r.pdf.SetLineWidth(0.2)
+	for _, fraction := range []float64{0, 0.5, 1} {
+		lineY := y + height - fraction*height
+		r.pdf.SetXY(x-12, lineY-2)
+		r.pdf.CellFormat(10, 4, fmt.Sprintf("%.4gh", fraction*maxValue), "", 0, "R", false, 0, "")
+		if fraction > 0 {
+			r.pdf.SetDashPattern([]float64{0.8, 0.8}, 0)
+		}
+		r.pdf.Line(x, lineY, x+width, lineY)
+		r.pdf.SetDashPattern([]float64{}, 0)
+	}
+	r.pdf.Line(x, y, x, y+height)
+}
+
+// xLabels writes labels under evenly spaced slots, skipping some when they would overlap
+func (r *pdfReport) xLabels(x, y, slotWidth float64, points []chartPoint) {
+	r.pdf.SetFont(r.template.FontFamily, "", 7)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	every := 1
+	for every < len(points) && r.pdf.GetStringWidth(points[0].label)+1 > slotWidth*float64(every) {
+		every++
+	}
+	for i, point := range points {
+		if i%every != 0 {
+			continue
+		}
+		r.pdf.SetXY(x+float64(i)*slotWidth, y+1)
+		r.pdf.CellFormat(slotWidth*float64(every), 4, point.label, "", 0, "L", false, 0, "")
+	}
+}
+
+func (r *pdfReport) barChart(title string, points []chartPoint) {
+	if len(points) == 0 {
+		return
+	}
+	left, top, width := r.chartArea(title, chartHeight)
+	x := left + 14 // room for the axis labels
+	width -= 14
+	maxValue := maxChartValue(points)
+
+	r.valueAxis(x, top, width, chartHeight, maxValue)
+	slotWidth := width / float64(len(points))
+	r.setColor(r.template.PrimaryColor, r.pdf.SetFillColor)
+	for i, point := range points {
+		barHeight := point.value / maxValue * chartHeight
+		if barHeight <= 0 {
+			continue
+		}
+		r.pdf.Rect(x+float64(i)*slotWidth+slotWidth*0.15, top+chartHeight-barHeight, slotWidth*0.7, barHeight, "F")
+	}
+	r.xLabels(x, top+chartHeight, slotWidth, points)
+
+	r.pdf.SetY(top + chartHeight + 2*chartLabelHeight)
+}
+
+func (r *pdfReport) lineChart(title string, points []chartPoint) {
+	if len(points) == 0 {
+		return
+	}
+	left, top, width := r.chartArea(title, chartHeight)
+	x := left + 14 // room for the axis labels
+	width -= 14
+	maxValue := maxChartValue(points)
+
+	r.valueAxis(x, top, width, chartHeight, maxValue)
+	slotWidth := width / float64(len(points))
+	r.setColor(r.template.PrimaryColor, r.pdf.SetDrawColor)
+	r.setColor(r.template.PrimaryColor, r.pdf.SetFillColor)
+	r.pdf.SetLineWidth(0.6)
+	var prevX, prevY float64
+	for i, point := range points {
+		pointX := x + float64(i)*slotWidth + slotWidth/2
+		pointY := top + chartHeight - point.value/maxValue*chartHeight
+		if i > 0 {
+			r.pdf.Line(prevX, prevY, pointX, pointY)
+		}
+		r.pdf.Circle(pointX, pointY, 0.8, "F")
+		prevX, prevY = pointX, pointY
+	}
+	r.pdf.SetLineWidth(0.2)
+	r.xLabels(x, top+chartHeight, slotWidth, points)
+
+	r.pdf.SetY(top + chartHeight + 2*chartLabelHeight)
+}
+
+func (r *pdfReport) pieChart(title string, points []chartPoint) {
+	total := 0.0
+	for _, point := range points {
+		total += point.value
+	}
+	if total <= 0 {
+		return
+	}
+	left, top, _ := r.chartArea(title, pieDiameter)
+	radius := pieDiameter / 2
+	centerX, centerY := left+radius, top+radius
+
+	r.pdf.SetDrawColor(255, 255, 255)
+	r.pdf.SetLineWidth(0.3)
+	start := -math.Pi / 2 // start at 12 o'clock
+	for i, point := range points {
+		sweep := point.value / total * 2 * math.Pi
+		color := chartPalette[i%len(chartPalette)]
+		r.pdf.SetFillColor(color[0], color[1], color[2])
+
+		// Approximate the slice with a polygon, one vertex every ~2 degrees
+		slice := []gofpdf.PointType{{X: centerX, Y: centerY}}
+		steps := int(math.Max(2, math.Ceil(sweep/(math.Pi/90))))
+		for step := 0; step <= steps; step++ {
+			angle := start + sweep*float64(step)/float64(steps)
+			slice = append(slice, gofpdf.PointType{X: centerX + radius*math.Cos(angle), Y: centerY + radius*math.Sin(angle)})
+		}
+		r.pdf.Polygon(slice, "FD")
+		start += sweep
+
+		// Legend
+		legendY := top + float64(i)*5
+		if legendY+5 > top+pieDiameter+chartLabelHeight*4 {
+			continue
+		}
+		r.pdf.Rect(centerX+radius+10, legendY+1, 3, 3, "F")
+		r.pdf.SetFont(r.template.FontFamily, "", 8)
+		r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+		r.pdf.SetXY(centerX+radius+15, legendY)
+		r.pdf.CellFormat(0, 5, fmt.Sprintf("%s (%.1f%%)", point.label, point.value/total*100), "", 0, "L", false, 0, "")
+	}
+
+	legendBottom := top + float64(len(points))*5
+	r.pdf.SetY(math.Max(top+pieDiameter, math.Min(legendBottom, top+pieDiameter+chartLabelHeight*4)) + chartLabelHeight)
+}
+
+// projectSharePoints returns the hours per project for a pie chart
+func projectSharePoints(projectTotals []ProjectTotal) []chartPoint {
+	var points []chartPoint
+	for _, projectTotal := range projectTotals {
+		if projectTotal.Seconds > 0 {
+			points = append(points, chartPoint{label: projectTotal.Name, value: secondsToHours(projectTotal.Seconds)})
+		}
+	}
+	return points
+}
+
+// monthlyCharts draws the hours per day, hours per week and project share charts of a month
+func (r *pdfReport) monthlyCharts(totals MonthlyTotals, year int, month time.Month) {
+	var daily []chartPoint
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for day := 1; day <= daysInMonth; day++ {
+		date := fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+		daily = append(daily, chartPoint{label: fmt.Sprintf("%d", day), value: secondsToHours(totals.DateSumTotals[date])})
+	}
+	r.barChart("Hours per day", daily)
+
+	var weekly []chartPoint
+	weekRanges := getWeekRanges(year, month)
+	for week := 1; week <= 5; week++ {
+		weekly = append(weekly, chartPoint{label: weekRanges[week], value: secondsToHours(totals.WeekSumTotals[week])})
+	}
+	r.barChart("Hours per week", weekly)
+
+	r.pieChart("Project share", projectSharePoints(totals.ProjectTotals))
+}
+
+// yearlyCharts draws the monthly trend and project share charts of a year
+func (r *pdfReport) yearlyCharts(totals YearlyTotals) {
+	var trend []chartPoint
+	for mIdx := time.January; mIdx <= time.December; mIdx++ {
+		month := monthMap[int(mIdx)]
+		trend = append(trend, chartPoint{label: month[:3], value: secondsToHours(totals.MonthSumTotals[month])})
+	}
+	r.lineChart("Monthly trend", trend)
+
+	r.pieChart("Project share", projectSharePoints(totals.ProjectTotals))
+}
@@ -3,8 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
@@ -13,357 +11,576 @@ import (
 	"github.com/jung-kurt/gofpdf"
 )
 
-func (a *App) exportPDFByMonth(organization string, year int, month time.Month) (string, error) {
-	MonthlyTotals, err := a.getMonthlyTotals(organization, year, month)
-	if err != nil {
-		log.Println(err)
-		return "", err
-	}
+const (
+	pdfRowHeight    = 10.0
+	pdfColumnWidth  = 40.0
+	pdfBottomMargin = 20.0
+)
 
-	// Get the save directory
-	dbDir, err := getSaveDir(a.environment)
-	if err != nil {
-		log.Println(err)
-		return "", err
-	}
+type pdfRow struct {
+	cells []string
+	total bool // highlighted TOTAL row
+}
 
-	// Create the directories for the organization, year, and month
-	dir := filepath.Join(dbDir, "pdf", organization, strconv.Itoa(year), month.String())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Println(err)
-		return "", err
-	}
+type pdfTable struct {
+	headers []string
+	widths  []float64
+	rows    []pdfRow
+}
 
-	// Create a new PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
+// pdfReport renders report sections using an organization's ReportTemplate
+type pdfReport struct {
+	pdf      *gofpdf.Fpdf
+	template ReportTemplate
+}
+
+func newPDFReport(template ReportTemplate) *pdfReport {
+	pdf := gofpdf.New(template.Orientation, "mm", template.PageSize, "")
+	pdf.SetAutoPageBreak(true, pdfBottomMargin)
+	pdf.AliasNbPages("")
+	r := &pdfReport{pdf: pdf, template: template}
 
-	// Add a page
-	pdf.AddPage()
+	pdf.SetHeaderFunc(r.header)
+	pdf.SetFooterFunc(r.footer)
+	return r
+}
 
-	// Set font
-	pdf.SetFont("Arial", "B", 16)
+func (r *pdfReport) setColor(color string, set func(r, g, b int)) {
+	red, green, blue, err := parseHexColor(color)
+	if err != nil {
+		red, green, blue = 0, 0, 0
+	}
+	set(red, green, blue)
+}
 
-	// pdf.SetFillColor(240, 240, 240)
+func (r *pdfReport) header() {
+	headerHeight := 0.0
+	if r.template.LogoPath != "" {
+		options := gofpdf.ImageOptions{ReadDpi: true}
+		r.pdf.ImageOptions(r.template.LogoPath, 10, 6, 0, 12, false, options, 0, "")
+		if err := r.pdf.Error(); err != nil {
+			// A broken logo shouldn't prevent the report from being generated
+			log.Println(err)
+			r.pdf.ClearError()
+		} else {
+			headerHeight = 12
+		}
+	}
+	if r.template.HeaderText != "" {
+		r.pdf.SetFont(r.template.FontFamily, "I", 9)
+		r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+		pageWidth, _ := r.pdf.GetPageSize()
+		_, _, right, _ := r.pdf.GetMargins()
+		r.pdf.SetXY(pageWidth/2, 8)
+		r.pdf.CellFormat(pageWidth/2-right, 6, r.template.HeaderText, "", 0, "R", false, 0, "")
+		headerHeight = max(headerHeight, 6)
+	}
+	if headerHeight > 0 {
+		r.pdf.SetY(8 + headerHeight)
+		r.pdf.Ln(2)
+	}
+}
 
-	// Write title
-	pdf.Cell(40, 10, fmt.Sprintf("Work Hours for %s", organization))
-	pdf.Ln(-1)
+func (r *pdfReport) footer() {
+	if r.template.FooterText == "" && !r.template.PageNumbers {
+		return
+	}
+	r.pdf.SetY(-15)
+	r.pdf.SetFont(r.template.FontFamily, "I", 9)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	if r.template.FooterText != "" {
+		r.pdf.CellFormat(0, 10, r.template.FooterText, "", 0, "L", false, 0, "")
+	}
+	if r.template.PageNumbers {
+		left, _, _, _ := r.pdf.GetMargins()
+		r.pdf.SetX(left)
+		r.pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", r.pdf.PageNo()), "", 0, "R", false, 0, "")
+	}
+}
 
-	// Set font for table
-	pdf.SetFont("Arial", "", 12)
+func (r *pdfReport) title(text string) {
+	r.pdf.SetFont(r.template.FontFamily, "B", 16)
+	r.setColor(r.template.PrimaryColor, r.pdf.SetTextColor)
+	r.pdf.Cell(pdfColumnWidth, 10, text)
+	r.pdf.Ln(-1)
+}
 
-	// Write title
-	pdf.Cell(40, 10, fmt.Sprintf("Month total for organization %s", organization))
-	pdf.Ln(-1)
+func (r *pdfReport) address(text string) {
+	if text == "" {
+		return
+	}
+	r.pdf.SetFont(r.template.FontFamily, "", 10)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	r.pdf.MultiCell(0, 5, text, "", "L", false)
+	r.pdf.Ln(2)
+}
 
-	// Write table header for monthly total
-	pdf.CellFormat(40, 10, "Month", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
+// spaceLeft returns the vertical space left on the current page before the automatic page break
+func (r *pdfReport) spaceLeft() float64 {
+	_, pageHeight := r.pdf.GetPageSize()
+	return pageHeight - pdfBottomMargin - r.pdf.GetY()
+}
 
-	// Write monthly total
-	monthlyHours := secondsToHours(MonthlyTotals.MonthlyTotal)
-	pdf.CellFormat(40, 10, month.String(), "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", monthlyHours), "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, formatTime(MonthlyTotals.MonthlyTotal), "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
+func (r *pdfReport) tableHeader(table pdfTable) {
+	r.pdf.SetFont(r.template.FontFamily, "B", 12)
+	r.setColor(r.template.PrimaryColor, r.pdf.SetFillColor)
+	r.pdf.SetTextColor(255, 255, 255)
+	for i, header := range table.headers {
+		r.pdf.CellFormat(table.widths[i], pdfRowHeight, header, "1", 0, "", true, 0, "")
+	}
+	r.pdf.Ln(-1)
+}
+
+// section writes a titled table, repeating the table header when it continues on a new page
+func (r *pdfReport) section(title string, table pdfTable) {
+	// Keep the title, header and first row together
+	if r.spaceLeft() < 3*pdfRowHeight {
+		r.pdf.AddPage()
+	}
+	r.pdf.SetFont(r.template.FontFamily, "", 12)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	r.pdf.Cell(pdfColumnWidth, 10, title)
+	r.pdf.Ln(-1)
+	r.tableHeader(table)
+
+	for _, row := range table.rows {
+		if r.spaceLeft() < pdfRowHeight {
+			r.pdf.AddPage()
+			r.tableHeader(table)
+		}
+		style := ""
+		if row.total {
+			style = "B"
+		}
+		r.pdf.SetFont(r.template.FontFamily, style, 12)
+		r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+		r.setColor(r.template.AccentColor, r.pdf.SetFillColor)
+		for i, cell := range row.cells {
+			r.pdf.CellFormat(table.widths[i], pdfRowHeight, cell, "1", 0, "", row.total, 0, "")
+		}
+		r.pdf.Ln(-1)
+	}
 
 	// Add space between tables
-	pdf.Ln(-1)
+	r.pdf.Ln(-1)
+}
+
+func (r *pdfReport) signature() {
+	if r.spaceLeft() < 4*pdfRowHeight {
+		r.pdf.AddPage()
+	}
+	r.pdf.Ln(10)
+	r.pdf.SetFont(r.template.FontFamily, "", 12)
+	r.setColor(r.template.TextColor, r.pdf.SetTextColor)
+	label := r.template.SignatureLabel
+	if label == "" {
+		label = "Signature"
+	}
+	r.pdf.CellFormat(80, pdfRowHeight, label+": ______________________", "", 0, "", false, 0, "")
+	r.pdf.CellFormat(80, pdfRowHeight, "Date: ______________", "", 0, "", false, 0, "")
+	r.pdf.Ln(-1)
+}
 
+// projectColumnWidth returns a width that fits the longest project name
+func (r *pdfReport) projectColumnWidth(projectTotals []ProjectTotal) float64 {
 	// find the project with the longest name to set the width of the project column
 	var longestProjectName string
-	for _, projectTotal := range MonthlyTotals.ProjectTotals {
+	for _, projectTotal := range projectTotals {
 		if len(projectTotal.Name) > len(longestProjectName) {
 			longestProjectName = projectTotal.Name
 		}
 	}
 
-	width := 40.0
-	if pdf.GetStringWidth(longestProjectName) > 40 {
-		width = pdf.GetStringWidth(longestProjectName) + 5
+	r.pdf.SetFont(r.template.FontFamily, "", 12)
+	width := pdfColumnWidth
+	if r.pdf.GetStringWidth(longestProjectName) > pdfColumnWidth {
+		width = r.pdf.GetStringWidth(longestProjectName) + 5
 	}
+	return width
+}
+
+// taskColumnWidth returns a width that fits the longest task name
+func (r *pdfReport) taskColumnWidth(taskTotals []TaskTotal) float64 {
+	r.pdf.SetFont(r.template.FontFamily, "", 12)
+	width := pdfColumnWidth
+	for _, taskTotal := range taskTotals {
+		width = max(width, r.pdf.GetStringWidth(taskTotal.Task)+5)
+	}
+	return width
+}
 
-	// Write table header for monthly per project breakdown
-	pdf.Cell(40, 10, "Monthly breakdown")
-	pdf.Ln(-1)
-	pdf.CellFormat(width, 10, "Project", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
+// overtime writes the expected and actual hours grouped by label and the resulting balance
+// when the organization has a work schedule
+func (r *pdfReport) overtime(overtime OvertimeSummary, group, period string, label func(date string) string) {
+	if !overtime.Enabled {
+		return
+	}
+	var rows []pdfRow
+	for _, cells := range overtimeRows(overtime, label) {
+		rows = append(rows, pdfRow{cells: cells})
+	}
+	for _, cells := range overtimeBalanceRows(overtime, period) {
+		rows = append(rows, pdfRow{cells: cells, total: true})
+	}
+	r.section("Overtime", pdfTable{
+		headers: []string{group, "Expected hours", "Absence hours", "Actual hours", "Difference"},
+		widths:  []float64{pdfColumnWidth, 36, 36, 36, 36},
+		rows:    rows,
+	})
+}
 
-	// Write monthly totals per project
-	for _, projectTotal := range MonthlyTotals.ProjectTotals {
+// reconciliation writes the difference between the raw and the billable total when time is rounded
+func (r *pdfReport) reconciliation(rounded RoundedTotals, raw int) {
+	if !rounded.Enabled {
+		return
+	}
+	r.section("Rounding reconciliation", pdfTable{
+		headers: []string{"Policy", "Raw hours", "Billable hours", "Difference"},
+		widths:  []float64{75, 35, 40, 35},
+		rows:    []pdfRow{{cells: rounded.reconciliation(raw), total: true}},
+	})
+}
+
+func (r *pdfReport) save(pdfFilePath string) error {
+	return r.pdf.OutputFileAndClose(pdfFilePath)
+}
+
+func hoursCells(seconds int) []string {
+	return []string{fmt.Sprintf("%.2f", secondsToHours(seconds)), formatTime(seconds)}
+}
+
+// billableCell returns the billable hours cell, none when billable is nil because time isn't rounded
+func billableCell(billable map[string]int, project string) []string {
+	if billable == nil {
+		return nil
+	}
+	return []string{fmt.Sprintf("%.2f", secondsToHours(billable[project]))}
+}
+
+// projectRows returns a row per project with time logged
+func projectRows(projectTotals []ProjectTotal, billable map[string]int) []pdfRow {
+	var rows []pdfRow
+	for _, projectTotal := range projectTotals {
 		if projectTotal.Seconds == 0 {
 			continue
 		}
+		cells := append([]string{projectTotal.Name}, hoursCells(projectTotal.Seconds)...)
+		rows = append(rows, pdfRow{cells: append(cells, billableCell(billable, projectTotal.Name)...)})
+	}
+	return rows
+}
 
-		projectHours := secondsToHours(projectTotal.Seconds)
-		pdf.CellFormat(width, 10, projectTotal.Name, "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", projectHours), "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, formatTime(projectTotal.Seconds), "1", 0, "", false, 0, "")
-		pdf.Ln(-1)
+// taskRows returns a row per task with time logged, the project is only shown on its first task
+func taskRows(taskTotals []TaskTotal) []pdfRow {
+	var rows []pdfRow
+	previous := ""
+	for _, taskTotal := range taskTotals {
+		project := taskTotal.Project
+		if project == previous {
+			project = ""
+		}
+		previous = taskTotal.Project
+		rows = append(rows, pdfRow{cells: append([]string{project, taskTotal.Task}, hoursCells(taskTotal.Seconds)...)})
 	}
+	return rows
+}
 
-	// Add space between tables
-	pdf.Ln(-1)
-
-	// Write table header for weekly breakdown
-	pdf.Cell(40, 10, "Weekly breakdown")
-	pdf.Ln(-1)
-	pdf.CellFormat(40, 10, "Week", "1", 0, "", false, 0, "")
-	pdf.CellFormat(width, 10, "Project", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-
-	weekRanges := getWeekRanges(year, month)
-
-	// Write weekly totals
-	for week := 1; week <= 5; week++ {
-		projectTotals, ok := MonthlyTotals.WeeklyTotals[week]
-		if !ok {
-			continue
+// groupRows returns a TOTAL row for the group followed by a row per project with time logged.
+// Groups without any time logged are skipped
+func groupRows(label string, total int, projectTotals map[string]int, billable map[string]int) []pdfRow {
+	// check that at least one project has time logged
+	var logGroup bool
+	for _, seconds := range projectTotals {
+		if seconds > 0 {
+			logGroup = true
+			break
 		}
-		// check that at least one project has time logged
-		var logWeek bool
-		for _, seconds := range projectTotals {
-			if seconds > 0 {
-				logWeek = true
-				break
-			}
+	}
+	if !logGroup {
+		return nil
+	}
+
+	cells := append([]string{label, "TOTAL"}, hoursCells(total)...)
+	if billable != nil {
+		billableTotal := 0
+		for _, seconds := range billable {
+			billableTotal += seconds
 		}
-		if !logWeek {
+		cells = append(cells, fmt.Sprintf("%.2f", secondsToHours(billableTotal)))
+	}
+	rows := []pdfRow{{cells: cells, total: true}}
+	for project, seconds := range projectTotals {
+		if seconds == 0 {
 			continue
 		}
+		cells := append([]string{"", project}, hoursCells(seconds)...)
+		rows = append(rows, pdfRow{cells: append(cells, billableCell(billable, project)...)})
+	}
+	return rows
+}
+
+func (a *App) exportPDFByMonth(scope reportScope, year int, month time.Month, interactive bool) (string, error) {
+	MonthlyTotals, err := a.getMonthlyTotals(scope, year, month)
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
 
-		weekSumHours := secondsToHours(MonthlyTotals.WeekSumTotals[week])
-		pdf.CellFormat(40, 10, fmt.Sprintf("(%s)", weekRanges[week]), "1", 0, "", false, 0, "")
-		pdf.CellFormat(width, 10, "TOTAL", "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", weekSumHours), "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, formatTime(MonthlyTotals.WeekSumTotals[week]), "1", 0, "", false, 0, "")
-		pdf.Ln(-1)
-		for project, seconds := range projectTotals {
-			if seconds == 0 {
-				continue
+	template, err := a.reportTemplate(scope)
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	// Resolve the output file from the export settings
+	pdfFilePath, err := a.exportFilePath(PDF, scope, year, month, interactive)
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+	if pdfFilePath == "" {
+		// Save dialog was cancelled
+		return "", nil
+	}
+
+	report := newPDFReport(template)
+	report.pdf.AddPage()
+	report.title(fmt.Sprintf("Work Hours for %s", scope.name))
+	report.address(scope.address)
+
+	width := report.projectColumnWidth(MonthlyTotals.ProjectTotals)
+	rounded := MonthlyTotals.Rounded
+	hoursHeaders, hoursWidths := rounded.pdfColumns()
+
+	for _, section := range template.sectionList() {
+		switch section {
+		case SectionSummary:
+			report.section(fmt.Sprintf("Month total for %s %s", scope.kind, scope.name), pdfTable{
+				headers: append([]string{"Month"}, hoursHeaders...),
+				widths:  append([]float64{pdfColumnWidth}, hoursWidths...),
+				rows:    []pdfRow{{cells: append(append([]string{month.String()}, hoursCells(MonthlyTotals.MonthlyTotal)...), rounded.totalCell()...)}},
+			})
+		case SectionCharts:
+			report.monthlyCharts(MonthlyTotals, year, month)
+		case SectionProjects:
+			report.section("Monthly breakdown", pdfTable{
+				headers: append([]string{"Project"}, hoursHeaders...),
+				widths:  append([]float64{width}, hoursWidths...),
+				rows:    projectRows(MonthlyTotals.ProjectTotals, rounded.billable(rounded.ProjectTotals)),
+			})
+			if len(MonthlyTotals.TaskTotals) > 0 {
+				report.section("Task breakdown", pdfTable{
+					headers: []string{"Project", "Task", "Hours", "Time (HH:MM:SS)"},
+					widths:  []float64{width, report.taskColumnWidth(MonthlyTotals.TaskTotals), pdfColumnWidth, pdfColumnWidth},
+					rows:    taskRows(MonthlyTotals.TaskTotals),
+				})
+			}
+		case SectionWeekly:
+			weekRanges := getWeekRanges(year, month)
+			var rows []pdfRow
+			for week := 1; week <= 5; week++ {
+				projectTotals, ok := MonthlyTotals.WeeklyTotals[week]
+				if !ok {
+					continue
+				}
+				rows = append(rows, groupRows(fmt.Sprintf("(%s)", weekRanges[week]), MonthlyTotals.WeekSumTotals[week], projectTotals, rounded.billable(rounded.WeeklyTotals[week]))...)
 			}
-			projectHours := secondsToHours(seconds)
-			pdf.CellFormat(40, 10, "", "1", 0, "", false, 0, "")
-			pdf.CellFormat(width, 10, project, "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", projectHours), "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, formatTime(seconds), "1", 0, "", false, 0, "")
-			pdf.Ln(-1)
+			report.section("Weekly breakdown", pdfTable{
+				headers: append([]string{"Week", "Project"}, hoursHeaders...),
+				widths:  append([]float64{pdfColumnWidth, width}, hoursWidths...),
+				rows:    rows,
+			})
+		case SectionDaily:
+			var rows []pdfRow
+			for _, date := range MonthlyTotals.Dates {
+				rows = append(rows, groupRows(date, MonthlyTotals.DateSumTotals[date], MonthlyTotals.DailyTotals[date], rounded.billable(rounded.DailyTotals[date]))...)
+			}
+			report.section("Daily breakdown", pdfTable{
+				headers: append([]string{"Date", "Project"}, hoursHeaders...),
+				widths:  append([]float64{pdfColumnWidth, width}, hoursWidths...),
+				rows:    rows,
+			})
 		}
 	}
 
-	// Add space between tables
-	pdf.Ln(-1)
-
-	// Write table header for daily breakdown
-	pdf.Cell(40, 10, "Daily breakdown")
-	pdf.Ln(-1)
-	pdf.CellFormat(40, 10, "Date", "1", 0, "", false, 0, "")
-	pdf.CellFormat(width, 10, "Project", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-
-	// Write daily totals
-	for _, date := range MonthlyTotals.Dates {
-		// check that at least one project has time logged
-		var logDate bool
-		for _, seconds := range MonthlyTotals.DailyTotals[date] {
-			if seconds > 0 {
-				logDate = true
-				break
-			}
+	// Time tracked on several projects at once is counted for each of them, list it so it can be checked
+	if len(MonthlyTotals.Overlaps) > 0 {
+		var rows []pdfRow
+		for _, overlap := range MonthlyTotals.Overlaps {
+			rows = append(rows, pdfRow{cells: append([]string{overlap.Date}, overlapCells(overlap)...)})
 		}
-		if !logDate {
-			continue
+		report.section("Overlapping time (counted once per project)", pdfTable{
+			headers: []string{"Date", "From", "To", "Projects", "Hours", "Time (HH:MM:SS)"},
+			widths:  []float64{25, 15, 15, 75, 20, 35},
+			rows:    rows,
+		})
+	}
+	report.overtime(MonthlyTotals.Overtime, "Week", month.String(), weekLabel)
+	if len(MonthlyTotals.Absences) > 0 {
+		var rows []pdfRow
+		for _, absence := range MonthlyTotals.Absences {
+			rows = append(rows, pdfRow{cells: absenceCells(absence)})
 		}
-
-		dateHours := secondsToHours(MonthlyTotals.DateSumTotals[date])
-		pdf.CellFormat(40, 10, date, "1", 0, "", false, 0, "")
-		pdf.CellFormat(width, 10, "TOTAL", "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", dateHours), "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, formatTime(MonthlyTotals.DateSumTotals[date]), "1", 0, "", false, 0, "")
-		pdf.Ln(-1)
-		for project, seconds := range MonthlyTotals.DailyTotals[date] {
-			if seconds == 0 {
-				continue
-			}
-			projectHours := secondsToHours(seconds)
-			pdf.CellFormat(40, 10, "", "1", 0, "", false, 0, "")
-			pdf.CellFormat(width, 10, project, "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", projectHours), "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, formatTime(seconds), "1", 0, "", false, 0, "")
-			pdf.Ln(-1)
+		report.section("Absences", pdfTable{
+			headers: []string{"Date", "Type", "Name", "Hours"},
+			widths:  []float64{30, 40, 85, 30},
+			rows:    rows,
+		})
+	}
+	if len(MonthlyTotals.Compliance) > 0 {
+		var rows []pdfRow
+		for _, violation := range MonthlyTotals.Compliance {
+			rows = append(rows, pdfRow{cells: complianceCells(violation)})
 		}
+		report.section("Working-time violations", pdfTable{
+			headers: []string{"Date", "Rule", "Details"},
+			widths:  []float64{25, 40, 125},
+			rows:    rows,
+		})
+	}
+	if len(MonthlyTotals.Locks) > 0 {
+		var rows []pdfRow
+		for _, lock := range MonthlyTotals.Locks {
+			rows = append(rows, pdfRow{cells: lockCells(lock)})
+		}
+		report.section("Period status", pdfTable{
+			headers: []string{"Period", "Status", "Since", "Note"},
+			widths:  []float64{45, 30, 30, 85},
+			rows:    rows,
+		})
+	}
+	report.reconciliation(rounded, MonthlyTotals.MonthlyTotal)
+
+	if template.SignatureBlock {
+		report.signature()
 	}
 
 	// Save the PDF
-	dateStr := fmt.Sprintf("%d-%s", year, month.String())
-	pdfFileName := fmt.Sprintf("work_hours_%s.pdf", dateStr)
-	pdfFilePath := filepath.Join(dir, pdfFileName)
-	err = pdf.OutputFileAndClose(pdfFilePath)
+	err = report.save(pdfFilePath)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-	runtime.BrowserOpenURL(a.ctx, pdfFilePath)
+	if interactive {
+		runtime.BrowserOpenURL(a.ctx, pdfFilePath)
+	}
 	return pdfFilePath, nil
 }
 
-func (a *App) exportPDFByYear(organization string, year int) (string, error) {
-	YearlyTotals, err := a.getYearlyTotals(organization, year)
+func (a *App) exportPDFByYear(scope reportScope, year int, interactive bool) (string, error) {
+	YearlyTotals, err := a.getYearlyTotals(scope, year)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 
-	// Get the save directory
-	dbDir, err := getSaveDir(a.environment)
+	template, err := a.reportTemplate(scope)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 
-	// Create the directories for the organization and year
-	dir := filepath.Join(dbDir, "pdf", organization, strconv.Itoa(year))
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	// Resolve the output file from the export settings
+	pdfFilePath, err := a.exportFilePath(PDF, scope, year, 0, interactive)
+	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-
-	// Create a new PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-
-	// Add a page
-	pdf.AddPage()
-
-	// Set font
-	pdf.SetFont("Arial", "B", 16)
-
-	// Write title
-	pdf.Cell(40, 10, fmt.Sprintf("Work Hours for %s", organization))
-	pdf.Ln(-1)
-
-	// Set font for table
-	pdf.SetFont("Arial", "", 12)
-
-	// Write title
-	pdf.Cell(40, 10, fmt.Sprintf("Yearly total for organization %s", organization))
-	pdf.Ln(-1)
-
-	// Write table header for monthly total
-	pdf.CellFormat(40, 10, "Year", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-
-	// Write yearly total
-	yearlyHours := secondsToHours(YearlyTotals.YearlyTotal)
-	pdf.CellFormat(40, 10, strconv.Itoa(year), "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", yearlyHours), "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, formatTime(YearlyTotals.YearlyTotal), "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-
-	// Add space between tables
-	pdf.Ln(-1)
-
-	// find the project with the longest name to set the width of the project column
-	var longestProjectName string
-	for _, projectTotal := range YearlyTotals.ProjectTotals {
-		if len(projectTotal.Name) > len(longestProjectName) {
-			longestProjectName = projectTotal.Name
-		}
+	if pdfFilePath == "" {
+		// Save dialog was cancelled
+		return "", nil
 	}
 
-	width := 40.0
-	if pdf.GetStringWidth(longestProjectName) > 40 {
-		width = pdf.GetStringWidth(longestProjectName) + 5
-	}
-
-	// Write table for yearly per project breakdown
-	pdf.Cell(40, 10, "Yearly breakdown")
-	pdf.Ln(-1)
-	pdf.CellFormat(width, 10, "Project", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-	for _, yearlyTotal := range YearlyTotals.ProjectTotals {
-		if yearlyTotal.Seconds == 0 {
-			continue
+	report := newPDFReport(template)
+	report.pdf.AddPage()
+	report.title(fmt.Sprintf("Work Hours for %s", scope.name))
+	report.address(scope.address)
+
+	width := report.projectColumnWidth(YearlyTotals.ProjectTotals)
+	rounded := YearlyTotals.Rounded
+	hoursHeaders, hoursWidths := rounded.pdfColumns()
+
+	for _, section := range template.sectionList() {
+		switch section {
+		case SectionSummary:
+			report.section(fmt.Sprintf("Yearly total for %s %s", scope.kind, scope.name), pdfTable{
+				headers: append([]string{"Year"}, hoursHeaders...),
+				widths:  append([]float64{pdfColumnWidth}, hoursWidths...),
+				rows:    []pdfRow{{cells: append(append([]string{strconv.Itoa(year)}, hoursCells(YearlyTotals.YearlyTotal)...), rounded.totalCell()...)}},
+			})
+		case SectionCharts:
+			report.yearlyCharts(YearlyTotals)
+		case SectionProjects:
+			report.section("Yearly breakdown", pdfTable{
+				headers: append([]string{"Project"}, hoursHeaders...),
+				widths:  append([]float64{width}, hoursWidths...),
+				rows:    projectRows(YearlyTotals.ProjectTotals, rounded.billable(rounded.ProjectTotals)),
+			})
+		case SectionMonthly:
+			var rows []pdfRow
+			for mIdx := time.January; mIdx <= time.December; mIdx++ {
+				month := monthMap[int(mIdx)]
+				projectTotals, ok := YearlyTotals.MonthlyTotals[month]
+				if !ok {
+					continue
+				}
+				rows = append(rows, groupRows(month, YearlyTotals.MonthSumTotals[month], projectTotals, rounded.billable(rounded.MonthlyTotals[month]))...)
+			}
+			report.section("Monthly breakdown", pdfTable{
+				headers: append([]string{"Month", "Project"}, hoursHeaders...),
+				widths:  append([]float64{pdfColumnWidth, width}, hoursWidths...),
+				rows:    rows,
+			})
 		}
-
-		projectHours := secondsToHours(yearlyTotal.Seconds)
-		pdf.CellFormat(width, 10, yearlyTotal.Name, "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", projectHours), "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, formatTime(yearlyTotal.Seconds), "1", 0, "", false, 0, "")
-		pdf.Ln(-1)
 	}
-
-	// Add space between tables
-	pdf.Ln(-1)
-
-	// Write table header for monthly breakdown
-	pdf.Cell(40, 10, "Monthly breakdown")
-	pdf.Ln(-1)
-	pdf.CellFormat(40, 10, "Month", "1", 0, "", false, 0, "")
-	pdf.CellFormat(width, 10, "Project", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Hours", "1", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "Time (HH:MM:SS)", "1", 0, "", false, 0, "")
-	pdf.Ln(-1)
-
-	// Write monthly totals
-	for mIdx := time.January; mIdx <= time.December; mIdx++ {
-		month := monthMap[int(mIdx)]
-		if _, ok := YearlyTotals.MonthlyTotals[month]; !ok {
-			continue
+	report.overtime(YearlyTotals.Overtime, "Month", strconv.Itoa(year), monthLabel)
+	if len(YearlyTotals.Leave) > 0 {
+		var rows []pdfRow
+		for _, balance := range YearlyTotals.Leave {
+			rows = append(rows, pdfRow{cells: leaveCells(balance)})
 		}
-		projectTotals := YearlyTotals.MonthlyTotals[month]
-
-		// check that at least one project has time logged
-		var logMonth bool
-		for _, seconds := range projectTotals {
-			if seconds > 0 {
-				logMonth = true
-				break
-			}
+		report.section("Leave (days)", pdfTable{
+			headers: []string{"Type", "Allowance", "Carried over", "Taken", "Remaining"},
+			widths:  []float64{pdfColumnWidth, 36, 36, 36, 36},
+			rows:    rows,
+		})
+	}
+	if len(YearlyTotals.Compliance) > 0 {
+		var rows []pdfRow
+		for _, cells := range complianceCounts(YearlyTotals.Compliance) {
+			rows = append(rows, pdfRow{cells: cells})
 		}
-		if !logMonth {
-			continue
+		report.section("Working-time violations", pdfTable{
+			headers: []string{"Month", "Rule", "Violations"},
+			widths:  []float64{pdfColumnWidth, pdfColumnWidth, pdfColumnWidth},
+			rows:    rows,
+		})
+	}
+	if len(YearlyTotals.Locks) > 0 {
+		var rows []pdfRow
+		for _, lock := range YearlyTotals.Locks {
+			rows = append(rows, pdfRow{cells: lockCells(lock)})
 		}
+		report.section("Period status", pdfTable{
+			headers: []string{"Period", "Status", "Since", "Note"},
+			widths:  []float64{45, 30, 30, 85},
+			rows:    rows,
+		})
+	}
+	report.reconciliation(rounded, YearlyTotals.YearlyTotal)
 
-		monthlyHours := secondsToHours(YearlyTotals.MonthSumTotals[month])
-		pdf.CellFormat(40, 10, month, "1", 0, "", false, 0, "")
-		pdf.CellFormat(width, 10, "TOTAL", "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", monthlyHours), "1", 0, "", false, 0, "")
-		pdf.CellFormat(40, 10, formatTime(YearlyTotals.MonthSumTotals[month]), "1", 0, "", false, 0, "")
-		pdf.Ln(-1)
-		for project, seconds := range projectTotals {
-			if seconds == 0 {
-				continue
-			}
-
-			projectHours := secondsToHours(seconds)
-			pdf.CellFormat(40, 10, "", "1", 0, "", false, 0, "")
-			pdf.CellFormat(width, 10, project, "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", projectHours), "1", 0, "", false, 0, "")
-			pdf.CellFormat(40, 10, formatTime(seconds), "1", 0, "", false, 0, "")
-			pdf.Ln(-1)
-		}
+	if template.SignatureBlock {
+		report.signature()
 	}
 
 	// Save the PDF
-	pdfFileName := fmt.Sprintf("work_hours_%d.pdf", year)
-	pdfFilePath := filepath.Join(dir, pdfFileName)
-	err = pdf.OutputFileAndClose(pdfFilePath)
+	err = report.save(pdfFilePath)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-	runtime.BrowserOpenURL(a.ctx, pdfFilePath)
+	if interactive {
+		runtime.BrowserOpenURL(a.ctx, pdfFilePath)
+	}
 	return pdfFilePath, nil
 }
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+// HoursDiscrepancy is a project day whose WorkHours don't match the sum of its sessions
+type HoursDiscrepancy struct {
+	ProjectID      uint   `json:"project_id"`
+	Date           string `json:"date"`
+	HoursSeconds   int    `json:"hours_seconds"`   // sum of the WorkHours entries
+	SessionSeconds int    `json:"session_seconds"` // sum of the sessions
+	HoursEntries   int    `json:"hours_entries"`
+	Sessions       int    `json:"sessions"`
+	// Hours above the session total may hold time whose session was never saved (e.g. after a crash),
+	// those days are only repaired on request
+	Repairable bool `json:"repairable"`
+	Locked     bool `json:"locked"` // in a locked period, never repaired
+}
+
+// ReconciliationReport is the result of comparing WorkHours with the sessions
+type ReconciliationReport struct {
+	CheckedAt     time.Time          `json:"checked_at"`
+	Discrepancies []HoursDiscrepancy `json:"discrepancies"`
+	Repaired      int                `json:"repaired"`
+}
+
+type dayTotal struct {
+	ProjectID uint
+	Date      string
+	Seconds   int
+	Count     int
+}
+
+type projectDay struct {
+	projectID uint
+	date      string
+}
+
+// findDiscrepancies compares the per day WorkHours of live projects with the sum of their sessions
+func findDiscrepancies(tx *gorm.DB) ([]HoursDiscrepancy, error) {
+	liveProjects := tx.Model(&Project{}).Select("id")
+
+	var hours []dayTotal
+	err := tx.Model(&WorkHours{}).
+		Select("project_id, date, SUM(seconds) AS seconds, COUNT(*) AS count").
+		Where("project_id IN (?)", liveProjects).
+		Group("project_id, date").
+		Scan(&hours).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []dayTotal
+	err = tx.Model(&WorkSession{}).
+		Select("project_id, date, SUM(seconds) AS seconds, COUNT(*) AS count").
+		Where("project_id IN (?)", liveProjects).
+		Group("project_id, date").
+		Scan(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[projectDay]*HoursDiscrepancy)
+	day := func(projectID uint, date string) *HoursDiscrepancy {
+		key := projectDay{projectID, date}
+		if days[key] == nil {
+			days[key] = &HoursDiscrepancy{ProjectID: projectID, Date: date}
+		}
+		return days[key]
+	}
+	for _, total := range hours {
+		d := day(total.ProjectID, total.Date)
+		d.HoursSeconds, d.HoursEntries = total.Seconds, total.Count
+	}
+	for _, total := range sessions {
+		d := day(total.ProjectID, total.Date)
+		d.SessionSeconds, d.Sessions = total.Seconds, total.Count
+	}
+
+	var discrepancies []HoursDiscrepancy
+	for _, d := range days {
+		switch {
+		case d.HoursSeconds < 0, d.HoursEntries > 1:
+			d.Repairable = true
+		case d.Sessions == 0:
+			// Tracked before sessions were recorded
+			continue
+		case d.HoursSeconds < d.SessionSeconds:
+			d.Repairable = true
+		case d.HoursSeconds == d.SessionSeconds:
+			continue
+		}
+		discrepancies = append(discrepancies, *d)
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].Date != discrepancies[j].Date {
+			return discrepancies[i].Date < discrepancies[j].Date
+		}
+		return discrepancies[i].ProjectID < discrepancies[j].ProjectID
+	})
+	return discrepancies, nil
+}
+
+// repairDiscrepancy merges the day's WorkHours into a single entry holding the sum of its sessions.
+// Without sessions, or with more hours than sessions unless forced, the existing total is kept but never below 0
+func repairDiscrepancy(tx *gorm.DB, discrepancy HoursDiscrepancy, force bool) error {
+	target := discrepancy.SessionSeconds
+	if discrepancy.Sessions == 0 || (discrepancy.HoursSeconds > discrepancy.SessionSeconds && !force) {
+		target = max(discrepancy.HoursSeconds, 0)
+	}
+	if target == discrepancy.HoursSeconds && discrepancy.HoursEntries == 1 {
+		return nil
+	}
+
+	var entries []WorkHours
+	err := tx.Where(&WorkHours{ProjectID: discrepancy.ProjectID, Date: discrepancy.Date}).Order("id").Find(&entries).Error
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return addWorkHours(tx, AuditRepair, discrepancy.ProjectID, discrepancy.Date, target)
+	}
+
+	var duplicates []uint
+	for _, entry := range entries[1:] {
+		duplicates = append(duplicates, entry.ID)
+	}
+	if err := deleteAudited[WorkHours](tx, AuditRepair, duplicates); err != nil {
+		return err
+	}
+
+	before := entries[0]
+	entry := entries[0]
+	entry.Seconds = target
+	if err := tx.Save(&entry).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, AuditRepair, AuditUpdate, before, entry)
+}
+
+// reconcileWorkHours checks the WorkHours against the sessions and optionally repairs them in one transaction.
+// force also repairs the days that aren't safe to repair automatically
+func (a *App) reconcileWorkHours(repair, force bool) (ReconciliationReport, error) {
+	report := ReconciliationReport{CheckedAt: time.Now().UTC()}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		discrepancies, err := findDiscrepancies(tx)
+		if err != nil {
+			return err
+		}
+		var days []projectDay
+		for _, discrepancy := range discrepancies {
+			days = append(days, projectDay{discrepancy.ProjectID, discrepancy.Date})
+		}
+		locked, err := lockedDays(tx, days)
+		if err != nil {
+			return err
+		}
+		for i, discrepancy := range discrepancies {
+			_, discrepancies[i].Locked = locked[projectDay{discrepancy.ProjectID, discrepancy.Date}]
+		}
+		report.Discrepancies = discrepancies
+		if !repair {
+			return nil
+		}
+		for _, discrepancy := range discrepancies {
+			if discrepancy.Locked || (!discrepancy.Repairable && !force) {
+				continue
+			}
+			if err := repairDiscrepancy(tx, discrepancy, force); err != nil {
+				return err
+			}
+			report.Repaired++
+		}
+		return nil
+	})
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+	return report, nil
+}
+
+// CheckWorkHours reports the days whose WorkHours don't match their sessions
+func (a *App) CheckWorkHours() (ReconciliationReport, error) {
+	return a.reconcileWorkHours(false, false)
+}
+
+// RepairWorkHours recomputes the WorkHours of inconsistent days from their sessions.
+// Days with more hours than sessions are only lowered to the session total with force
+func (a *App) RepairWorkHours(force bool) (ReconciliationReport, error) {
+	report, err := a.reconcileWorkHours(true, force)
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+	if report.Repaired > 0 && a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "hours-reconciled", report)
+	}
+	return report, nil
+}
+
+// reconcileOnStartup reports drift left behind by a crash or an older version, repairs are left to RepairWorkHours.
+// Sessions saved by older versions were dated by the day they ended, while their hours were counted on the
+// days they ran, so repairing those days unasked would raise their billed hours
+func (a *App) reconcileOnStartup() {
+	report, err := a.CheckWorkHours()
+	if err != nil {
+		log.Printf("Error checking work hours: %v", err)
+		return
+	}
+	if len(report.Discrepancies) > 0 {
+		log.Printf("Found %d work hours discrepancies, they can be repaired with RepairWorkHours", len(report.Discrepancies))
+	}
+}
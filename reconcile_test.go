@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// dayHours returns the WorkHours of a project's day
+func dayHours(t *testing.T, a *App, projectID uint, date string) int {
+	t.Helper()
+	var seconds int
+	if err := a.db.Model(&WorkHours{}).Where(&WorkHours{ProjectID: projectID, Date: date}).
+		Select("COALESCE(SUM(seconds), 0)").Scan(&seconds).Error; err != nil {
+		t.Fatal(err)
+	}
+	return seconds
+}
+
+func TestLegacySessionPastMidnight(t *testing.T) {
+	a := newTestApp(t)
+	created, err := a.NewOrganization("Acme", "Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.Project.ID
+	a.db.Where(&WorkHours{ProjectID: projectID}).Delete(&WorkHours{})
+
+	// An older version counted the hours on the days the timer ran but dated the session by the day it ended
+	startedAt := time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)
+	session := WorkSession{UID: newUID(), Date: "2026-03-03", Seconds: 7200, ProjectID: projectID,
+		StartedAt: startedAt, EndedAt: startedAt.Add(2 * time.Hour), Timezone: "UTC"}
+	if err := a.db.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, date := range []string{"2026-03-02", "2026-03-03"} {
+		if err := a.db.Create(&WorkHours{ProjectID: projectID, Date: date, Seconds: 3600}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Startup only reports the day, its billed hours stay as tracked
+	a.reconcileOnStartup()
+	if got := dayHours(t, a, projectID, "2026-03-03"); got != 3600 {
+		t.Errorf("hours of 2026-03-03 after startup: %d, want 3600", got)
+	}
+	report, err := a.CheckWorkHours()
+	if err != nil || len(report.Discrepancies) != 1 || report.Discrepancies[0].Date != "2026-03-03" {
+		t.Errorf("discrepancies: %+v, %v, want 2026-03-03", report.Discrepancies, err)
+	}
+
+	// Deleting the session takes off no more than the day holds, and undoing puts back what was taken
+	if err := a.DeleteWorkSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := dayHours(t, a, projectID, "2026-03-03"); got != 0 {
+		t.Errorf("hours after deleting the session: %d, want 0", got)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := dayHours(t, a, projectID, "2026-03-03"); got != 3600 {
+		t.Errorf("hours after undoing: %d, want 3600", got)
+	}
+}
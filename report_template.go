@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+// ReportTemplate controls the layout and branding of an organization's PDF reports
+type ReportTemplate struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	OrganizationID uint      `gorm:"uniqueIndex" json:"organization_id"`
+	PageSize       string    `json:"page_size"`   // A3, A4, A5, Letter or Legal
+	Orientation    string    `json:"orientation"` // P (portrait) or L (landscape)
+	FontFamily     string    `json:"font_family"` // one of the PDF core fonts
+	LogoPath       string    `json:"logo_path"`   // PNG, JPG or GIF drawn in the page header
+	HeaderText     string    `json:"header_text"`
+	FooterText     string    `json:"footer_text"`
+	PrimaryColor   string    `json:"primary_color"` // titles and table headers, as #RRGGBB
+	AccentColor    string    `json:"accent_color"`  // background of total rows, as #RRGGBB
+	TextColor      string    `json:"text_color"`
+	Sections       string    `json:"sections"` // comma separated, in render order
+	PageNumbers    bool      `json:"page_numbers"`
+	SignatureBlock bool      `json:"signature_block"`
+	SignatureLabel string    `json:"signature_label"`
+}
+
+// Report sections, monthly reports render summary/projects/weekly/daily and yearly reports summary/projects/monthly.
+// Both render charts, leaving the section out of the template turns them off
+const (
+	SectionSummary  = "summary"
+	SectionProjects = "projects"
+	SectionWeekly   = "weekly"
+	SectionDaily    = "daily"
+	SectionMonthly  = "monthly"
+	SectionCharts   = "charts"
+)
+
+var reportSections = map[string]bool{
+	SectionSummary:  true,
+	SectionProjects: true,
+	SectionWeekly:   true,
+	SectionDaily:    true,
+	SectionMonthly:  true,
+	SectionCharts:   true,
+}
+
+var reportPageSizes = map[string]bool{
+	"A3":     true,
+	"A4":     true,
+	"A5":     true,
+	"Letter": true,
+	"Legal":  true,
+}
+
+var reportFonts = map[string]bool{
+	"Arial":     true,
+	"Helvetica": true,
+	"Times":     true,
+	"Courier":   true,
+}
+
+func defaultReportTemplate(organizationID uint) ReportTemplate {
+	return ReportTemplate{
+		OrganizationID: organizationID,
+		PageSize:       "A4",
+		Orientation:    "P",
+		FontFamily:     "Arial",
+		PrimaryColor:   "#1B2636",
+		AccentColor:    "#F0F0F0",
+		TextColor:      "#000000",
+		Sections:       strings.Join([]string{SectionSummary, SectionCharts, SectionProjects, SectionWeekly, SectionDaily, SectionMonthly}, ","),
+		PageNumbers:    true,
+		SignatureLabel: "Approved by",
+	}
+}
+
+// sectionList returns the template's sections in render order
+func (t ReportTemplate) sectionList() []string {
+	var sections []string
+	for _, section := range strings.Split(t.Sections, ",") {
+		section = strings.TrimSpace(section)
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// parseHexColor converts #RRGGBB into its RGB components
+func parseHexColor(color string) (r, g, b int, err error) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid color %q, expected #RRGGBB", color)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q, expected #RRGGBB", color)
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF), nil
+}
+
+func validateReportTemplate(template ReportTemplate) error {
+	if !reportPageSizes[template.PageSize] {
+		return fmt.Errorf("invalid page size %q", template.PageSize)
+	}
+	if template.Orientation != "P" && template.Orientation != "L" {
+		return fmt.Errorf("invalid orientation %q", template.Orientation)
+	}
+	if !reportFonts[template.FontFamily] {
+		return fmt.Errorf("invalid font %q", template.FontFamily)
+	}
+	for _, color := range []string{template.PrimaryColor, template.AccentColor, template.TextColor} {
+		if _, _, _, err := parseHexColor(color); err != nil {
+			return err
+		}
+	}
+	for _, section := range template.sectionList() {
+		if !reportSections[section] {
+			return fmt.Errorf("invalid report section %q", section)
+		}
+	}
+	if template.LogoPath != "" {
+		if _, err := os.Stat(template.LogoPath); err != nil {
+			return fmt.Errorf("logo not found: %s", template.LogoPath)
+		}
+	}
+	return nil
+}
+
+// getReportTemplate returns the organization's template, or the default one if it hasn't been customized
+func (a *App) getReportTemplate(organizationID uint) (ReportTemplate, error) {
+	var template ReportTemplate
+	err := a.db.Where(&ReportTemplate{OrganizationID: organizationID}).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultReportTemplate(organizationID), nil
+	}
+	if err != nil {
+		return ReportTemplate{}, err
+	}
+	return template, nil
+}
+
+// GetReportTemplate returns the PDF report template for the specified organization
+func (a *App) GetReportTemplate(organizationID uint) (ReportTemplate, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return ReportTemplate{}, err
+	}
+	return a.getReportTemplate(organizationID)
+}
+
+// SaveReportTemplate validates and stores the PDF report template of an organization
+func (a *App) SaveReportTemplate(template ReportTemplate) (ReportTemplate, error) {
+	if _, err := a.getOrganization(template.OrganizationID); err != nil {
+		return ReportTemplate{}, err
+	}
+	if err := validateReportTemplate(template); err != nil {
+		return ReportTemplate{}, invalid(err)
+	}
+
+	current, err := a.getReportTemplate(template.OrganizationID)
+	if err != nil {
+		return ReportTemplate{}, err
+	}
+	template.ID = current.ID
+	template.CreatedAt = current.CreatedAt
+
+	if err := a.db.Save(&template).Error; err != nil {
+		return ReportTemplate{}, err
+	}
+	return template, nil
+}
+
+// ResetReportTemplate restores the default PDF report template for the specified organization
+func (a *App) ResetReportTemplate(organizationID uint) (ReportTemplate, error) {
+	if err := a.db.Where(&ReportTemplate{OrganizationID: organizationID}).Delete(&ReportTemplate{}).Error; err != nil {
+		return ReportTemplate{}, err
+	}
+	return defaultReportTemplate(organizationID), nil
+}
+
+// SelectReportLogo lets the user pick an image to use as the report logo
+func (a *App) SelectReportLogo() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select report logo",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Images (*.png;*.jpg;*.jpeg;*.gif)", Pattern: "*.png;*.jpg;*.jpeg;*.gif"},
+		},
+	})
+}
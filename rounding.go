@@ -0,0 +1,374 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RoundingMode string
+
+const (
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+	RoundNearest RoundingMode = "nearest"
+)
+
+// RoundingLevel is what a rounding increment is applied to
+type RoundingLevel string
+
+const (
+	RoundPerSession RoundingLevel = "session"
+	RoundPerDay     RoundingLevel = "day"
+	RoundPerPeriod  RoundingLevel = "period" // the month, yearly reports sum the rounded months
+)
+
+// RoundingPolicy is how an organization bills its tracked time, an increment of 0 bills the exact time
+type RoundingPolicy struct {
+	ID               uint          `gorm:"primarykey" json:"id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+	OrganizationID   uint          `gorm:"uniqueIndex" json:"organization_id"`
+	IncrementMinutes int           `json:"increment_minutes"`
+	Mode             RoundingMode  `json:"mode"`
+	Level            RoundingLevel `json:"level"`
+}
+
+func defaultRoundingPolicy(organizationID uint) RoundingPolicy {
+	return RoundingPolicy{OrganizationID: organizationID, Mode: RoundNearest, Level: RoundPerDay}
+}
+
+func validateRoundingPolicy(policy RoundingPolicy) error {
+	if policy.IncrementMinutes < 0 || policy.IncrementMinutes > 24*60 {
+		return errors.New("rounding increment must be between 0 and 1440 minutes")
+	}
+	switch policy.Mode {
+	case RoundUp, RoundDown, RoundNearest:
+	default:
+		return fmt.Errorf("invalid rounding mode %q", policy.Mode)
+	}
+	switch policy.Level {
+	case RoundPerSession, RoundPerDay, RoundPerPeriod:
+	default:
+		return fmt.Errorf("invalid rounding level %q", policy.Level)
+	}
+	return nil
+}
+
+func (p RoundingPolicy) enabled() bool {
+	return p.IncrementMinutes > 0
+}
+
+// round rounds seconds to the policy's increment
+func (p RoundingPolicy) round(seconds int) int {
+	increment := p.IncrementMinutes * 60
+	if increment <= 0 || seconds <= 0 {
+		return seconds
+	}
+	switch p.Mode {
+	case RoundUp:
+		return (seconds + increment - 1) / increment * increment
+	case RoundDown:
+		return seconds / increment * increment
+	default:
+		return (seconds + increment/2) / increment * increment
+	}
+}
+
+func (p RoundingPolicy) String() string {
+	if !p.enabled() {
+		return "exact time"
+	}
+	return fmt.Sprintf("%d min, %s, per %s", p.IncrementMinutes, p.Mode, p.Level)
+}
+
+// RoundedTotals are the billable counterparts of the raw totals of a report
+type RoundedTotals struct {
+	Enabled       bool                      // at least one organization in the report rounds its time
+	Policies      []string                  // descriptions of the policies in use
+	DailyTotals   map[string]map[string]int // map[date]map[project]seconds, raw for period rounding
+	DateSumTotals map[string]int
+	WeeklyTotals  map[int]map[string]int
+	WeekSumTotals map[int]int
+	MonthlyTotals map[string]map[string]int // map[month]map[project]seconds
+	ProjectTotals map[string]int
+	Total         int
+}
+
+func (a *App) getRoundingPolicy(organizationID uint) (RoundingPolicy, error) {
+	var policy RoundingPolicy
+	err := a.db.Where("organization_id = ?", organizationID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultRoundingPolicy(organizationID), nil
+	}
+	return policy, err
+}
+
+// GetRoundingPolicy returns the billing rounding policy of the organization
+func (a *App) GetRoundingPolicy(organizationID uint) (RoundingPolicy, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return RoundingPolicy{}, err
+	}
+	policy, err := a.getRoundingPolicy(organizationID)
+	if err != nil {
+		return RoundingPolicy{}, toAppError(err)
+	}
+	return policy, nil
+}
+
+// SaveRoundingPolicy validates and stores the billing rounding policy of an organization
+func (a *App) SaveRoundingPolicy(policy RoundingPolicy) (RoundingPolicy, error) {
+	if _, err := a.getOrganization(policy.OrganizationID); err != nil {
+		return RoundingPolicy{}, err
+	}
+	if err := validateRoundingPolicy(policy); err != nil {
+		return RoundingPolicy{}, invalid(err)
+	}
+
+	existing, err := a.getRoundingPolicy(policy.OrganizationID)
+	if err != nil {
+		return RoundingPolicy{}, toAppError(err)
+	}
+	policy.ID = existing.ID
+	policy.CreatedAt = existing.CreatedAt
+	if err := a.db.Save(&policy).Error; err != nil {
+		return RoundingPolicy{}, toAppError(err)
+	}
+	return policy, nil
+}
+
+type labeledSeconds struct {
+	Date           string
+	Label          string
+	OrganizationID uint
+	Seconds        int
+}
+
+// roundedDays returns the billable seconds per date and project after session and day rounding,
+// along with the policy of each project so period rounding can be applied to the period totals
+func (a *App) roundedDays(scope reportScope, startDate, endDate string) (map[string]map[string]int, map[string]RoundingPolicy, error) {
+	var stored []RoundingPolicy
+	if err := a.db.Find(&stored).Error; err != nil {
+		return nil, nil, err
+	}
+	policies := make(map[uint]RoundingPolicy)
+	for _, policy := range stored {
+		policies[policy.OrganizationID] = policy
+	}
+
+	query := func(table string, group bool) ([]labeledSeconds, error) {
+		var rows []labeledSeconds
+		selectSeconds := table + ".seconds"
+		if group {
+			selectSeconds = "SUM(" + table + ".seconds)"
+		}
+		db := a.db.Table(table).
+			Select(table+".date, "+scope.label+" AS label, projects.organization_id, "+selectSeconds+" AS seconds").
+			Joins("JOIN projects ON projects.id = "+table+".project_id").
+			Where("projects.deleted_at IS NULL AND "+table+".deleted_at IS NULL"). // Ignore deleted projects and rows
+			Scopes(scope.filter).
+			Where(table+".date >= ? AND "+table+".date <= ?", startDate, endDate)
+		if group {
+			db = db.Group(table + ".date, label, projects.organization_id")
+		}
+		return rows, db.Scan(&rows).Error
+	}
+
+	hours, err := query("work_hours", true)
+	if err != nil {
+		return nil, nil, err
+	}
+	sessions, err := query("work_sessions", false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type dayKey struct{ date, label string }
+	sessionSums := make(map[dayKey]int)
+	roundedSessions := make(map[dayKey]int)
+	for _, session := range sessions {
+		key := dayKey{session.Date, session.Label}
+		sessionSums[key] += session.Seconds
+		roundedSessions[key] += policies[session.OrganizationID].round(session.Seconds)
+	}
+
+	days := make(map[string]map[string]int)
+	labelPolicies := make(map[string]RoundingPolicy)
+	for _, day := range hours {
+		policy, ok := policies[day.OrganizationID]
+		if !ok {
+			policy = defaultRoundingPolicy(day.OrganizationID)
+		}
+		labelPolicies[day.Label] = policy
+
+		rounded := day.Seconds
+		if policy.enabled() {
+			switch policy.Level {
+			case RoundPerDay:
+				rounded = policy.round(day.Seconds)
+			case RoundPerSession:
+				// Time without a session (tracked before sessions were recorded) is rounded as one more session
+				key := dayKey{day.Date, day.Label}
+				rounded = roundedSessions[key] + policy.round(max(day.Seconds-sessionSums[key], 0))
+			}
+		}
+		if days[day.Date] == nil {
+			days[day.Date] = make(map[string]int)
+		}
+		days[day.Date][day.Label] += rounded
+	}
+	return days, labelPolicies, nil
+}
+
+// describePolicies returns whether any of the policies rounds and their distinct descriptions
+func describePolicies(policies map[string]RoundingPolicy) (bool, []string) {
+	enabled := false
+	seen := make(map[string]bool)
+	var descriptions []string
+	for _, policy := range policies {
+		enabled = enabled || policy.enabled()
+		if description := policy.String(); !seen[description] {
+			seen[description] = true
+			descriptions = append(descriptions, description)
+		}
+	}
+	sort.Strings(descriptions)
+	return enabled, descriptions
+}
+
+// roundMonth applies the rounding policies to a month, rawProjects holds the raw seconds per project
+func (a *App) roundMonth(scope reportScope, year int, month time.Month, rawProjects map[string]int) (RoundedTotals, error) {
+	period := fmt.Sprintf("%04d-%02d", year, month)
+	days, policies, err := a.roundedDays(scope, period+"-01", period+"-31")
+	if err != nil {
+		return RoundedTotals{}, err
+	}
+
+	rounded := RoundedTotals{
+		DailyTotals:   days,
+		DateSumTotals: make(map[string]int),
+		WeeklyTotals:  make(map[int]map[string]int),
+		WeekSumTotals: make(map[int]int),
+		ProjectTotals: make(map[string]int),
+	}
+	rounded.Enabled, rounded.Policies = describePolicies(policies)
+	for date, projects := range days {
+		parsedDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return RoundedTotals{}, err
+		}
+		week := a.GetWeekOfMonth(year, month, parsedDate.Day())
+		if rounded.WeeklyTotals[week] == nil {
+			rounded.WeeklyTotals[week] = make(map[string]int)
+		}
+		for project, seconds := range projects {
+			rounded.DateSumTotals[date] += seconds
+			rounded.WeeklyTotals[week][project] += seconds
+			rounded.WeekSumTotals[week] += seconds
+			rounded.ProjectTotals[project] += seconds
+		}
+	}
+
+	// Period rounding only applies to the month's totals
+	for project, seconds := range rawProjects {
+		if policy := policies[project]; policy.enabled() && policy.Level == RoundPerPeriod {
+			rounded.ProjectTotals[project] = policy.round(seconds)
+		}
+	}
+	for _, seconds := range rounded.ProjectTotals {
+		rounded.Total += seconds
+	}
+	return rounded, nil
+}
+
+// roundYear applies the rounding policies to each month of a year, the year's totals are the sums of the rounded months
+func (a *App) roundYear(scope reportScope, year int, rawMonths map[string]map[string]int) (RoundedTotals, error) {
+	rounded := RoundedTotals{
+		MonthlyTotals: make(map[string]map[string]int),
+		ProjectTotals: make(map[string]int),
+	}
+	seen := make(map[string]bool)
+	for month := time.January; month <= time.December; month++ {
+		rawProjects, ok := rawMonths[monthMap[int(month)]]
+		if !ok {
+			continue
+		}
+		monthTotals, err := a.roundMonth(scope, year, month, rawProjects)
+		if err != nil {
+			return RoundedTotals{}, err
+		}
+		rounded.Enabled = rounded.Enabled || monthTotals.Enabled
+		for _, description := range monthTotals.Policies {
+			if !seen[description] {
+				seen[description] = true
+				rounded.Policies = append(rounded.Policies, description)
+			}
+		}
+		rounded.MonthlyTotals[monthMap[int(month)]] = monthTotals.ProjectTotals
+		for project, seconds := range monthTotals.ProjectTotals {
+			rounded.ProjectTotals[project] += seconds
+		}
+		rounded.Total += monthTotals.Total
+	}
+	return rounded, nil
+}
+
+// headers returns the billable columns of an export, none when no organization rounds its time
+func (r RoundedTotals) headers() []string {
+	if !r.Enabled {
+		return nil
+	}
+	return []string{"Billable hours", "Billable time (HH:MM:SS)"}
+}
+
+// cells returns the billable cells matching headers
+func (r RoundedTotals) cells(seconds int) []string {
+	if !r.Enabled {
+		return nil
+	}
+	return []string{fmt.Sprintf("%.2f", secondsToHours(seconds)), formatTime(seconds)}
+}
+
+// reconciliation returns the raw, billable and difference cells of a report's total
+func (r RoundedTotals) reconciliation(raw int) []string {
+	return []string{
+		strings.Join(r.Policies, "; "),
+		fmt.Sprintf("%.2f", secondsToHours(raw)),
+		fmt.Sprintf("%.2f", secondsToHours(r.Total)),
+		signedHours(r.Total - raw),
+	}
+}
+
+// pdfColumns returns the hour columns of a PDF table with the billable hours when time is rounded
+func (r RoundedTotals) pdfColumns() ([]string, []float64) {
+	headers := []string{"Hours", "Time (HH:MM:SS)"}
+	widths := []float64{pdfColumnWidth, pdfColumnWidth}
+	if r.Enabled {
+		headers = append(headers, "Billable hours")
+		widths = append(widths, pdfColumnWidth)
+	}
+	return headers, widths
+}
+
+// totalCell returns the billable total cell of a PDF summary
+func (r RoundedTotals) totalCell() []string {
+	if !r.Enabled {
+		return nil
+	}
+	return []string{fmt.Sprintf("%.2f", secondsToHours(r.Total))}
+}
+
+// billable returns the billable seconds per project for PDF rows, nil when time isn't rounded
+func (r RoundedTotals) billable(projects map[string]int) map[string]int {
+	if !r.Enabled {
+		return nil
+	}
+	if projects == nil {
+		return map[string]int{}
+	}
+	return projects
+}
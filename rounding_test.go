@@ -0,0 +1,186 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoundingPolicyRound(t *testing.T) {
+	minutes := func(m int) int { return m * 60 }
+	tests := []struct {
+		mode      RoundingMode
+		increment int
+		seconds   int
+		want      int
+	}{
+		{RoundUp, 15, minutes(1), minutes(15)},
+		{RoundUp, 15, 1, minutes(15)},
+		{RoundUp, 15, minutes(15), minutes(15)},
+		{RoundUp, 15, minutes(15) + 1, minutes(30)},
+		{RoundDown, 15, minutes(14), 0},
+		{RoundDown, 15, minutes(29), minutes(15)},
+		{RoundDown, 15, minutes(30), minutes(30)},
+		{RoundNearest, 15, minutes(7), 0},
+		{RoundNearest, 15, minutes(7) + 30, minutes(15)}, // halfway rounds up
+		{RoundNearest, 15, minutes(22), minutes(15)},
+		{RoundNearest, 15, minutes(23), minutes(30)},
+		{RoundNearest, 6, minutes(100), minutes(102)},
+		{RoundUp, 60, minutes(61), minutes(120)},
+		{RoundUp, 0, 1234, 1234}, // exact time
+		{RoundUp, 15, 0, 0},
+		{RoundNearest, 15, -minutes(20), -minutes(20)}, // corrections aren't rounded
+	}
+	for _, test := range tests {
+		policy := RoundingPolicy{IncrementMinutes: test.increment, Mode: test.mode, Level: RoundPerSession}
+		if got := policy.round(test.seconds); got != test.want {
+			t.Errorf("%d min %s: round(%d) = %d, want %d", test.increment, test.mode, test.seconds, got, test.want)
+		}
+	}
+}
+
+func TestValidateRoundingPolicy(t *testing.T) {
+	tests := []struct {
+		policy  RoundingPolicy
+		wantErr bool
+	}{
+		{RoundingPolicy{IncrementMinutes: 15, Mode: RoundUp, Level: RoundPerDay}, false},
+		{RoundingPolicy{IncrementMinutes: 0, Mode: RoundNearest, Level: RoundPerPeriod}, false},
+		{RoundingPolicy{IncrementMinutes: -1, Mode: RoundUp, Level: RoundPerDay}, true},
+		{RoundingPolicy{IncrementMinutes: 24*60 + 1, Mode: RoundUp, Level: RoundPerDay}, true},
+		{RoundingPolicy{IncrementMinutes: 15, Mode: "ceil", Level: RoundPerDay}, true},
+		{RoundingPolicy{IncrementMinutes: 15, Mode: RoundUp, Level: "week"}, true},
+	}
+	for _, test := range tests {
+		if err := validateRoundingPolicy(test.policy); (err != nil) != test.wantErr {
+			t.Errorf("validateRoundingPolicy(%+v) = %v, want error %v", test.policy, err, test.wantErr)
+		}
+	}
+}
+
+// newRoundingApp returns an app with an organization "Acme" tracking on "Web":
+// March 2nd has sessions of 7 and 8 minutes and 5 minutes tracked before sessions were recorded,
+// March 3rd a single session of 22 minutes. That is 42 minutes raw
+func newRoundingApp(t *testing.T) (*App, uint) {
+	t.Helper()
+	a := newTestApp(t)
+	created, err := a.NewOrganization("Acme", "Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.Project.ID
+	rows := []any{
+		&WorkHours{Date: "2026-03-02", Seconds: 20 * 60, ProjectID: projectID},
+		&WorkHours{Date: "2026-03-03", Seconds: 22 * 60, ProjectID: projectID},
+		&WorkSession{Date: "2026-03-02", Seconds: 7 * 60, ProjectID: projectID},
+		&WorkSession{Date: "2026-03-02", Seconds: 8 * 60, ProjectID: projectID},
+		&WorkSession{Date: "2026-03-03", Seconds: 22 * 60, ProjectID: projectID},
+		// Other months and other organizations aren't part of the report
+		&WorkHours{Date: "2026-04-01", Seconds: 60 * 60, ProjectID: projectID},
+	}
+	other, err := a.NewOrganization("Other", "Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows = append(rows, &WorkHours{Date: "2026-03-02", Seconds: 90 * 60, ProjectID: other.Project.ID})
+	for _, row := range rows {
+		if err := a.db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a, created.Organization.ID
+}
+
+func TestRoundMonth(t *testing.T) {
+	const raw = 42 * 60
+	tests := []struct {
+		mode      RoundingMode
+		level     RoundingLevel
+		increment int
+		days      map[string]int // billable minutes per date
+		total     int            // billable minutes of the month
+		diff      string         // reconciliation difference in hours
+	}{
+		{RoundUp, RoundPerSession, 15, map[string]int{"2026-03-02": 45, "2026-03-03": 30}, 75, "+0.55"},
+		{RoundDown, RoundPerSession, 15, map[string]int{"2026-03-02": 0, "2026-03-03": 15}, 15, "-0.45"},
+		{RoundNearest, RoundPerSession, 15, map[string]int{"2026-03-02": 15, "2026-03-03": 15}, 30, "-0.20"},
+		{RoundUp, RoundPerDay, 15, map[string]int{"2026-03-02": 30, "2026-03-03": 30}, 60, "+0.30"},
+		{RoundDown, RoundPerDay, 15, map[string]int{"2026-03-02": 15, "2026-03-03": 15}, 30, "-0.20"},
+		{RoundNearest, RoundPerDay, 15, map[string]int{"2026-03-02": 15, "2026-03-03": 15}, 30, "-0.20"},
+		// Period rounding leaves the days raw and rounds the month's total
+		{RoundUp, RoundPerPeriod, 15, map[string]int{"2026-03-02": 20, "2026-03-03": 22}, 45, "+0.05"},
+		{RoundDown, RoundPerPeriod, 15, map[string]int{"2026-03-02": 20, "2026-03-03": 22}, 30, "-0.20"},
+		{RoundNearest, RoundPerPeriod, 15, map[string]int{"2026-03-02": 20, "2026-03-03": 22}, 45, "+0.05"},
+		{RoundUp, RoundPerDay, 0, map[string]int{"2026-03-02": 20, "2026-03-03": 22}, 42, "+0.00"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode)+"/"+string(test.level), func(t *testing.T) {
+			a, organizationID := newRoundingApp(t)
+			policy := RoundingPolicy{OrganizationID: organizationID, IncrementMinutes: test.increment, Mode: test.mode, Level: test.level}
+			if _, err := a.SaveRoundingPolicy(policy); err != nil {
+				t.Fatal(err)
+			}
+			scope, err := a.organizationScope("Acme")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rounded, err := a.roundMonth(scope, 2026, time.March, map[string]int{"Web": raw})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rounded.Enabled != (test.increment > 0) {
+				t.Errorf("Enabled = %v", rounded.Enabled)
+			}
+			days := make(map[string]int)
+			for date, projects := range rounded.DailyTotals {
+				days[date] = projects["Web"] / 60
+				if rounded.DateSumTotals[date] != projects["Web"] {
+					t.Errorf("%s: date sum %d, project %d", date, rounded.DateSumTotals[date], projects["Web"])
+				}
+			}
+			if !reflect.DeepEqual(days, test.days) {
+				t.Errorf("days = %v, want %v", days, test.days)
+			}
+			if rounded.Total != test.total*60 || rounded.ProjectTotals["Web"] != test.total*60 {
+				t.Errorf("total = %d min, project total %d min, want %d", rounded.Total/60, rounded.ProjectTotals["Web"]/60, test.total)
+			}
+
+			reconciliation := rounded.reconciliation(raw)
+			if reconciliation[0] != policy.String() || reconciliation[1] != "0.70" || reconciliation[3] != test.diff {
+				t.Errorf("reconciliation = %q, want %q raw 0.70 and difference %s", reconciliation, policy.String(), test.diff)
+			}
+		})
+	}
+}
+
+func TestRoundYear(t *testing.T) {
+	a, organizationID := newRoundingApp(t)
+	policy := RoundingPolicy{OrganizationID: organizationID, IncrementMinutes: 30, Mode: RoundUp, Level: RoundPerPeriod}
+	if _, err := a.SaveRoundingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	scope, err := a.organizationScope("Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each month is rounded on its own: 42 minutes in March and 60 in April
+	rounded, err := a.roundYear(scope, 2026, map[string]map[string]int{
+		monthMap[3]: {"Web": 42 * 60},
+		monthMap[4]: {"Web": 60 * 60},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rounded.MonthlyTotals[monthMap[3]]["Web"] != 60*60 || rounded.MonthlyTotals[monthMap[4]]["Web"] != 60*60 {
+		t.Errorf("monthly totals = %v", rounded.MonthlyTotals)
+	}
+	if rounded.Total != 120*60 || rounded.ProjectTotals["Web"] != 120*60 {
+		t.Errorf("year total = %d min, want 120", rounded.Total/60)
+	}
+	if !reflect.DeepEqual(rounded.Policies, []string{policy.String()}) {
+		t.Errorf("policies = %v", rounded.Policies)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkSchedule holds the contractual hours of an organization per weekday. The overtime balance
+// starts at OpeningBalanceSeconds on StartDate and adds the difference between the actual and
+// the expected hours of every day since, up to today
+type WorkSchedule struct {
+	ID                    uint      `gorm:"primarykey" json:"id"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	OrganizationID        uint      `gorm:"uniqueIndex" json:"organization_id"`
+	Enabled               bool      `json:"enabled"`
+	MondayMinutes         int       `json:"monday_minutes"`
+	TuesdayMinutes        int       `json:"tuesday_minutes"`
+	WednesdayMinutes      int       `json:"wednesday_minutes"`
+	ThursdayMinutes       int       `json:"thursday_minutes"`
+	FridayMinutes         int       `json:"friday_minutes"`
+	SaturdayMinutes       int       `json:"saturday_minutes"`
+	SundayMinutes         int       `json:"sunday_minutes"`
+	StartDate             string    `json:"start_date"`              // first day counted in the balance
+	OpeningBalanceSeconds int       `json:"opening_balance_seconds"` // balance carried over from before StartDate
+}
+
+// DayOvertime is the expected and the actual time of a day
+type DayOvertime struct {
+	Date            string `json:"date"`
+	ExpectedSeconds int    `json:"expected_seconds"` // scheduled time not covered by absences
+	AbsenceSeconds  int    `json:"absence_seconds"`  // scheduled time covered by absences
+	ActualSeconds   int    `json:"actual_seconds"`
+}
+
+// OvertimeSummary is the overtime of a period, days after today are not counted yet
+type OvertimeSummary struct {
+	Enabled           bool          `json:"enabled"` // false when the organization has no schedule
+	Start             string        `json:"start"`
+	End               string        `json:"end"`
+	Days              []DayOvertime `json:"days"` // days with time scheduled or logged
+	ExpectedSeconds   int           `json:"expected_seconds"`
+	AbsenceSeconds    int           `json:"absence_seconds"`
+	ActualSeconds     int           `json:"actual_seconds"`
+	DifferenceSeconds int           `json:"difference_seconds"`
+	CarryOverSeconds  int           `json:"carry_over_seconds"` // balance before the period
+	BalanceSeconds    int           `json:"balance_seconds"`    // balance at the end of the period
+}
+
+func defaultWorkSchedule(organizationID uint) WorkSchedule {
+	return WorkSchedule{
+		OrganizationID:   organizationID,
+		MondayMinutes:    8 * 60,
+		TuesdayMinutes:   8 * 60,
+		WednesdayMinutes: 8 * 60,
+		ThursdayMinutes:  8 * 60,
+		FridayMinutes:    8 * 60,
+		StartDate:        time.Now().Format("2006-01") + "-01",
+	}
+}
+
+func validateWorkSchedule(schedule WorkSchedule) error {
+	for _, minutes := range schedule.weekdayMinutes() {
+		if minutes < 0 || minutes > 24*60 {
+			return errors.New("scheduled minutes must be between 0 and 1440 per day")
+		}
+	}
+	if _, err := time.Parse("2006-01-02", schedule.StartDate); err != nil {
+		return fmt.Errorf("invalid start date %q", schedule.StartDate)
+	}
+	return nil
+}
+
+// weekdayMinutes returns the scheduled minutes indexed by time.Weekday
+func (s WorkSchedule) weekdayMinutes() [7]int {
+	return [7]int{
+		s.SundayMinutes,
+		s.MondayMinutes,
+		s.TuesdayMinutes,
+		s.WednesdayMinutes,
+		s.ThursdayMinutes,
+		s.FridayMinutes,
+		s.SaturdayMinutes,
+	}
+}
+
+func (a *App) getWorkSchedule(organizationID uint) (WorkSchedule, error) {
+	var schedule WorkSchedule
+	err := a.db.Where("organization_id = ?", organizationID).First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultWorkSchedule(organizationID), nil
+	}
+	return schedule, err
+}
+
+// GetWorkSchedule returns the work schedule of the organization
+func (a *App) GetWorkSchedule(organizationID uint) (WorkSchedule, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return WorkSchedule{}, err
+	}
+	schedule, err := a.getWorkSchedule(organizationID)
+	if err != nil {
+		return WorkSchedule{}, toAppError(err)
+	}
+	return schedule, nil
+}
+
+// SaveWorkSchedule validates and stores the work schedule of an organization
+func (a *App) SaveWorkSchedule(schedule WorkSchedule) (WorkSchedule, error) {
+	if _, err := a.getOrganization(schedule.OrganizationID); err != nil {
+		return WorkSchedule{}, err
+	}
+	if err := validateWorkSchedule(schedule); err != nil {
+		return WorkSchedule{}, invalid(err)
+	}
+
+	existing, err := a.getWorkSchedule(schedule.OrganizationID)
+	if err != nil {
+		return WorkSchedule{}, toAppError(err)
+	}
+	schedule.ID = existing.ID
+	schedule.CreatedAt = existing.CreatedAt
+	if err := a.db.Save(&schedule).Error; err != nil {
+		return WorkSchedule{}, toAppError(err)
+	}
+	return schedule, nil
+}
+
+// GetOvertime returns the expected and actual time of an organization between two dates (inclusive)
+// and its overtime balance before and after them
+func (a *App) GetOvertime(organizationID uint, startDate, endDate string) (OvertimeSummary, error) {
+	if _, err := a.getOrganization(organizationID); err != nil {
+		return OvertimeSummary{}, err
+	}
+	overtime, err := a.getOvertime(organizationID, startDate, endDate)
+	if err != nil {
+		return OvertimeSummary{}, toAppError(err)
+	}
+	return overtime, nil
+}
+
+// expectedDays returns the scheduled seconds of every day between two dates (inclusive) that aren't
+// covered by absences, along with the scheduled seconds the absences cover
+func (a *App) expectedDays(schedule WorkSchedule, from, to time.Time) (map[string]int, map[string]int, error) {
+	absent, err := a.absenceSeconds(schedule, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, nil, err
+	}
+	minutes := schedule.weekdayMinutes()
+	expected := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		expected[date] = minutes[day.Weekday()]*60 - absent[date]
+	}
+	return expected, absent, nil
+}
+
+// overtimeDays returns the expected and actual time of the days between two dates (inclusive) with either
+func (a *App) overtimeDays(schedule WorkSchedule, startDate, endDate string) ([]DayOvertime, error) {
+	from, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, err
+	}
+	if from.After(to) {
+		return nil, nil
+	}
+
+	expected, absent, err := a.expectedDays(schedule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Date    string
+		Seconds int
+	}
+	err = a.db.Table("work_hours").
+		Select("work_hours.date, SUM(work_hours.seconds) AS seconds").
+		Joins("JOIN projects ON projects.id = work_hours.project_id").
+		Where("projects.deleted_at IS NULL AND work_hours.deleted_at IS NULL"). // Ignore deleted projects and merged duplicates
+		Where("projects.organization_id = ?", schedule.OrganizationID).
+		Where("work_hours.date >= ? AND work_hours.date <= ?", startDate, endDate).
+		Group("work_hours.date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[string]int)
+	for _, row := range rows {
+		actual[row.Date] = row.Seconds
+	}
+
+	var days []DayOvertime
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if expected[date] == 0 && absent[date] == 0 && actual[date] == 0 {
+			continue
+		}
+		days = append(days, DayOvertime{Date: date, ExpectedSeconds: expected[date], AbsenceSeconds: absent[date], ActualSeconds: actual[date]})
+	}
+	return days, nil
+}
+
+func overtimeDifference(days []DayOvertime) int {
+	difference := 0
+	for _, day := range days {
+		difference += day.ActualSeconds - day.ExpectedSeconds
+	}
+	return difference
+}
+
+func (a *App) getOvertime(organizationID uint, startDate, endDate string) (OvertimeSummary, error) {
+	schedule, err := a.getWorkSchedule(organizationID)
+	if err != nil {
+		return OvertimeSummary{}, err
+	}
+	overtime := OvertimeSummary{Enabled: schedule.Enabled, Start: startDate, End: endDate}
+	if !schedule.Enabled {
+		return overtime, nil
+	}
+
+	// Only count the days since the schedule started, up to today
+	today := time.Now().Format("2006-01-02")
+	from := max(startDate, schedule.StartDate)
+	to := min(endDate, today)
+
+	overtime.CarryOverSeconds = schedule.OpeningBalanceSeconds
+	if schedule.StartDate < startDate {
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return OvertimeSummary{}, err
+		}
+		before, err := a.overtimeDays(schedule, schedule.StartDate, min(start.AddDate(0, 0, -1).Format("2006-01-02"), today))
+		if err != nil {
+			return OvertimeSummary{}, err
+		}
+		overtime.CarryOverSeconds += overtimeDifference(before)
+	}
+
+	overtime.Days, err = a.overtimeDays(schedule, from, to)
+	if err != nil {
+		return OvertimeSummary{}, err
+	}
+	for _, day := range overtime.Days {
+		overtime.ExpectedSeconds += day.ExpectedSeconds
+		overtime.AbsenceSeconds += day.AbsenceSeconds
+		overtime.ActualSeconds += day.ActualSeconds
+	}
+	overtime.DifferenceSeconds = overtime.ActualSeconds - overtime.ExpectedSeconds
+	overtime.BalanceSeconds = overtime.CarryOverSeconds + overtime.DifferenceSeconds
+	return overtime, nil
+}
+
+// scopeOvertime returns the overtime of a report, reports that aren't about an organization have none
+func (a *App) scopeOvertime(scope reportScope, startDate, endDate string) (OvertimeSummary, error) {
+	if scope.organizationID == 0 || scope.kind != "organization" {
+		return OvertimeSummary{}, nil
+	}
+	return a.getOvertime(scope.organizationID, startDate, endDate)
+}
+
+// overtimeRows returns the expected, absence, actual and difference cells of the days grouped by label, in date order
+func overtimeRows(overtime OvertimeSummary, label func(date string) string) [][]string {
+	var labels []string
+	expected := make(map[string]int)
+	absent := make(map[string]int)
+	actual := make(map[string]int)
+	for _, day := range overtime.Days {
+		group := label(day.Date)
+		if _, ok := expected[group]; !ok {
+			labels = append(labels, group)
+		}
+		expected[group] += day.ExpectedSeconds
+		absent[group] += day.AbsenceSeconds
+		actual[group] += day.ActualSeconds
+	}
+
+	var rows [][]string
+	for _, group := range labels {
+		rows = append(rows, []string{
+			group,
+			fmt.Sprintf("%.2f", secondsToHours(expected[group])),
+			fmt.Sprintf("%.2f", secondsToHours(absent[group])),
+			fmt.Sprintf("%.2f", secondsToHours(actual[group])),
+			signedHours(actual[group] - expected[group]),
+		})
+	}
+	return rows
+}
+
+// overtimeBalanceRows returns the carry-over, the period's difference and the resulting balance
+func overtimeBalanceRows(overtime OvertimeSummary, period string) [][]string {
+	return [][]string{
+		{"Carried over", "", "", "", signedHours(overtime.CarryOverSeconds)},
+		{
+			period,
+			fmt.Sprintf("%.2f", secondsToHours(overtime.ExpectedSeconds)),
+			fmt.Sprintf("%.2f", secondsToHours(overtime.AbsenceSeconds)),
+			fmt.Sprintf("%.2f", secondsToHours(overtime.ActualSeconds)),
+			signedHours(overtime.DifferenceSeconds),
+		},
+		{"Balance", "", "", "", signedHours(overtime.BalanceSeconds)},
+	}
+}
+
+// signedHours formats seconds as hours with their sign, e.g. "+1.50"
+func signedHours(seconds int) string {
+	return fmt.Sprintf("%+.2f", secondsToHours(seconds))
+}
+
+// weekLabel labels a date with the range of its week within the month, weeks end on Sunday like in the weekly breakdown
+func weekLabel(date string) string {
+	day, _ := time.Parse("2006-01-02", date)
+	start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	end := start.AddDate(0, 0, 6)
+	if start.Month() != day.Month() {
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if end.Month() != day.Month() {
+		end = time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+	return fmt.Sprintf("(%s - %s)", start.Format("01-02"), end.Format("01-02"))
+}
+
+// monthLabel labels a date with its month
+func monthLabel(date string) string {
+	day, _ := time.Parse("2006-01-02", date)
+	return monthMap[int(day.Month())]
+}
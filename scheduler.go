@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+type ReportPeriod string
+
+const (
+	PeriodMonth ReportPeriod = "month"
+	PeriodYear  ReportPeriod = "year"
+)
+
+type RunStatus string
+
+const (
+	RunSuccess RunStatus = "success"
+	RunFailed  RunStatus = "failed"
+)
+
+// Upper bound of missed runs generated on catch up, e.g. after the app was closed for a long time
+const maxCatchUpRuns = 24
+
+// ReportSchedule generates an organization's export on a cron schedule.
+// Each run exports the last complete period (month or year) before its scheduled time
+type ReportSchedule struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	OrganizationID uint           `json:"organization_id"`
+	ExportType     ExportType     `json:"export_type"`
+	Period         ReportPeriod   `json:"period"`
+	Cron           string         `json:"cron"` // evaluated in the organization's timezone
+	Enabled        bool           `json:"enabled"`
+	EmailReport    bool           `json:"email_report"` // send the report to the organization's recipients
+	LastRunAt      *time.Time     `json:"last_run_at"`  // scheduled time of the last run
+}
+
+// ReportRun records a single execution of a ReportSchedule
+type ReportRun struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ScheduleID   uint      `gorm:"index" json:"schedule_id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	FinishedAt   time.Time `json:"finished_at"`
+	CatchUp      bool      `json:"catch_up"` // the app wasn't running at the scheduled time
+	Status       RunStatus `json:"status"`
+	OutputPath   string    `json:"output_path"`
+	Error        string    `json:"error"`
+}
+
+func validateReportSchedule(schedule ReportSchedule) error {
+	if schedule.ExportType != CSV && schedule.ExportType != PDF {
+		return fmt.Errorf("invalid export type %q", schedule.ExportType)
+	}
+	if schedule.Period != PeriodMonth && schedule.Period != PeriodYear {
+		return fmt.Errorf("invalid report period %q", schedule.Period)
+	}
+	cron, err := parseCron(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	if cron.next(time.Now()).IsZero() {
+		return fmt.Errorf("cron expression %q never runs", schedule.Cron)
+	}
+	return nil
+}
+
+// reportPeriodFor returns the last complete period before the scheduled time
+func reportPeriodFor(period ReportPeriod, scheduledFor time.Time) (year int, month time.Month) {
+	if period == PeriodYear {
+		return scheduledFor.Year() - 1, 0
+	}
+	previous := time.Date(scheduledFor.Year(), scheduledFor.Month(), 1, 0, 0, 0, 0, scheduledFor.Location()).AddDate(0, -1, 0)
+	return previous.Year(), previous.Month()
+}
+
+// GetReportSchedules returns the report schedules of the specified organization
+func (a *App) GetReportSchedules(organizationID uint) (schedules []ReportSchedule, err error) {
+	err = a.db.Where(&ReportSchedule{OrganizationID: organizationID}).Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// SaveReportSchedule creates or updates a report schedule
+func (a *App) SaveReportSchedule(schedule ReportSchedule) (ReportSchedule, error) {
+	if _, err := a.getOrganization(schedule.OrganizationID); err != nil {
+		return ReportSchedule{}, err
+	}
+	if err := validateReportSchedule(schedule); err != nil {
+		return ReportSchedule{}, invalid(err)
+	}
+
+	if schedule.ID != 0 {
+		var current ReportSchedule
+		if err := a.db.Where(&ReportSchedule{ID: schedule.ID}).First(&current).Error; err != nil {
+			return ReportSchedule{}, err
+		}
+		schedule.CreatedAt = current.CreatedAt
+		if current.Cron == schedule.Cron && current.Period == schedule.Period {
+			schedule.LastRunAt = current.LastRunAt
+		}
+	}
+	if schedule.LastRunAt == nil {
+		// Don't catch up on occurrences from before the schedule existed
+		now := time.Now().UTC()
+		schedule.LastRunAt = &now
+	}
+
+	if err := a.db.Save(&schedule).Error; err != nil {
+		return ReportSchedule{}, err
+	}
+	return schedule, nil
+}
+
+// DeleteReportSchedule deletes the specified report schedule
+func (a *App) DeleteReportSchedule(scheduleID uint) error {
+	if scheduleID == 0 {
+		return validationError("schedule ID is 0")
+	}
+	return a.db.Delete(&ReportSchedule{}, scheduleID).Error
+}
+
+// GetReportRuns returns the run history of a schedule, most recent first
+func (a *App) GetReportRuns(scheduleID uint) (runs []ReportRun, err error) {
+	err = a.db.Where(&ReportRun{ScheduleID: scheduleID}).Order("scheduled_for DESC").Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// RunReportSchedule generates the schedule's report immediately, without affecting its next scheduled run
+func (a *App) RunReportSchedule(scheduleID uint) (ReportRun, error) {
+	var schedule ReportSchedule
+	if err := a.db.Where(&ReportSchedule{ID: scheduleID}).First(&schedule).Error; err != nil {
+		return ReportRun{}, err
+	}
+	return a.runReportSchedule(schedule, time.Now(), false), nil
+}
+
+// runReportSchedule generates the report for a single occurrence and records the run
+func (a *App) runReportSchedule(schedule ReportSchedule, scheduledFor time.Time, catchUp bool) ReportRun {
+	run := ReportRun{
+		ScheduleID:   schedule.ID,
+		ScheduledFor: scheduledFor.UTC(),
+		CatchUp:      catchUp,
+		Status:       RunSuccess,
+	}
+
+	organization, err := a.getOrganization(schedule.OrganizationID)
+	if err == nil {
+		year, month := reportPeriodFor(schedule.Period, scheduledFor.In(loadLocation(organization.Timezone)))
+		if schedule.Period == PeriodYear {
+			run.OutputPath, err = a.exportByYear(schedule.ExportType, organization.Name, year, false)
+		} else {
+			run.OutputPath, err = a.exportByMonth(schedule.ExportType, organization.Name, year, month, false)
+		}
+	}
+	if err != nil {
+		log.Printf("Scheduled report %d failed: %v", schedule.ID, err)
+		run.Status = RunFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now().UTC()
+
+	if err := a.db.Create(&run).Error; err != nil {
+		log.Printf("Error recording report run: %v", err)
+	}
+	if run.Status == RunSuccess && schedule.EmailReport {
+		year, month := reportPeriodFor(schedule.Period, scheduledFor.In(loadLocation(organization.Timezone)))
+		period := strconv.Itoa(year)
+		if month != 0 {
+			period = fmt.Sprintf("%s %d", month, year)
+		}
+		if _, err := a.queueReportEmail(schedule.OrganizationID, &run.ID, period, schedule.ExportType, run.OutputPath); err != nil {
+			log.Printf("Error emailing scheduled report %d: %v", schedule.ID, err)
+		}
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "report-generated", run)
+	}
+	return run
+}
+
+// runDueReports runs every occurrence of the enabled schedules that is due, including missed ones
+func (a *App) runDueReports() {
+	var schedules []ReportSchedule
+	if err := a.db.Where("enabled = ?", true).Find(&schedules).Error; err != nil {
+		log.Printf("Error loading report schedules: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		cron, err := parseCron(schedule.Cron)
+		if err != nil {
+			log.Printf("Invalid cron expression for report schedule %d: %v", schedule.ID, err)
+			continue
+		}
+
+		last := schedule.CreatedAt
+		if schedule.LastRunAt != nil {
+			last = *schedule.LastRunAt
+		}
+		last = last.In(a.reportingLocation(schedule.OrganizationID))
+
+		var due []time.Time
+		for next := cron.next(last); !next.IsZero() && !next.After(now); next = cron.next(next) {
+			due = append(due, next)
+		}
+		if len(due) == 0 {
+			continue
+		}
+		runs := latestPerPeriod(schedule.Period, due)
+		if len(runs) > maxCatchUpRuns {
+			runs = runs[len(runs)-maxCatchUpRuns:]
+		}
+
+		for _, scheduledFor := range runs {
+			catchUp := now.Sub(scheduledFor) > time.Minute
+			a.runReportSchedule(schedule, scheduledFor, catchUp)
+
+			// Saved after every run so a crash doesn't repeat the runs already done
+			if err := a.db.Model(&schedule).UpdateColumn("last_run_at", scheduledFor.UTC()).Error; err != nil {
+				log.Printf("Error updating report schedule %d: %v", schedule.ID, err)
+			}
+		}
+	}
+}
+
+// latestPerPeriod keeps the last occurrence of each report period. Occurrences of the same period export the same
+// report, e.g. catching up on a week of a daily schedule of monthly reports exports last month once, not seven times
+func latestPerPeriod(period ReportPeriod, occurrences []time.Time) []time.Time {
+	var result []time.Time
+	for i, occurrence := range occurrences {
+		year, month := reportPeriodFor(period, occurrence)
+		if i+1 < len(occurrences) {
+			nextYear, nextMonth := reportPeriodFor(period, occurrences[i+1])
+			if nextYear == year && nextMonth == month {
+				continue
+			}
+		}
+		result = append(result, occurrence)
+	}
+	return result
+}
+
+func (a *App) schedulerRoutine() {
+	// Catch up on startup then check every minute
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		a.withDb(a.runDueReports)
+		for range ticker.C {
+			a.withDb(a.runDueReports)
+		}
+	}()
+}
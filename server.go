@@ -0,0 +1,441 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Team server
+//
+// `go-work-tracker server` runs an HTTP server that the apps of a team push their organizations, projects and
+// sessions to, the same changes devices exchange through a sync folder (see sync.go). Every user gets their
+// own database on the server, so team reports reuse the monthly and yearly aggregations of the app.
+// Leads can see everyone's hours, members only their own. Requests carry an API token issued with
+// `go-work-tracker server token`, only its hash is stored.
+//
+//	go-work-tracker server add-user -name alice -lead
+//	go-work-tracker server token -name alice -label laptop
+//	go-work-tracker server -addr :8443 -tls-cert server.crt -tls-key server.key
+//
+// Without -tls-cert the server speaks plain HTTP and tokens cross the network readable, run it behind a
+// reverse proxy that terminates TLS (Caddy, nginx) then.
+
+type ServerRole string
+
+const (
+	RoleMember ServerRole = "member"
+	RoleLead   ServerRole = "lead"
+)
+
+const (
+	serverTokenPrefix = "wtt_"
+	maxPushBytes      = 32 << 20
+
+	serverReadHeaderTimeout = 10 * time.Second
+	serverReadTimeout       = 2 * time.Minute // a push of maxPushBytes on a slow connection
+	serverWriteTimeout      = 2 * time.Minute // yearly reports of every user
+	serverIdleTimeout       = 2 * time.Minute
+)
+
+// ServerUser is an account on the team server
+type ServerUser struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Name      string     `gorm:"uniqueIndex" json:"name"`
+	Role      ServerRole `json:"role"`
+}
+
+// ServerToken is an API token of a user, a user has one per device
+type ServerToken struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UserID     uint       `gorm:"index"`
+	Label      string     // e.g. the device the token is for
+	Hash       string     `gorm:"uniqueIndex"` // sha256 of the token
+	LastUsedAt *time.Time // last authenticated request
+}
+
+// teamPush is the body of a push, the changes are applied in order
+type teamPush struct {
+	DeviceID string       `json:"device_id"`
+	Changes  []syncChange `json:"changes"`
+}
+
+// teamUserReport is a user's part of a team report
+type teamUserReport[T any] struct {
+	User   string `json:"user"`
+	Totals T      `json:"totals"`
+}
+
+type teamServer struct {
+	db      *gorm.DB // users and tokens
+	dataDir string
+	mu      sync.Mutex
+	users   map[uint]*App        // the database of each user, opened on first use
+	userMu  map[uint]*sync.Mutex // keeps pushes of the same user from overlapping
+}
+
+// runServer handles the server command and its subcommands
+func runServer(args []string) error {
+	defaultDir := "team-server"
+	if saveDir, err := getSaveDir(os.Getenv("APP_ENV")); err == nil {
+		defaultDir = filepath.Join(saveDir, "team-server")
+	}
+
+	command := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	flags := flag.NewFlagSet("server "+command, flag.ExitOnError)
+	dataDir := flags.String("data", defaultDir, "directory of the server's databases")
+
+	switch command {
+	case "run":
+		addr := flags.String("addr", ":8080", "address to listen on")
+		tlsCert := flags.String("tls-cert", "", "certificate file (PEM) to serve HTTPS with")
+		tlsKey := flags.String("tls-key", "", "private key file (PEM) of the certificate")
+		flags.Parse(args)
+		if (*tlsCert == "") != (*tlsKey == "") {
+			return errors.New("-tls-cert and -tls-key go together")
+		}
+		server, err := openTeamServer(*dataDir)
+		if err != nil {
+			return err
+		}
+		httpServer := &http.Server{
+			Addr:              *addr,
+			Handler:           server.handler(),
+			ReadHeaderTimeout: serverReadHeaderTimeout,
+			ReadTimeout:       serverReadTimeout,
+			WriteTimeout:      serverWriteTimeout,
+			IdleTimeout:       serverIdleTimeout,
+		}
+		if *tlsCert != "" {
+			Logger.Printf("Team server listening on %s (HTTPS), data in %s", *addr, *dataDir)
+			return httpServer.ListenAndServeTLS(*tlsCert, *tlsKey)
+		}
+		Logger.Printf("Team server listening on %s (plain HTTP, use -tls-cert or a TLS proxy), data in %s", *addr, *dataDir)
+		return httpServer.ListenAndServe()
+	case "add-user":
+		name := flags.String("name", "", "user name")
+		lead := flags.Bool("lead", false, "let the user see the hours of everyone")
+		flags.Parse(args)
+		server, err := openTeamServer(*dataDir)
+		if err != nil {
+			return err
+		}
+		role := RoleMember
+		if *lead {
+			role = RoleLead
+		}
+		user, err := server.addUser(*name, role)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Added %s (%s)\n", user.Name, user.Role)
+		return nil
+	case "token":
+		name := flags.String("name", "", "user name")
+		label := flags.String("label", "", "what the token is for, e.g. the device")
+		flags.Parse(args)
+		server, err := openTeamServer(*dataDir)
+		if err != nil {
+			return err
+		}
+		token, err := server.issueToken(*name, *label)
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	default:
+		return fmt.Errorf("unknown server command %q, use run, add-user or token", command)
+	}
+}
+
+func openTeamServer(dataDir string) (*teamServer, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "server.sqlite")), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&ServerUser{}, &ServerToken{}); err != nil {
+		return nil, err
+	}
+	return &teamServer{
+		db:      db,
+		dataDir: dataDir,
+		users:   make(map[uint]*App),
+		userMu:  make(map[uint]*sync.Mutex),
+	}, nil
+}
+
+func (s *teamServer) addUser(name string, role ServerRole) (ServerUser, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ServerUser{}, errors.New("a user name is required")
+	}
+	user := ServerUser{Name: name, Role: role}
+	if err := s.db.Create(&user).Error; err != nil {
+		return ServerUser{}, fmt.Errorf("couldn't add %s: %w", name, err)
+	}
+	return user, nil
+}
+
+// issueToken creates a token for a user, the token itself is only returned here
+func (s *teamServer) issueToken(name, label string) (string, error) {
+	var user ServerUser
+	if err := s.db.Where("name = ?", name).First(&user).Error; err != nil {
+		return "", fmt.Errorf("unknown user %q", name)
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := serverTokenPrefix + hex.EncodeToString(b)
+	if err := s.db.Create(&ServerToken{UserID: user.ID, Label: label, Hash: hashToken(token)}).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// userApp returns the app holding a user's data, with a lock for changing it
+func (s *teamServer) userApp(userID uint) (*App, *sync.Mutex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if app, ok := s.users[userID]; ok {
+		return app, s.userMu[userID], nil
+	}
+	dir := filepath.Join(s.dataDir, "users", strconv.FormatUint(uint64(userID), 10))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, err
+	}
+	// Not cached on failure, the next request tries again
+	db, err := openDb(dir)
+	if err != nil {
+		Logger.Printf("The database of user %d couldn't be opened: %v", userID, err)
+		return nil, nil, fmt.Errorf("the user's database couldn't be opened: %w", err)
+	}
+	app := &App{db: db}
+	s.users[userID] = app
+	s.userMu[userID] = &sync.Mutex{}
+	return app, s.userMu[userID], nil
+}
+
+func (s *teamServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/me", s.authenticated(http.MethodGet, s.handleMe))
+	mux.HandleFunc("/api/push", s.authenticated(http.MethodPost, s.handlePush))
+	mux.HandleFunc("/api/users", s.authenticated(http.MethodGet, s.handleUsers))
+	mux.HandleFunc("/api/reports/monthly", s.authenticated(http.MethodGet, s.handleMonthlyReport))
+	mux.HandleFunc("/api/reports/yearly", s.authenticated(http.MethodGet, s.handleYearlyReport))
+	return mux
+}
+
+// authenticated resolves the bearer token of a request to its user
+func (s *teamServer) authenticated(method string, next func(http.ResponseWriter, *http.Request, ServerUser)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeServerError(w, http.StatusMethodNotAllowed, "use "+method)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeServerError(w, http.StatusUnauthorized, "missing API token")
+			return
+		}
+		var stored ServerToken
+		if err := s.db.Where("hash = ?", hashToken(token)).First(&stored).Error; err != nil {
+			writeServerError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		}
+		var user ServerUser
+		if err := s.db.First(&user, stored.UserID).Error; err != nil {
+			writeServerError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		}
+		s.db.Model(&stored).UpdateColumn("last_used_at", time.Now().UTC())
+		next(w, r, user)
+	}
+}
+
+func (s *teamServer) handleMe(w http.ResponseWriter, r *http.Request, user ServerUser) {
+	writeServerJSON(w, user)
+}
+
+// handlePush applies the changes pushed by one of the user's devices
+func (s *teamServer) handlePush(w http.ResponseWriter, r *http.Request, user ServerUser) {
+	var push teamPush
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPushBytes)).Decode(&push); err != nil {
+		writeServerError(w, http.StatusBadRequest, "invalid push: "+err.Error())
+		return
+	}
+	app, mu, err := s.userApp(user.ID)
+	if err != nil {
+		writeServerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	report := SyncReport{SyncedAt: time.Now().UTC()}
+	err = app.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range push.Changes {
+			// The server has no device ID of its own, a tie goes to the pushing device
+			if err := app.applySyncChange(tx, "", change, &report); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Logger.Printf("Push of %s from %s failed: %v", user.Name, push.DeviceID, err)
+		writeServerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeServerJSON(w, report)
+}
+
+// handleUsers lists the users the caller may report on
+func (s *teamServer) handleUsers(w http.ResponseWriter, r *http.Request, user ServerUser) {
+	users, err := s.visibleUsers(user, "")
+	if err != nil {
+		writeServerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeServerJSON(w, users)
+}
+
+func (s *teamServer) handleMonthlyReport(w http.ResponseWriter, r *http.Request, user ServerUser) {
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	month, monthErr := strconv.Atoi(r.URL.Query().Get("month"))
+	if err != nil || monthErr != nil || month < 1 || month > 12 {
+		writeServerError(w, http.StatusBadRequest, "year and month (1-12) are required")
+		return
+	}
+	reports, status, err := teamReport(s, r, user, func(app *App, scope reportScope) (MonthlyTotals, error) {
+		return app.getMonthlyTotals(scope, year, time.Month(month))
+	})
+	if err != nil {
+		writeServerError(w, status, err.Error())
+		return
+	}
+	writeServerJSON(w, reports)
+}
+
+func (s *teamServer) handleYearlyReport(w http.ResponseWriter, r *http.Request, user ServerUser) {
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil {
+		writeServerError(w, http.StatusBadRequest, "year is required")
+		return
+	}
+	reports, status, err := teamReport(s, r, user, func(app *App, scope reportScope) (YearlyTotals, error) {
+		return app.getYearlyTotals(scope, year)
+	})
+	if err != nil {
+		writeServerError(w, status, err.Error())
+		return
+	}
+	writeServerJSON(w, reports)
+}
+
+// teamReport runs a report for each user the caller may see, optionally narrowed with ?user= and ?organization=.
+// Users without the organization are left out
+func teamReport[T any](s *teamServer, r *http.Request, caller ServerUser, totals func(*App, reportScope) (T, error)) ([]teamUserReport[T], int, error) {
+	users, err := s.visibleUsers(caller, r.URL.Query().Get("user"))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if len(users) == 0 {
+		return nil, http.StatusNotFound, errors.New("no such user")
+	}
+
+	organization := r.URL.Query().Get("organization")
+	reports := []teamUserReport[T]{}
+	for _, user := range users {
+		app, _, err := s.userApp(user.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		scope := allOrganizationsScope()
+		if organization != "" {
+			if scope, err = app.organizationScope(organization); errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			} else if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+		}
+		result, err := totals(app, scope)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		reports = append(reports, teamUserReport[T]{User: user.Name, Totals: result})
+	}
+	return reports, http.StatusOK, nil
+}
+
+// visibleUsers returns the users the caller may report on, all of them for a lead, or the named one
+func (s *teamServer) visibleUsers(caller ServerUser, name string) ([]ServerUser, error) {
+	if caller.Role != RoleLead {
+		if name != "" && name != caller.Name {
+			return nil, nil
+		}
+		return []ServerUser{caller}, nil
+	}
+	query := s.db.Order("name")
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	var users []ServerUser
+	err := query.Find(&users).Error
+	return users, err
+}
+
+// allOrganizationsScope reports on every project, labeled with its organization
+func allOrganizationsScope() reportScope {
+	return reportScope{
+		kind:  "team",
+		name:  "All organizations",
+		label: "organizations.name || ' / ' || projects.name",
+		filter: func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN organizations ON organizations.id = projects.organization_id").
+				Where("organizations.deleted_at IS NULL")
+		},
+	}
+}
+
+func writeServerJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		Logger.Println("Failed to write response:", err)
+	}
+}
+
+func writeServerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
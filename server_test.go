@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestServer returns a team server with a lead "alice" and a member "bob", and their tokens
+func newTestServer(t *testing.T) (*teamServer, *httptest.Server, map[string]string) {
+	t.Helper()
+	server, err := openTeamServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := make(map[string]string)
+	for _, user := range []ServerUser{{Name: "alice", Role: RoleLead}, {Name: "bob", Role: RoleMember}} {
+		if _, err := server.addUser(user.Name, user.Role); err != nil {
+			t.Fatal(err)
+		}
+		if tokens[user.Name], err = server.issueToken(user.Name, "laptop"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	httpServer := httptest.NewServer(server.handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		for _, app := range server.users {
+			if sqlDB, err := app.db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+		if sqlDB, err := server.db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return server, httpServer, tokens
+}
+
+// serverRequest sends a request with a token and decodes the JSON answer into result, it returns the status
+func serverRequest(t *testing.T, method, url, token string, body, result any) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if result != nil && response.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return response.StatusCode
+}
+
+func TestServerAuth(t *testing.T) {
+	server, httpServer, tokens := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, "", http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, serverTokenPrefix + "0000", http.StatusUnauthorized},
+		{"wrong method", http.MethodPost, tokens["bob"], http.StatusMethodNotAllowed},
+		{"valid token", http.MethodGet, tokens["bob"], http.StatusOK},
+	}
+	for _, test := range tests {
+		var me ServerUser
+		if got := serverRequest(t, test.method, httpServer.URL+"/api/me", test.token, nil, &me); got != test.want {
+			t.Errorf("%s: status %d, want %d", test.name, got, test.want)
+		}
+		if test.want == http.StatusOK && (me.Name != "bob" || me.Role != RoleMember) {
+			t.Errorf("%s: me = %+v", test.name, me)
+		}
+	}
+
+	// Only the hash of a token is stored, and its use is recorded
+	var stored ServerToken
+	if err := server.db.Where("hash = ?", hashToken(tokens["bob"])).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.LastUsedAt == nil {
+		t.Error("last use of the token wasn't recorded")
+	}
+	var count int64
+	server.db.Model(&ServerToken{}).Where("hash = ?", tokens["bob"]).Count(&count)
+	if count != 0 {
+		t.Error("the token is stored in plain")
+	}
+}
+
+func TestServerVisibleUsers(t *testing.T) {
+	_, httpServer, tokens := newTestServer(t)
+	names := func(users []ServerUser) []string {
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	var users []ServerUser
+	serverRequest(t, http.MethodGet, httpServer.URL+"/api/users", tokens["alice"], nil, &users)
+	if got := names(users); len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Errorf("lead sees %v, want alice and bob", got)
+	}
+	users = nil
+	serverRequest(t, http.MethodGet, httpServer.URL+"/api/users", tokens["bob"], nil, &users)
+	if got := names(users); len(got) != 1 || got[0] != "bob" {
+		t.Errorf("member sees %v, want only bob", got)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		query string
+		want  int
+		users []string
+	}{
+		{"lead, everyone", tokens["alice"], "", http.StatusOK, []string{"alice", "bob"}},
+		{"lead, one user", tokens["alice"], "&user=bob", http.StatusOK, []string{"bob"}},
+		{"member, themself", tokens["bob"], "", http.StatusOK, []string{"bob"}},
+		{"member, someone else", tokens["bob"], "&user=alice", http.StatusNotFound, nil},
+		{"unknown user", tokens["alice"], "&user=carol", http.StatusNotFound, nil},
+	}
+	for _, test := range tests {
+		var reports []teamUserReport[YearlyTotals]
+		status := serverRequest(t, http.MethodGet, httpServer.URL+"/api/reports/yearly?year=2026"+test.query, test.token, nil, &reports)
+		if status != test.want {
+			t.Errorf("%s: status %d, want %d", test.name, status, test.want)
+			continue
+		}
+		var got []string
+		for _, report := range reports {
+			got = append(got, report.User)
+		}
+		if len(got) != len(test.users) || (len(got) > 0 && got[0] != test.users[0]) {
+			t.Errorf("%s: reports of %v, want %v", test.name, got, test.users)
+		}
+	}
+}
+
+func TestServerPush(t *testing.T) {
+	_, httpServer, tokens := newTestServer(t)
+	at := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	push := teamPush{DeviceID: "laptop", Changes: []syncChange{
+		syncOrganizationChange("o-1", "Acme", at),
+		syncProjectChange("p-1", "o-1", "Web", at),
+		syncSessionChange("s-1", "p-1", "2026-03-02", 3600, at),
+		syncSessionChange("s-2", "p-unknown", "2026-03-02", 60, at),
+	}}
+
+	var report SyncReport
+	if status := serverRequest(t, http.MethodPost, httpServer.URL+"/api/push", tokens["bob"], push, &report); status != http.StatusOK {
+		t.Fatalf("push status %d", status)
+	}
+	if report.Applied != 3 || len(report.Skipped) != 1 {
+		t.Errorf("applied %d, skipped %v, want 3 applied and the session of the unknown project skipped", report.Applied, report.Skipped)
+	}
+	// Pushing the same changes again applies nothing new
+	if serverRequest(t, http.MethodPost, httpServer.URL+"/api/push", tokens["bob"], push, &report); report.Applied != 0 {
+		t.Errorf("the repeated push applied %d changes", report.Applied)
+	}
+	if status := serverRequest(t, http.MethodPost, httpServer.URL+"/api/push", tokens["bob"], "not a push", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid push status %d", status)
+	}
+
+	// The hours land in bob's database only
+	var reports []teamUserReport[MonthlyTotals]
+	serverRequest(t, http.MethodGet, httpServer.URL+"/api/reports/monthly?year=2026&month=3", tokens["alice"], nil, &reports)
+	totals := make(map[string]int)
+	for _, report := range reports {
+		totals[report.User] = report.Totals.MonthlyTotal
+	}
+	if totals["bob"] != 3600 || totals["alice"] != 0 {
+		t.Errorf("monthly totals %v, want 3600 seconds for bob", totals)
+	}
+
+	// The organization narrows the report, users without it are left out
+	reports = nil
+	serverRequest(t, http.MethodGet, httpServer.URL+"/api/reports/monthly?year=2026&month=3&organization=Acme", tokens["alice"], nil, &reports)
+	if len(reports) != 1 || reports[0].User != "bob" {
+		t.Errorf("reports for Acme: %+v", reports)
+	}
+}
+
+func TestServerBrokenUserDatabase(t *testing.T) {
+	server, httpServer, tokens := newTestServer(t)
+	var bob ServerUser
+	if err := server.db.Where("name = ?", "bob").First(&bob).Error; err != nil {
+		t.Fatal(err)
+	}
+	// A directory where bob's database file should be can't be opened
+	dir := filepath.Join(server.dataDir, "users", strconv.FormatUint(uint64(bob.ID), 10), dbFileName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	push := teamPush{DeviceID: "laptop"}
+	if status := serverRequest(t, http.MethodPost, httpServer.URL+"/api/push", tokens["bob"], push, nil); status != http.StatusInternalServerError {
+		t.Errorf("push to a broken database: status %d, want 500", status)
+	}
+	// The server is still up for the others
+	var reports []teamUserReport[YearlyTotals]
+	if status := serverRequest(t, http.MethodGet, httpServer.URL+"/api/reports/yearly?year=2026&user=alice", tokens["alice"], nil, &reports); status != http.StatusOK {
+		t.Errorf("alice's report: status %d", status)
+	}
+
+	// Once repaired, the database is opened on the next request
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if status := serverRequest(t, http.MethodPost, httpServer.URL+"/api/push", tokens["bob"], push, nil); status != http.StatusOK {
+		t.Errorf("push after the repair: status %d", status)
+	}
+}
+
+func TestTeamTokenSecret(t *testing.T) {
+	_, httpServer, tokens := newTestServer(t)
+
+	plain := newTestApp(t)
+	var appErr *AppError
+	if _, err := plain.SaveTeamConfig(httpServer.URL, tokens["bob"]); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("saving the token into a plaintext database: %v, want a conflict", err)
+	}
+
+	a := newEncryptedTestApp(t)
+	if _, err := a.NewOrganization("Acme", "Web"); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := a.SaveTeamConfig(httpServer.URL, tokens["bob"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := a.GetTeamConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []TeamConfig{saved, loaded} {
+		if got.Token != "" || !got.TokenSet {
+			t.Errorf("the frontend gets %q, set %v", got.Token, got.TokenSet)
+		}
+	}
+
+	// An empty token keeps the saved one of the same server, and the push still authenticates with it
+	if _, err := a.SaveTeamConfig(httpServer.URL+"/", ""); err != nil {
+		t.Fatal(err)
+	}
+	if pushed, err := a.PushNow(); err != nil || pushed.LastError != "" || pushed.Pending != 0 {
+		t.Errorf("push with the kept token: %+v, %v", pushed, err)
+	}
+	if _, err := a.SaveTeamConfig("https://other.example.com", ""); !errors.As(err, &appErr) || appErr.Code != ErrValidation {
+		t.Errorf("another server without a token: %v, want a validation error", err)
+	}
+
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); !errors.As(err, &appErr) || appErr.Code != ErrConflict {
+		t.Errorf("decrypting with a team token: %v, want a conflict", err)
+	}
+	if _, err := a.SaveTeamConfig("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetEncryption(EncryptionNone, "", "correct horse"); err != nil {
+		t.Errorf("decrypting after turning pushing off: %v", err)
+	}
+}
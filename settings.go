@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Settings holds the user preferences shared by the frontend and the backend.
+// There is only ever one row (ID 1)
+type Settings struct {
+	ID                uint      `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	AlertTime         int       `json:"alert_time"` // minutes between "Are you still working?" prompts, 0 disables it
+	AppTheme          string    `json:"app_theme"`
+	EnableColorOnDark bool      `json:"enable_color_on_dark"`
+
+	// Timers
+	AllowParallelTimers bool `json:"allow_parallel_timers"` // running parallel timers keep running when this is turned off
+
+	// Exports
+	ExportDir             string         `json:"export_dir"` // empty uses the app's save directory
+	MonthlyExportTemplate string         `json:"monthly_export_template"`
+	YearlyExportTemplate  string         `json:"yearly_export_template"`
+	ExportConflict        ExportConflict `json:"export_conflict"`
+	ExportUseSaveDialog   bool           `json:"export_use_save_dialog"`
+
+	// Email delivery
+	SMTPHost     string       `json:"smtp_host"` // empty disables email delivery
+	SMTPPort     int          `json:"smtp_port"`
+	SMTPSecurity SMTPSecurity `json:"smtp_security"`
+	SMTPUsername string       `json:"smtp_username"`
+	SMTPPassword string       `json:"smtp_password"` // only stored in an encrypted database and never sent back, see redacted
+	SMTPFrom     string       `json:"smtp_from"`
+
+	SMTPPasswordSet bool `gorm:"-" json:"smtp_password_set"`
+}
+
+const (
+	settingsID       = 1
+	settingsFileName = "settings.json"
+	maxAlertTime     = 120
+)
+
+var appThemes = map[string]bool{
+	"light": true,
+	"dark":  true,
+}
+
+func defaultSettings() Settings {
+	return Settings{
+		ID:                settingsID,
+		AlertTime:         30,
+		AppTheme:          "dark",
+		EnableColorOnDark: false,
+
+		MonthlyExportTemplate: defaultMonthlyExportTemplate,
+		YearlyExportTemplate:  defaultYearlyExportTemplate,
+		ExportConflict:        ExportOverwrite,
+
+		SMTPPort:     587,
+		SMTPSecurity: SMTPStartTLS,
+	}
+}
+
+func validateSettings(settings Settings) error {
+	if settings.AlertTime < 0 || settings.AlertTime > maxAlertTime {
+		return fmt.Errorf("alert time must be between 0 and %d minutes", maxAlertTime)
+	}
+	if !appThemes[settings.AppTheme] {
+		return fmt.Errorf("invalid app theme %q", settings.AppTheme)
+	}
+	if settings.ExportDir != "" && !filepath.IsAbs(settings.ExportDir) {
+		return errors.New("export directory must be an absolute path")
+	}
+	if err := validateExportTemplate(settings.MonthlyExportTemplate, false); err != nil {
+		return err
+	}
+	if err := validateExportTemplate(settings.YearlyExportTemplate, true); err != nil {
+		return err
+	}
+	if settings.ExportConflict != ExportOverwrite && settings.ExportConflict != ExportVersion {
+		return fmt.Errorf("invalid export conflict mode %q", settings.ExportConflict)
+	}
+	return validateSMTPSettings(settings)
+}
+
+// redacted returns the settings without the SMTP password, only whether it is set
+func (s Settings) redacted() Settings {
+	s.SMTPPasswordSet = s.SMTPPassword != ""
+	s.SMTPPassword = ""
+	return s
+}
+
+// loadSettings reads the settings row, creating it with the defaults on first run
+func (a *App) loadSettings() (Settings, error) {
+	settings := defaultSettings()
+	if err := a.db.FirstOrCreate(&settings, Settings{ID: settingsID}).Error; err != nil {
+		Logger.Println(err)
+		return defaultSettings(), err
+	}
+	fillSettingsDefaults(&settings)
+	return settings, nil
+}
+
+// fillSettingsDefaults sets defaults for settings added after the row was first created
+func fillSettingsDefaults(settings *Settings) {
+	defaults := defaultSettings()
+	if settings.MonthlyExportTemplate == "" {
+		settings.MonthlyExportTemplate = defaults.MonthlyExportTemplate
+	}
+	if settings.YearlyExportTemplate == "" {
+		settings.YearlyExportTemplate = defaults.YearlyExportTemplate
+	}
+	if settings.ExportConflict == "" {
+		settings.ExportConflict = defaults.ExportConflict
+	}
+	if settings.SMTPPort == 0 {
+		settings.SMTPPort = defaults.SMTPPort
+	}
+	if settings.SMTPSecurity == "" {
+		settings.SMTPSecurity = defaults.SMTPSecurity
+	}
+}
+
+// GetSettings returns the persisted user settings without the SMTP password
+func (a *App) GetSettings() (Settings, error) {
+	settings, err := a.loadSettings()
+	return settings.redacted(), err
+}
+
+// UpdateSettings validates and persists the user settings and notifies the frontend.
+// An empty SMTP password keeps the saved one as long as the username stays the same
+func (a *App) UpdateSettings(settings Settings) (Settings, error) {
+	fillSettingsDefaults(&settings)
+	if err := validateSettings(settings); err != nil {
+		return Settings{}, invalid(err)
+	}
+
+	current, err := a.loadSettings()
+	if err != nil {
+		return Settings{}, err
+	}
+	if settings.SMTPPassword == "" && settings.SMTPUsername == current.SMTPUsername {
+		settings.SMTPPassword = current.SMTPPassword
+	} else if settings.SMTPPassword != "" && settings.SMTPPassword != current.SMTPPassword {
+		if err := a.requireEncryption("the SMTP password is stored in it"); err != nil {
+			return Settings{}, err
+		}
+	}
+	settings.ID = settingsID
+	settings.CreatedAt = current.CreatedAt
+
+	if err := a.db.Save(&settings).Error; err != nil {
+		return Settings{}, err
+	}
+	a.settings = settings
+
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "settings-updated", settings.redacted())
+	}
+	return settings.redacted(), nil
+}
+
+// SelectExportDir lets the user pick the directory exports are written to
+func (a *App) SelectExportDir() (string, error) {
+	root, err := a.exportRoot()
+	if err != nil {
+		return "", err
+	}
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select export directory",
+		DefaultDirectory:     root,
+		CanCreateDirectories: true,
+	})
+}
+
+// ExportSettings writes the settings to a JSON file chosen by the user
+func (a *App) ExportSettings() (string, error) {
+	settings, err := a.loadSettings()
+	if err != nil {
+		return "", err
+	}
+
+	saveDir := a.dbDir
+
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export settings",
+		DefaultDirectory: saveDir,
+		DefaultFilename:  settingsFileName,
+		Filters:          []runtime.FileFilter{{DisplayName: "JSON (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if filePath == "" {
+		// Dialog was cancelled
+		return "", nil
+	}
+
+	// Don't write the SMTP password to a file the user may share
+	settings.SMTPPassword = ""
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// ImportSettings replaces the settings with the contents of a JSON file chosen by the user
+func (a *App) ImportSettings() (Settings, error) {
+	saveDir := a.dbDir
+
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:            "Import settings",
+		DefaultDirectory: saveDir,
+		Filters:          []runtime.FileFilter{{DisplayName: "JSON (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil {
+		return Settings{}, err
+	}
+	if filePath == "" {
+		return a.loadSettings()
+	}
+	return a.importSettingsFile(filePath)
+}
+
+func (a *App) importSettingsFile(filePath string) (Settings, error) {
+	data, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return Settings{}, err
+	}
+
+	// Start from the defaults so settings missing from older files stay valid
+	settings := defaultSettings()
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, validationError("settings file is not valid JSON")
+	}
+	// Exported files don't contain the password, UpdateSettings keeps the current one
+	return a.UpdateSettings(settings)
+}
@@ -0,0 +1,838 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
+)
+
+// Sync between devices
+//
+// Every device appends the changes made on it to its own log, <folder>/<device ID>.jsonl, and reads the
+// logs of the other devices from where it stopped last time. A log is only ever written by its own device,
+// so a file sync tool (Syncthing, Dropbox) never has to merge files, and a device that is offline simply
+// catches up later.
+//
+// Organizations, projects and sessions are matched across devices by their UID, the auto-increment IDs stay
+// local to each database. A change replaces the local row when it is newer, ties go to the higher device ID.
+// Organizations with the same name, and projects with the same name in the same organization, that were
+// created on different devices are merged: both devices keep the lower UID. Daily hours follow the synced
+// sessions, hours tracked without a session (before sessions were recorded) aren't synced. The deletion of a
+// row not seen yet is kept as a deleted row, so its older changes read later from another log don't bring it
+// back. Tasks, clients and settings stay local, a synced session keeps no task. Changes to locked periods are skipped.
+
+const (
+	syncConfigID           = 1
+	defaultSyncMinutes     = 5
+	syncLogExtension       = ".jsonl"
+	syncEntityOrganization = "organization"
+	syncEntityProject      = "project"
+	syncEntitySession      = "work_session"
+)
+
+// syncEntityOrder makes parents come before their children in a log
+var syncEntityOrder = map[string]int{syncEntityOrganization: 0, syncEntityProject: 1, syncEntitySession: 2}
+
+// SyncConfig is this device's sync setup, it is kept out of Settings so exported settings don't carry it to another device
+type SyncConfig struct {
+	ID              uint       `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeviceID        string     `json:"device_id"`
+	Folder          string     `json:"folder"` // empty disables sync
+	IntervalMinutes int        `json:"interval_minutes"`
+	ExportedAuditID uint       `json:"-"` // last audit entry written to this device's log
+	LastSyncAt      *time.Time `json:"last_sync_at"`
+	LastError       string     `json:"last_error"`
+}
+
+// SyncCursor is how far another device's log has been applied
+type SyncCursor struct {
+	ID       uint   `gorm:"primarykey"`
+	DeviceID string `gorm:"uniqueIndex"`
+	Offset   int64  // bytes of the log applied so far
+}
+
+// SyncAlias maps the UID of a row merged into another one to the UID that was kept
+type SyncAlias struct {
+	ID        uint   `gorm:"primarykey"`
+	UID       string `gorm:"uniqueIndex"`
+	TargetUID string
+}
+
+// SyncReport is the result of a sync run
+type SyncReport struct {
+	SyncedAt time.Time `json:"synced_at"`
+	Exported int       `json:"exported"` // changes written to this device's log
+	Applied  int       `json:"applied"`  // changes of other devices applied
+	Ignored  int       `json:"ignored"`  // changes older than the local row
+	Skipped  []string  `json:"skipped"`  // changes that couldn't be applied, and why
+}
+
+// syncChange is one line of a device log, the state of a row after the change
+type syncChange struct {
+	Device       string            `json:"device"`
+	Entity       string            `json:"entity"`
+	UID          string            `json:"uid"`
+	Deleted      bool              `json:"deleted"`
+	ChangedAt    time.Time         `json:"changed_at"`
+	Organization *syncOrganization `json:"organization,omitempty"`
+	Project      *syncProject      `json:"project,omitempty"`
+	Session      *syncSession      `json:"session,omitempty"`
+}
+
+type syncOrganization struct {
+	Name       string     `json:"name"`
+	Favorite   bool       `json:"favorite"`
+	Timezone   string     `json:"timezone"`
+	ArchivedAt *time.Time `json:"archived_at"`
+}
+
+type syncProject struct {
+	OrganizationUID string     `json:"organization_uid"`
+	Name            string     `json:"name"`
+	Favorite        bool       `json:"favorite"`
+	ArchivedAt      *time.Time `json:"archived_at"`
+}
+
+type syncSession struct {
+	ProjectUID string    `json:"project_uid"`
+	Date       string    `json:"date"`
+	Seconds    int       `json:"seconds"`
+	Parallel   bool      `json:"parallel"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+	Timezone   string    `json:"timezone"`
+}
+
+// syncMu keeps sync runs from overlapping
+var syncMu sync.Mutex
+
+// newUID returns a random (version 4) UUID
+func newUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.UID == "" {
+		o.UID = newUID()
+	}
+	return nil
+}
+
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.UID == "" {
+		p.UID = newUID()
+	}
+	return nil
+}
+
+func (w *WorkSession) BeforeCreate(tx *gorm.DB) error {
+	if w.UID == "" {
+		w.UID = newUID()
+	}
+	return nil
+}
+
+// migrateSyncUIDs gives rows created before sync existed a UID, the unique indexes are created once every row has one
+func migrateSyncUIDs(db *gorm.DB) {
+	for _, table := range []string{"organizations", "projects", "work_sessions"} {
+		var ids []uint
+		err := db.Table(table).Where("uid IS NULL OR uid = ''").Pluck("id", &ids).Error
+		handleDBError(err)
+		for _, id := range ids {
+			handleDBError(db.Table(table).Where("id = ?", id).Update("uid", newUID()).Error)
+		}
+		handleDBError(db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_uid ON %s(uid)", table, table)).Error)
+	}
+}
+
+func (a *App) getSyncConfig() (SyncConfig, error) {
+	config := SyncConfig{ID: syncConfigID, IntervalMinutes: defaultSyncMinutes}
+	if err := a.db.FirstOrCreate(&config, SyncConfig{ID: syncConfigID}).Error; err != nil {
+		return SyncConfig{}, err
+	}
+	if config.DeviceID == "" {
+		config.DeviceID = newUID()
+		if err := a.db.Model(&config).Update("device_id", config.DeviceID).Error; err != nil {
+			return SyncConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// GetSyncConfig returns this device's sync folder and ID
+func (a *App) GetSyncConfig() (SyncConfig, error) {
+	config, err := a.getSyncConfig()
+	if err != nil {
+		return SyncConfig{}, toAppError(err)
+	}
+	return config, nil
+}
+
+// SaveSyncConfig sets the shared folder the device logs are kept in, an empty folder turns sync off
+func (a *App) SaveSyncConfig(folder string, intervalMinutes int) (SyncConfig, error) {
+	if folder != "" && !filepath.IsAbs(folder) {
+		return SyncConfig{}, validationError("the sync folder must be an absolute path")
+	}
+	if intervalMinutes < 1 || intervalMinutes > 24*60 {
+		return SyncConfig{}, validationError("the sync interval must be between 1 and 1440 minutes")
+	}
+	config, err := a.getSyncConfig()
+	if err != nil {
+		return SyncConfig{}, toAppError(err)
+	}
+	config.Folder = folder
+	config.IntervalMinutes = intervalMinutes
+	if err := a.db.Save(&config).Error; err != nil {
+		return SyncConfig{}, toAppError(err)
+	}
+	return config, nil
+}
+
+// SelectSyncFolder lets the user pick the shared folder
+func (a *App) SelectSyncFolder() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select sync folder",
+		CanCreateDirectories: true,
+	})
+}
+
+// SyncNow writes this device's changes to the shared folder and applies the changes of the other devices
+func (a *App) SyncNow() (SyncReport, error) {
+	report, err := a.sync()
+	if err != nil {
+		return SyncReport{}, toAppError(err)
+	}
+	return report, nil
+}
+
+func (a *App) sync() (SyncReport, error) {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+
+	report := SyncReport{SyncedAt: time.Now().UTC()}
+	config, err := a.getSyncConfig()
+	if err != nil {
+		return report, err
+	}
+	if config.Folder == "" {
+		return report, conflictError("no sync folder is set")
+	}
+
+	err = os.MkdirAll(config.Folder, 0755)
+	if err == nil {
+		report.Exported, err = a.exportSyncChanges(&config)
+	}
+	if err == nil {
+		err = a.importSyncChanges(config, &report)
+	}
+
+	config.LastSyncAt = &report.SyncedAt
+	config.LastError = ""
+	if err != nil {
+		config.LastError = err.Error()
+	}
+	saveErr := a.db.Model(&config).Updates(map[string]any{"last_sync_at": config.LastSyncAt, "last_error": config.LastError}).Error
+	if saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return report, err
+	}
+	if a.ctx != nil && report.Applied > 0 {
+		runtime.EventsEmit(a.ctx, "sync-applied", report)
+	}
+	return report, nil
+}
+
+// syncRoutine syncs in the background at the configured interval
+func (a *App) syncRoutine() {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			a.withDb(func() {
+				config, err := a.getSyncConfig()
+				if err != nil || config.Folder == "" {
+					return
+				}
+				if config.LastSyncAt != nil && time.Since(*config.LastSyncAt) < time.Duration(config.IntervalMinutes)*time.Minute {
+					return
+				}
+				if _, err := a.sync(); err != nil {
+					Logger.Println("Sync failed:", err)
+				}
+			})
+		}
+	}()
+}
+
+// exportSyncChanges appends the rows changed on this device since the last export to its log.
+// The first export writes every row so the other devices get the existing data
+func (a *App) exportSyncChanges(config *SyncConfig) (int, error) {
+	changes, lastAuditID, err := a.changesSince(config.DeviceID, config.ExportedAuditID, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var buffer bytes.Buffer
+	for _, change := range changes {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return 0, err
+		}
+		buffer.Write(append(data, '\n'))
+	}
+
+	if buffer.Len() > 0 {
+		file, err := os.OpenFile(filepath.Join(config.Folder, config.DeviceID+syncLogExtension), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := file.Write(buffer.Bytes()); err != nil {
+			file.Close()
+			return 0, err
+		}
+		if err := file.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	config.ExportedAuditID = lastAuditID
+	if err := a.db.Model(config).Update("exported_audit_id", lastAuditID).Error; err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
+
+// changesSince returns the current state of the rows changed after an audit entry, or of every row when
+// afterAuditID is 0, along with the last audit entry it covers. Rows changed by sync are only included on request
+func (a *App) changesSince(device string, afterAuditID uint, includeSynced bool) ([]syncChange, uint, error) {
+	type entityRow struct {
+		entity string
+		id     uint
+	}
+	var rows []entityRow
+	seen := make(map[entityRow]bool)
+
+	var lastAuditID uint
+	if err := a.db.Model(&AuditEntry{}).Select("COALESCE(MAX(id), 0)").Row().Scan(&lastAuditID); err != nil {
+		return nil, 0, err
+	}
+
+	if afterAuditID == 0 {
+		for _, table := range []struct{ entity, name string }{
+			{syncEntityOrganization, "organizations"},
+			{syncEntityProject, "projects"},
+			{syncEntitySession, "work_sessions"},
+		} {
+			var ids []uint
+			if err := a.db.Table(table.name).Order("id").Pluck("id", &ids).Error; err != nil {
+				return nil, 0, err
+			}
+			for _, id := range ids {
+				rows = append(rows, entityRow{table.entity, id})
+			}
+		}
+	} else {
+		query := a.db.Where("id > ? AND id <= ?", afterAuditID, lastAuditID).
+			Where("entity_type IN ?", []string{syncEntityOrganization, syncEntityProject, syncEntitySession})
+		if !includeSynced {
+			query = query.Where("source <> ?", AuditSync)
+		}
+		var entries []AuditEntry
+		if err := query.Order("id").Find(&entries).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, entry := range entries {
+			row := entityRow{entry.EntityType, entry.EntityID}
+			if !seen[row] {
+				seen[row] = true
+				rows = append(rows, row)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return syncEntityOrder[rows[i].entity] < syncEntityOrder[rows[j].entity]
+		})
+	}
+
+	var changes []syncChange
+	for _, row := range rows {
+		change, ok, err := a.syncChangeFor(device, row.entity, row.id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes, lastAuditID, nil
+}
+
+// syncChangeFor returns the current state of a row as a change, ok is false when the row is gone
+func (a *App) syncChangeFor(device, entity string, id uint) (syncChange, bool, error) {
+	change := syncChange{Device: device, Entity: entity}
+	changed := func(updatedAt time.Time, deletedAt gorm.DeletedAt) {
+		change.ChangedAt = updatedAt.UTC()
+		if deletedAt.Valid {
+			change.Deleted = true
+			change.ChangedAt = deletedAt.Time.UTC()
+		}
+	}
+
+	switch entity {
+	case syncEntityOrganization:
+		var organization Organization
+		if err := a.db.Unscoped().First(&organization, id).Error; err != nil {
+			return change, false, ignoreNotFound(err)
+		}
+		change.UID = organization.UID
+		changed(organization.UpdatedAt, organization.DeletedAt)
+		change.Organization = &syncOrganization{
+			Name:       organization.Name,
+			Favorite:   organization.Favorite,
+			Timezone:   organization.Timezone,
+			ArchivedAt: organization.ArchivedAt,
+		}
+	case syncEntityProject:
+		var project Project
+		if err := a.db.Unscoped().First(&project, id).Error; err != nil {
+			return change, false, ignoreNotFound(err)
+		}
+		var organization Organization
+		if err := a.db.Unscoped().First(&organization, project.OrganizationID).Error; err != nil {
+			return change, false, ignoreNotFound(err)
+		}
+		change.UID = project.UID
+		changed(project.UpdatedAt, project.DeletedAt)
+		change.Project = &syncProject{
+			OrganizationUID: organization.UID,
+			Name:            project.Name,
+			Favorite:        project.Favorite,
+			ArchivedAt:      project.ArchivedAt,
+		}
+	case syncEntitySession:
+		var session WorkSession
+		if err := a.db.Unscoped().First(&session, id).Error; err != nil {
+			return change, false, ignoreNotFound(err)
+		}
+		var project Project
+		if err := a.db.Unscoped().First(&project, session.ProjectID).Error; err != nil {
+			return change, false, ignoreNotFound(err)
+		}
+		change.UID = session.UID
+		changed(session.UpdatedAt, session.DeletedAt)
+		change.Session = &syncSession{
+			ProjectUID: project.UID,
+			Date:       session.Date,
+			Seconds:    session.Seconds,
+			Parallel:   session.Parallel,
+			StartedAt:  session.StartedAt,
+			EndedAt:    session.EndedAt,
+			Timezone:   session.Timezone,
+		}
+	default:
+		return change, false, nil
+	}
+	return change, true, nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// importSyncChanges applies the new lines of the other devices' logs, each log in its own transaction
+func (a *App) importSyncChanges(config SyncConfig, report *SyncReport) error {
+	entries, err := os.ReadDir(config.Folder)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		device := strings.TrimSuffix(entry.Name(), syncLogExtension)
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), syncLogExtension) || device == config.DeviceID {
+			continue
+		}
+		if err := a.importSyncLog(config, device, filepath.Join(config.Folder, entry.Name()), report); err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (a *App) importSyncLog(config SyncConfig, device, path string, report *SyncReport) error {
+	cursor := SyncCursor{DeviceID: device}
+	if err := a.db.Where(SyncCursor{DeviceID: device}).FirstOrCreate(&cursor).Error; err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if cursor.Offset > info.Size() {
+		// The log was truncated or recreated (a reset device, a sync tool restoring an older copy), read it
+		// again from the start: a change applied twice leaves the rows as they were
+		Logger.Printf("The sync log of %s is shorter than the %d bytes already read, reading it again", device, cursor.Offset)
+		report.Skipped = append(report.Skipped, fmt.Sprintf("the log of %s was truncated, it was read again", device))
+		cursor.Offset = 0
+	}
+	if _, err := file.Seek(cursor.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		reader := bufio.NewReader(file)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				// A line without its newline is still being written or synced, it is read next time
+				break
+			}
+			if err != nil {
+				return err
+			}
+			cursor.Offset += int64(len(line))
+
+			var change syncChange
+			if err := json.Unmarshal(line, &change); err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("unreadable line in the log of %s", device))
+				continue
+			}
+			if err := a.applySyncChange(tx, config.DeviceID, change, report); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&cursor).Error
+	})
+}
+
+// resolveUID follows the aliases of merged rows
+func resolveUID(tx *gorm.DB, uid string) (string, error) {
+	var alias SyncAlias
+	err := tx.Where("uid = ?", uid).First(&alias).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uid, nil
+	}
+	return alias.TargetUID, err
+}
+
+// syncWins reports whether a change replaces a local row last changed at the given time
+func syncWins(change syncChange, device string, localChangedAt time.Time) bool {
+	if change.ChangedAt.Equal(localChangedAt) {
+		return change.Device > device
+	}
+	return change.ChangedAt.After(localChangedAt)
+}
+
+func changedAt(updatedAt time.Time, deletedAt gorm.DeletedAt) time.Time {
+	if deletedAt.Valid {
+		return deletedAt.Time
+	}
+	return updatedAt
+}
+
+// mergeDuplicate handles a row created on another device with the same name as a local row.
+// Both devices end up with the lower UID: the local row takes the incoming UID or the incoming UID becomes an alias
+func mergeDuplicate(tx *gorm.DB, table string, localID uint, localUID, incomingUID string) error {
+	if incomingUID < localUID {
+		if err := tx.Table(table).Where("id = ?", localID).UpdateColumn("uid", incomingUID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&SyncAlias{UID: localUID, TargetUID: incomingUID}).Error
+	}
+	return tx.Create(&SyncAlias{UID: incomingUID, TargetUID: localUID}).Error
+}
+
+func (a *App) applySyncChange(tx *gorm.DB, device string, change syncChange, report *SyncReport) error {
+	uid, err := resolveUID(tx, change.UID)
+	if err != nil {
+		return err
+	}
+	change.UID = uid
+
+	var applied bool
+	switch {
+	case change.Entity == syncEntityOrganization && change.Organization != nil:
+		applied, err = a.applySyncOrganization(tx, device, change)
+	case change.Entity == syncEntityProject && change.Project != nil:
+		applied, err = a.applySyncProject(tx, device, change, report)
+	case change.Entity == syncEntitySession && change.Session != nil:
+		applied, err = a.applySyncSession(tx, device, change, report)
+	default:
+		report.Skipped = append(report.Skipped, fmt.Sprintf("unknown change %q from %s", change.Entity, change.Device))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if applied {
+		report.Applied++
+	} else {
+		report.Ignored++
+	}
+	return nil
+}
+
+// setSyncedAt keeps the change time of the other device so all devices compare the same times
+func setSyncedAt(tx *gorm.DB, model any, change syncChange) error {
+	if change.Deleted {
+		return tx.Unscoped().Model(model).UpdateColumn("deleted_at", change.ChangedAt).Error
+	}
+	return tx.Unscoped().Model(model).UpdateColumn("updated_at", change.ChangedAt).Error
+}
+
+// syncDeletion soft deletes or restores a row to match the change and audits it
+func syncDeletion[T auditable](tx *gorm.DB, id uint, wasDeleted, deleted bool) error {
+	switch {
+	case deleted && !wasDeleted:
+		return deleteAudited[T](tx, AuditSync, []uint{id})
+	case !deleted && wasDeleted:
+		return restoreAudited[T](tx, AuditSync, []uint{id})
+	}
+	return nil
+}
+
+func (a *App) applySyncOrganization(tx *gorm.DB, device string, change syncChange) (bool, error) {
+	var organization Organization
+	err := tx.Unscoped().Where("uid = ?", change.UID).First(&organization).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tx.Where("name = ?", change.Organization.Name).First(&organization).Error
+		if err == nil {
+			if err := mergeDuplicate(tx, "organizations", organization.ID, organization.UID, change.UID); err != nil {
+				return false, err
+			}
+			if change.UID, err = resolveUID(tx, change.UID); err != nil {
+				return false, err
+			}
+			return a.applySyncOrganization(tx, device, change)
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		organization = Organization{UID: change.UID}
+	} else if err != nil {
+		return false, err
+	} else if !syncWins(change, device, changedAt(organization.UpdatedAt, organization.DeletedAt)) {
+		return false, nil
+	}
+
+	before := organization
+	wasDeleted := organization.DeletedAt.Valid
+	organization.Name = change.Organization.Name
+	organization.Favorite = change.Organization.Favorite
+	organization.Timezone = change.Organization.Timezone
+	organization.ArchivedAt = change.Organization.ArchivedAt
+	if organization.ID == 0 {
+		if err := insertAudited(tx, AuditSync, &organization); err != nil {
+			return false, err
+		}
+	} else {
+		if err := tx.Unscoped().Save(&organization).Error; err != nil {
+			return false, err
+		}
+		if err := recordAudit(tx, AuditSync, AuditUpdate, before, organization); err != nil {
+			return false, err
+		}
+		if err := syncDeletion[Organization](tx, organization.ID, wasDeleted, change.Deleted); err != nil {
+			return false, err
+		}
+	}
+	return true, setSyncedAt(tx, &organization, change)
+}
+
+func (a *App) applySyncProject(tx *gorm.DB, device string, change syncChange, report *SyncReport) (bool, error) {
+	organizationUID, err := resolveUID(tx, change.Project.OrganizationUID)
+	if err != nil {
+		return false, err
+	}
+	var organization Organization
+	if err := tx.Unscoped().Where("uid = ?", organizationUID).First(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("project %s: its organization is unknown", change.Project.Name))
+			return false, nil
+		}
+		return false, err
+	}
+
+	var project Project
+	err = tx.Unscoped().Where("uid = ?", change.UID).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tx.Where("organization_id = ? AND name = ?", organization.ID, change.Project.Name).First(&project).Error
+		if err == nil {
+			if err := mergeDuplicate(tx, "projects", project.ID, project.UID, change.UID); err != nil {
+				return false, err
+			}
+			if change.UID, err = resolveUID(tx, change.UID); err != nil {
+				return false, err
+			}
+			return a.applySyncProject(tx, device, change, report)
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		project = Project{UID: change.UID}
+	} else if err != nil {
+		return false, err
+	} else if !syncWins(change, device, changedAt(project.UpdatedAt, project.DeletedAt)) {
+		return false, nil
+	}
+
+	before := project
+	wasDeleted := project.DeletedAt.Valid
+	if project.OrganizationID != organization.ID {
+		project.ClientID = nil
+	}
+	project.OrganizationID = organization.ID
+	project.Name = change.Project.Name
+	project.Favorite = change.Project.Favorite
+	project.ArchivedAt = change.Project.ArchivedAt
+	if project.ID == 0 {
+		if err := insertAudited(tx, AuditSync, &project); err != nil {
+			return false, err
+		}
+		return true, setSyncedAt(tx, &project, change)
+	}
+
+	if err := tx.Unscoped().Save(&project).Error; err != nil {
+		return false, err
+	}
+	if err := recordAudit(tx, AuditSync, AuditUpdate, before, project); err != nil {
+		return false, err
+	}
+	// The project's hours go and come back with it, like DeleteProject and RestoreProject
+	switch {
+	case change.Deleted && !wasDeleted:
+		var hours []uint
+		if err := tx.Model(&WorkHours{}).Where("project_id = ?", project.ID).Pluck("id", &hours).Error; err != nil {
+			return false, err
+		}
+		if err := deleteAudited[WorkHours](tx, AuditSync, hours); err != nil {
+			return false, err
+		}
+		// setSyncedAt dates the project's deletion to the change, the hours must match to be restored with it
+		if len(hours) > 0 {
+			err := tx.Unscoped().Model(&WorkHours{}).Where("id IN ?", hours).UpdateColumn("deleted_at", change.ChangedAt).Error
+			if err != nil {
+				return false, err
+			}
+		}
+	case !change.Deleted && wasDeleted:
+		hours, err := deletedHours(tx, project)
+		if err != nil {
+			return false, err
+		}
+		if err := restoreAudited[WorkHours](tx, AuditSync, hours); err != nil {
+			return false, err
+		}
+	}
+	if err := syncDeletion[Project](tx, project.ID, wasDeleted, change.Deleted); err != nil {
+		return false, err
+	}
+	return true, setSyncedAt(tx, &project, change)
+}
+
+func (a *App) applySyncSession(tx *gorm.DB, device string, change syncChange, report *SyncReport) (bool, error) {
+	projectUID, err := resolveUID(tx, change.Session.ProjectUID)
+	if err != nil {
+		return false, err
+	}
+	var project Project
+	if err := tx.Unscoped().Where("uid = ?", projectUID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("session on %s: its project is unknown", change.Session.Date))
+			return false, nil
+		}
+		return false, err
+	}
+
+	var session WorkSession
+	err = tx.Unscoped().Where("uid = ?", change.UID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		session = WorkSession{UID: change.UID}
+	} else if err != nil {
+		return false, err
+	} else if a.isRunningSession(session.ID) || !syncWins(change, device, changedAt(session.UpdatedAt, session.DeletedAt)) {
+		return false, nil
+	}
+
+	days := []projectDay{{project.ID, change.Session.Date}}
+	if session.ID != 0 {
+		days = append(days, projectDay{session.ProjectID, session.Date})
+	}
+	if err := ensureUnlocked(tx, days...); err != nil {
+		var appErr *AppError
+		if errors.As(err, &appErr) && appErr.Code == ErrLocked {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("session of %s: %s", project.Name, appErr.Message))
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Move the session's seconds out of the old day and into the new one
+	if session.ID != 0 && !session.DeletedAt.Valid {
+		if err := addWorkHours(tx, AuditSync, session.ProjectID, session.Date, -session.Seconds); err != nil {
+			return false, err
+		}
+	}
+	if !change.Deleted {
+		if err := addWorkHours(tx, AuditSync, project.ID, change.Session.Date, change.Session.Seconds); err != nil {
+			return false, err
+		}
+	}
+
+	before := session
+	wasDeleted := session.DeletedAt.Valid
+	if session.ProjectID != project.ID {
+		session.TaskID = nil
+	}
+	session.ProjectID = project.ID
+	session.Date = change.Session.Date
+	session.Seconds = change.Session.Seconds
+	session.Parallel = change.Session.Parallel
+	session.StartedAt = change.Session.StartedAt
+	session.EndedAt = change.Session.EndedAt
+	session.Timezone = change.Session.Timezone
+	if session.ID == 0 {
+		if err := insertAudited(tx, AuditSync, &session); err != nil {
+			return false, err
+		}
+		return true, setSyncedAt(tx, &session, change)
+	}
+
+	if err := tx.Unscoped().Save(&session).Error; err != nil {
+		return false, err
+	}
+	if err := recordAudit(tx, AuditSync, AuditUpdate, before, session); err != nil {
+		return false, err
+	}
+	if err := syncDeletion[WorkSession](tx, session.ID, wasDeleted, change.Deleted); err != nil {
+		return false, err
+	}
+	return true, setSyncedAt(tx, &session, change)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSyncWins(t *testing.T) {
+	local := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		changedAt time.Time
+		from      string
+		want      bool
+	}{
+		{"newer", local.Add(time.Second), "aaa", true},
+		{"older", local.Add(-time.Second), "zzz", false},
+		{"tie from a higher device", local, "ccc", true},
+		{"tie from a lower device", local, "aaa", false},
+	}
+	for _, test := range tests {
+		change := syncChange{Device: test.from, ChangedAt: test.changedAt}
+		if got := syncWins(change, "bbb", local); got != test.want {
+			t.Errorf("%s: syncWins = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// writeSyncLog writes the changes as the log of a device and returns its path
+func writeSyncLog(t *testing.T, folder, device string, changes ...syncChange) string {
+	t.Helper()
+	var lines []string
+	for _, change := range changes {
+		change.Device = device
+		data, err := json.Marshal(change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	path := filepath.Join(folder, device+syncLogExtension)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func syncOrganizationChange(uid, name string, at time.Time) syncChange {
+	return syncChange{Entity: syncEntityOrganization, UID: uid, ChangedAt: at, Organization: &syncOrganization{Name: name}}
+}
+
+func syncProjectChange(uid, organizationUID, name string, at time.Time) syncChange {
+	return syncChange{Entity: syncEntityProject, UID: uid, ChangedAt: at,
+		Project: &syncProject{OrganizationUID: organizationUID, Name: name}}
+}
+
+func syncSessionChange(uid, projectUID, date string, seconds int, at time.Time) syncChange {
+	startedAt := at.Add(-time.Duration(seconds) * time.Second)
+	return syncChange{Entity: syncEntitySession, UID: uid, ChangedAt: at,
+		Session: &syncSession{ProjectUID: projectUID, Date: date, Seconds: seconds, StartedAt: startedAt, EndedAt: at}}
+}
+
+// syncState describes the synced rows of a database by UID, in a form two databases can be compared in
+func syncState(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var state []string
+	var organizations []Organization
+	var projects []Project
+	var sessions []WorkSession
+	var hours []WorkHours
+	for _, rows := range []any{&organizations, &projects, &sessions, &hours} {
+		if err := db.Unscoped().Find(rows).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	organizationUIDs := make(map[uint]string)
+	for _, organization := range organizations {
+		organizationUIDs[organization.ID] = organization.UID
+		state = append(state, "organization "+organization.UID+" "+organization.Name+deletedMark(organization.DeletedAt))
+	}
+	projectUIDs := make(map[uint]string)
+	for _, project := range projects {
+		projectUIDs[project.ID] = project.UID
+		state = append(state, "project "+project.UID+" "+organizationUIDs[project.OrganizationID]+" "+project.Name+deletedMark(project.DeletedAt))
+	}
+	for _, session := range sessions {
+		state = append(state, "session "+session.UID+" "+projectUIDs[session.ProjectID]+" "+session.Date+" "+
+			formatSeconds(session.Seconds)+deletedMark(session.DeletedAt))
+	}
+	for _, day := range hours {
+		if day.DeletedAt.Valid || day.Seconds == 0 {
+			continue
+		}
+		state = append(state, "hours "+projectUIDs[day.ProjectID]+" "+day.Date+" "+formatSeconds(day.Seconds))
+	}
+	sort.Strings(state)
+	return state
+}
+
+func deletedMark(deletedAt gorm.DeletedAt) string {
+	if deletedAt.Valid {
+		return " deleted"
+	}
+	return ""
+}
+
+func formatSeconds(seconds int) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func TestSyncConverges(t *testing.T) {
+	folder := t.TempDir()
+	at := func(minute int) time.Time { return time.Date(2026, 3, 2, 18, minute, 0, 0, time.UTC) }
+
+	// Both devices created "Acme" and its project "Web" on their own. Device aaa tracked three sessions,
+	// device bbb later shortened one, deleted one and tracked one on its own copy of "Web"
+	logA := writeSyncLog(t, folder, "aaa",
+		syncOrganizationChange("o-2", "Acme", at(0)),
+		syncProjectChange("p-2", "o-2", "Web", at(0)),
+		syncProjectChange("p-9", "o-2", "Mobile", at(0)),
+		syncSessionChange("s-1", "p-2", "2026-03-02", 3600, at(10)),
+		syncSessionChange("s-3", "p-9", "2026-03-02", 900, at(10)),
+		syncSessionChange("s-4", "p-2", "2026-03-04", 1200, at(10)),
+	)
+	deleted := syncSessionChange("s-4", "p-3", "2026-03-04", 1200, at(30))
+	deleted.Deleted = true
+	logB := writeSyncLog(t, folder, "bbb",
+		syncOrganizationChange("o-1", "Acme", at(1)),
+		syncProjectChange("p-3", "o-1", "Web", at(1)),
+		syncSessionChange("s-1", "p-3", "2026-03-02", 1800, at(20)),
+		syncSessionChange("s-2", "p-3", "2026-03-03", 600, at(20)),
+		// An older change loses whichever log is read first
+		syncSessionChange("s-3", "p-3", "2026-03-02", 60, at(5)),
+		deleted,
+	)
+
+	first := newTestApp(t)
+	second := newTestApp(t)
+	for _, test := range []struct {
+		app  *App
+		logs []string
+	}{
+		{first, []string{logA, logB}},
+		{second, []string{logB, logA}},
+	} {
+		config, err := test.app.getSyncConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report SyncReport
+		for _, path := range test.logs {
+			device := strings.TrimSuffix(filepath.Base(path), syncLogExtension)
+			if err := test.app.importSyncLog(config, device, path, &report); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(report.Skipped) != 0 {
+			t.Errorf("skipped %v", report.Skipped)
+		}
+	}
+
+	// The lower UIDs are kept, the session on the merged project follows the alias
+	want := []string{
+		"hours p-2 2026-03-02 30m0s",
+		"hours p-2 2026-03-03 10m0s",
+		"hours p-9 2026-03-02 15m0s",
+		"organization o-1 Acme",
+		"project p-2 o-1 Web",
+		"project p-9 o-1 Mobile",
+		"session s-1 p-2 2026-03-02 30m0s",
+		"session s-2 p-2 2026-03-03 10m0s",
+		"session s-3 p-9 2026-03-02 15m0s",
+		"session s-4 p-2 2026-03-04 20m0s deleted",
+	}
+	if got := syncState(t, first.db); !reflect.DeepEqual(got, want) {
+		t.Errorf("logs of aaa then bbb:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := syncState(t, second.db); !reflect.DeepEqual(got, want) {
+		t.Errorf("logs of bbb then aaa:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Merged UIDs resolve to the kept ones on both databases
+	for _, a := range []*App{first, second} {
+		for uid, target := range map[string]string{"o-2": "o-1", "p-3": "p-2"} {
+			if resolved, err := resolveUID(a.db, uid); err != nil || resolved != target {
+				t.Errorf("resolveUID(%s) = %s, %v, want %s", uid, resolved, err, target)
+			}
+		}
+	}
+}
+
+func TestImportSyncLogTruncated(t *testing.T) {
+	folder := t.TempDir()
+	at := func(minute int) time.Time { return time.Date(2026, 3, 2, 18, minute, 0, 0, time.UTC) }
+	a := newTestApp(t)
+	config, err := a.getSyncConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeSyncLog(t, folder, "aaa",
+		syncOrganizationChange("o-1", "Acme", at(0)),
+		syncProjectChange("p-1", "o-1", "Web", at(0)),
+		syncSessionChange("s-1", "p-1", "2026-03-02", 3600, at(10)),
+	)
+	if err := a.importSyncLog(config, "aaa", path, &SyncReport{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The device was reset and started its log over, shorter than what was read before
+	writeSyncLog(t, folder, "aaa", syncProjectChange("p-1", "o-1", "Website", at(40)))
+	var report SyncReport
+	if err := a.importSyncLog(config, "aaa", path, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Applied != 1 || len(report.Skipped) != 1 {
+		t.Errorf("applied %d, skipped %v, want the change applied and the truncation reported", report.Applied, report.Skipped)
+	}
+	var project Project
+	if err := a.db.Where("uid = ?", "p-1").First(&project).Error; err != nil || project.Name != "Website" {
+		t.Errorf("project = %q, %v, want Website", project.Name, err)
+	}
+
+	// Appending continues from the new end
+	var cursor SyncCursor
+	if err := a.db.Where("device_id = ?", "aaa").First(&cursor).Error; err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || cursor.Offset != info.Size() {
+		t.Errorf("cursor at %d, log is %d bytes", cursor.Offset, info.Size())
+	}
+}
+
+func TestSyncRestoresProjectOnEncryptedDatabase(t *testing.T) {
+	folder := t.TempDir()
+	at := func(minute int) time.Time { return time.Date(2026, 3, 2, 18, minute, 0, 0, time.UTC) }
+	a := newEncryptedTestApp(t)
+	config, err := a.getSyncConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The encrypted store has a single connection, the restored hours are read inside the sync transaction
+	deleted := syncProjectChange("p-1", "o-1", "Web", at(20))
+	deleted.Deleted = true
+	path := writeSyncLog(t, folder, "aaa",
+		syncOrganizationChange("o-1", "Acme", at(0)),
+		syncProjectChange("p-1", "o-1", "Web", at(0)),
+		syncSessionChange("s-1", "p-1", "2026-03-02", 3600, at(10)),
+		deleted,
+		syncProjectChange("p-1", "o-1", "Web", at(30)),
+	)
+	var report SyncReport
+	if err := a.importSyncLog(config, "aaa", path, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"hours p-1 2026-03-02 1h0m0s",
+		"organization o-1 Acme",
+		"project p-1 o-1 Web",
+		"session s-1 p-1 2026-03-02 1h0m0s",
+	}
+	if got := syncState(t, a.db); !reflect.DeepEqual(got, want) {
+		t.Errorf("state:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaskStatus string
+
+const (
+	TaskTodo       TaskStatus = "todo"
+	TaskInProgress TaskStatus = "in_progress"
+	TaskDone       TaskStatus = "done"
+)
+
+var taskStatuses = map[TaskStatus]bool{
+	TaskTodo:       true,
+	TaskInProgress: true,
+	TaskDone:       true,
+}
+
+// Task is a unit of work within a project, tasks can be nested below another task of the same project.
+// Time tracked on a task is part of its project's WorkHours, so project totals always include it
+type Task struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Name            string         `json:"name"`
+	ProjectID       uint           `gorm:"index" json:"project_id"`
+	ParentID        *uint          `gorm:"index" json:"parent_id"` // nil for top level tasks
+	Status          TaskStatus     `json:"status"`
+	EstimateSeconds int            `json:"estimate_seconds"` // 0 when there is no estimate
+}
+
+func (t Task) auditInfo() (string, uint, uint, string) {
+	return "task", t.ID, t.ProjectID, ""
+}
+
+// TaskTime is the time tracked on a task in a date range
+type TaskTime struct {
+	TaskID          uint   `json:"task_id"`
+	Path            string `json:"path"`             // "Parent / Child"
+	Seconds         int    `json:"seconds"`          // tracked on the task itself
+	TotalSeconds    int    `json:"total_seconds"`    // including its subtasks
+	EstimateSeconds int    `json:"estimate_seconds"` // including its subtasks
+}
+
+// TaskTotal is a task's share of a project's time in an export
+type TaskTotal struct {
+	Project string
+	Task    string
+	Seconds int
+}
+
+func (a *App) getTask(taskID uint) (Task, error) {
+	var task Task
+	if err := a.db.Where(&Task{ID: taskID}).First(&task).Error; err != nil {
+		return Task{}, dbError(err, "task", taskID)
+	}
+	return task, nil
+}
+
+// validateTaskParent checks that the parent belongs to the same project and isn't the task or one of its subtasks
+func (a *App) validateTaskParent(task Task) error {
+	if task.ParentID == nil {
+		return nil
+	}
+	for parentID := task.ParentID; parentID != nil; {
+		if *parentID == task.ID {
+			return validationError("a task can't be nested below itself or one of its subtasks")
+		}
+		parent, err := a.getTask(*parentID)
+		if err != nil {
+			return err
+		}
+		if parent.ProjectID != task.ProjectID {
+			return validationError("the parent task belongs to another project")
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func validateTask(task Task) error {
+	if strings.TrimSpace(task.Name) == "" {
+		return validationError("task name is empty")
+	}
+	if !taskStatuses[task.Status] {
+		return validationError(fmt.Sprintf("unknown task status %q", task.Status))
+	}
+	if task.EstimateSeconds < 0 {
+		return validationError("task estimate can't be negative")
+	}
+	return nil
+}
+
+// NewTask creates a task for the project, below parentID unless it is 0
+func (a *App) NewTask(projectID uint, parentID uint, name string, estimateSeconds int) (Task, error) {
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	task := Task{Name: strings.TrimSpace(name), ProjectID: project.ID, Status: TaskTodo, EstimateSeconds: estimateSeconds}
+	if parentID != 0 {
+		task.ParentID = &parentID
+	}
+	if err := validateTask(task); err != nil {
+		return Task{}, err
+	}
+	if err := a.validateTaskParent(task); err != nil {
+		return Task{}, err
+	}
+
+	if err := a.createAudited(AuditManual, &task); err != nil {
+		return Task{}, toAppError(err)
+	}
+	return task, nil
+}
+
+// UpdateTask saves the name, parent, status and estimate of a task
+func (a *App) UpdateTask(task Task) (Task, error) {
+	existing, err := a.getTask(task.ID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	updated := existing
+	updated.Name = strings.TrimSpace(task.Name)
+	updated.ParentID = task.ParentID
+	if updated.ParentID != nil && *updated.ParentID == 0 {
+		updated.ParentID = nil
+	}
+	updated.Status = task.Status
+	updated.EstimateSeconds = task.EstimateSeconds
+	if err := validateTask(updated); err != nil {
+		return Task{}, err
+	}
+	if err := a.validateTaskParent(updated); err != nil {
+		return Task{}, err
+	}
+
+	if err := a.saveAudited(existing, &updated); err != nil {
+		return Task{}, toAppError(err)
+	}
+	if a.task.ID == updated.ID {
+		a.task = updated
+	}
+	return updated, nil
+}
+
+// DeleteTask deletes a task and its subtasks, their sessions keep counting towards the project
+func (a *App) DeleteTask(taskID uint) error {
+	task, err := a.getTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := a.GetTasks(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	ids := append([]uint{task.ID}, subtaskIDs(tasks, task.ID)...)
+	for _, id := range ids {
+		if a.isTimedTask(id) {
+			return conflictError(fmt.Sprintf("the timer is running for %s, stop it first", task.Name))
+		}
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		return deleteAudited[Task](tx, AuditManual, ids)
+	})
+	if err != nil {
+		return toAppError(err)
+	}
+	for _, id := range ids {
+		if a.task.ID == id {
+			a.task = Task{}
+		}
+	}
+	return nil
+}
+
+// subtaskIDs returns the IDs of all tasks nested below the task
+func subtaskIDs(tasks []Task, taskID uint) []uint {
+	var ids []uint
+	for _, task := range tasks {
+		if task.ParentID != nil && *task.ParentID == taskID {
+			ids = append(ids, task.ID)
+			ids = append(ids, subtaskIDs(tasks, task.ID)...)
+		}
+	}
+	return ids
+}
+
+// taskPaths returns the "Parent / Child" name of each task
+func taskPaths(tasks []Task) map[uint]string {
+	byID := make(map[uint]Task)
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	paths := make(map[uint]string)
+	for _, task := range tasks {
+		names := []string{task.Name}
+		// The depth limit guards against cycles in data edited outside the app
+		for parent, depth := task.ParentID, 0; parent != nil && depth < len(tasks); depth++ {
+			parentTask, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			names = append([]string{parentTask.Name}, names...)
+			parent = parentTask.ParentID
+		}
+		paths[task.ID] = strings.Join(names, " / ")
+	}
+	return paths
+}
+
+// GetTasks returns the tasks of the project
+func (a *App) GetTasks(projectID uint) (tasks []Task, err error) {
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.db.Where(&Task{ProjectID: project.ID}).Order("id").Find(&tasks).Error; err != nil {
+		return nil, toAppError(err)
+	}
+	return tasks, nil
+}
+
+// GetTaskTimes returns the time tracked on each of the project's tasks between the dates, rolled up into parent tasks
+func (a *App) GetTaskTimes(projectID uint, startDate, endDate string) ([]TaskTime, error) {
+	tasks, err := a.GetTasks(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	type taskSeconds struct {
+		TaskID  uint
+		Seconds int
+	}
+	var tracked []taskSeconds
+	err = a.db.Model(&WorkSession{}).
+		Select("task_id, COALESCE(SUM(seconds), 0) AS seconds").
+		Where("project_id = ? AND task_id IS NOT NULL", projectID).
+		Where("date >= ? AND date <= ?", startDate, endDate).
+		Group("task_id").
+		Scan(&tracked).Error
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	seconds := make(map[uint]int)
+	for _, row := range tracked {
+		seconds[row.TaskID] = row.Seconds
+	}
+
+	estimates := make(map[uint]int)
+	for _, task := range tasks {
+		estimates[task.ID] = task.EstimateSeconds
+	}
+
+	paths := taskPaths(tasks)
+	var times []TaskTime
+	for _, task := range tasks {
+		taskTime := TaskTime{
+			TaskID:          task.ID,
+			Path:            paths[task.ID],
+			Seconds:         seconds[task.ID],
+			TotalSeconds:    seconds[task.ID],
+			EstimateSeconds: task.EstimateSeconds,
+		}
+		for _, id := range subtaskIDs(tasks, task.ID) {
+			taskTime.TotalSeconds += seconds[id]
+			taskTime.EstimateSeconds += estimates[id]
+		}
+		times = append(times, taskTime)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Path < times[j].Path
+	})
+	return times, nil
+}
+
+// SetTask selects the task the timer tracks, 0 tracks the project without a task.
+// Like switching projects, switching tasks stops a running timer
+func (a *App) SetTask(taskID uint) error {
+	if a.isRunning && a.task.ID != taskID {
+		if err := a.StopTimer(); err != nil {
+			return err
+		}
+	}
+	if taskID == 0 {
+		a.task = Task{}
+		return nil
+	}
+
+	task, err := a.getTask(taskID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID != a.project.ID {
+		return validationError(fmt.Sprintf("%s doesn't belong to the selected project", task.Name))
+	}
+	a.task = task
+	return nil
+}
+
+// SetWorkSessionTask assigns a finished session to one of its project's tasks, 0 removes the task
+func (a *App) SetWorkSessionTask(workSessionID, taskID uint) (WorkSession, error) {
+	workSession, err := a.getWorkSession(workSessionID)
+	if err != nil {
+		return WorkSession{}, err
+	}
+
+	if err := ensureUnlocked(a.db, projectDay{workSession.ProjectID, workSession.Date}); err != nil {
+		return WorkSession{}, toAppError(err)
+	}
+
+	before := workSession
+	workSession.TaskID = nil
+	if taskID != 0 {
+		task, err := a.getTask(taskID)
+		if err != nil {
+			return WorkSession{}, err
+		}
+		if task.ProjectID != workSession.ProjectID {
+			return WorkSession{}, validationError(fmt.Sprintf("%s belongs to another project than the session", task.Name))
+		}
+		workSession.TaskID = &task.ID
+	}
+
+	if err := a.saveAudited(before, &workSession); err != nil {
+		return WorkSession{}, toAppError(err)
+	}
+	return workSession, nil
+}
+
+// getTaskTotals returns the time per task of the scope's projects between the dates
+func (a *App) getTaskTotals(scope reportScope, startDate, endDate string) ([]TaskTotal, error) {
+	var tasks []Task
+	err := a.db.Joins("JOIN projects ON projects.id = tasks.project_id").
+		Where("projects.deleted_at IS NULL").
+		Scopes(scope.filter).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	paths := taskPaths(tasks)
+
+	rows, err := a.db.Table("work_sessions").
+		Select(scope.label+", work_sessions.task_id, COALESCE(SUM(work_sessions.seconds), 0)").
+		Joins("JOIN projects ON projects.id = work_sessions.project_id").
+		Where("projects.deleted_at IS NULL AND work_sessions.deleted_at IS NULL"). // Ignore deleted projects and sessions
+		Scopes(scope.filter).
+		Where("work_sessions.task_id IS NOT NULL").
+		Where("work_sessions.date >= ? AND work_sessions.date <= ?", startDate, endDate).
+		Group(scope.label + ", work_sessions.task_id").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []TaskTotal
+	for rows.Next() {
+		var total TaskTotal
+		var taskID uint
+		if err := rows.Scan(&total.Project, &taskID, &total.Seconds); err != nil {
+			return nil, err
+		}
+		path, ok := paths[taskID]
+		if !ok || total.Seconds == 0 {
+			// Sessions of deleted tasks only count towards the project
+			continue
+		}
+		total.Task = path
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Project != totals[j].Project {
+			return totals[i].Project < totals[j].Project
+		}
+		return totals[i].Task < totals[j].Task
+	})
+	return totals, nil
+}
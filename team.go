@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pushing to a team server (see server.go)
+//
+// Changes are first queued in the local database and then pushed in batches, an item leaves the queue once the
+// server took it. While the server can't be reached the queue simply grows and is pushed later. Unlike the sync
+// log, the queue also carries changes received from other devices, so the server gets them even when only one
+// device pushes.
+
+const (
+	teamConfigID      = 1
+	teamPushBatchSize = 500
+	teamPushTimeout   = 30 * time.Second
+)
+
+// TeamConfig is this device's team server setup
+type TeamConfig struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ServerURL     string     `json:"server_url"` // empty disables pushing
+	Token         string     `json:"token"`      // only stored in an encrypted database and never sent back, see redacted
+	QueuedAuditID uint       `json:"-"`          // last audit entry added to the queue
+	LastPushAt    *time.Time `json:"last_push_at"`
+	LastError     string     `json:"last_error"`
+	Pending       int64      `gorm:"-" json:"pending"` // changes waiting in the queue
+	TokenSet      bool       `gorm:"-" json:"token_set"`
+}
+
+// TeamQueueItem is a change waiting to be pushed
+type TeamQueueItem struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Change    string // syncChange as JSON
+}
+
+// teamMu keeps pushes from overlapping
+var teamMu sync.Mutex
+
+func (a *App) getTeamConfig() (TeamConfig, error) {
+	var config TeamConfig
+	if err := a.db.FirstOrCreate(&config, TeamConfig{ID: teamConfigID}).Error; err != nil {
+		return TeamConfig{}, err
+	}
+	err := a.db.Model(&TeamQueueItem{}).Count(&config.Pending).Error
+	return config, err
+}
+
+// redacted returns the setup without the API token, only whether it is set
+func (c TeamConfig) redacted() TeamConfig {
+	c.TokenSet = c.Token != ""
+	c.Token = ""
+	return c
+}
+
+// GetTeamConfig returns the team server this device pushes to, without its API token
+func (a *App) GetTeamConfig() (TeamConfig, error) {
+	config, err := a.getTeamConfig()
+	if err != nil {
+		return TeamConfig{}, toAppError(err)
+	}
+	return config.redacted(), nil
+}
+
+// SaveTeamConfig sets the team server and API token, an empty URL turns pushing off and an empty token keeps
+// the saved one of the same server. Switching to another server or account starts over with all the data
+func (a *App) SaveTeamConfig(serverURL string, token string) (TeamConfig, error) {
+	serverURL = strings.TrimRight(strings.TrimSpace(serverURL), "/")
+	token = strings.TrimSpace(token)
+	if serverURL != "" {
+		parsed, err := url.Parse(serverURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return TeamConfig{}, validationError("the server URL must start with http:// or https://")
+		}
+	}
+
+	teamMu.Lock()
+	defer teamMu.Unlock()
+	config, err := a.getTeamConfig()
+	if err != nil {
+		return TeamConfig{}, toAppError(err)
+	}
+	if serverURL != "" {
+		if token == "" && serverURL == config.ServerURL {
+			token = config.Token
+		}
+		if token == "" {
+			return TeamConfig{}, validationError("an API token is required")
+		}
+		if token != config.Token {
+			if err := a.requireEncryption("the API token is stored in it"); err != nil {
+				return TeamConfig{}, err
+			}
+		}
+	}
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if config.ServerURL != serverURL || config.Token != token {
+			if err := tx.Where("1 = 1").Delete(&TeamQueueItem{}).Error; err != nil {
+				return err
+			}
+			config.QueuedAuditID = 0
+			config.LastPushAt = nil
+			config.LastError = ""
+		}
+		config.ServerURL = serverURL
+		config.Token = token
+		return tx.Save(&config).Error
+	})
+	if err != nil {
+		return TeamConfig{}, toAppError(err)
+	}
+	return a.GetTeamConfig()
+}
+
+// PushNow queues this device's changes and pushes the queue to the team server
+func (a *App) PushNow() (TeamConfig, error) {
+	if err := a.pushToTeam(); err != nil {
+		return TeamConfig{}, toAppError(err)
+	}
+	return a.GetTeamConfig()
+}
+
+func (a *App) pushToTeam() error {
+	teamMu.Lock()
+	defer teamMu.Unlock()
+
+	config, err := a.getTeamConfig()
+	if err != nil {
+		return err
+	}
+	if config.ServerURL == "" {
+		return conflictError("no team server is set")
+	}
+
+	err = a.queueTeamChanges(&config)
+	if err == nil {
+		err = a.pushTeamQueue(config)
+	}
+
+	now := time.Now().UTC()
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	saveErr := a.db.Model(&config).Updates(map[string]any{"last_push_at": &now, "last_error": lastError}).Error
+	if err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// queueTeamChanges adds the rows changed since the last run to the queue, the first run queues every row
+func (a *App) queueTeamChanges(config *TeamConfig) error {
+	syncConfig, err := a.getSyncConfig()
+	if err != nil {
+		return err
+	}
+	changes, lastAuditID, err := a.changesSince(syncConfig.DeviceID, config.QueuedAuditID, true)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			data, err := json.Marshal(change)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&TeamQueueItem{Change: string(data)}).Error; err != nil {
+				return err
+			}
+		}
+		config.QueuedAuditID = lastAuditID
+		return tx.Model(config).Update("queued_audit_id", lastAuditID).Error
+	})
+}
+
+// pushTeamQueue sends the queue in batches, oldest first, and stops at the first batch the server doesn't take
+func (a *App) pushTeamQueue(config TeamConfig) error {
+	syncConfig, err := a.getSyncConfig()
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: teamPushTimeout}
+
+	for {
+		var items []TeamQueueItem
+		if err := a.db.Order("id").Limit(teamPushBatchSize).Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		push := struct {
+			DeviceID string            `json:"device_id"`
+			Changes  []json.RawMessage `json:"changes"`
+		}{DeviceID: syncConfig.DeviceID}
+		for _, item := range items {
+			push.Changes = append(push.Changes, json.RawMessage(item.Change))
+		}
+		body, err := json.Marshal(push)
+		if err != nil {
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, config.ServerURL+"/api/push", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", "Bearer "+config.Token)
+		request.Header.Set("Content-Type", "application/json")
+		response, err := client.Do(request)
+		if err != nil {
+			return fmt.Errorf("the team server can't be reached, %d changes are kept for later: %w", len(items), err)
+		}
+		err = teamResponseError(response)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := a.db.Where("id <= ?", items[len(items)-1].ID).Delete(&TeamQueueItem{}).Error; err != nil {
+			return err
+		}
+	}
+}
+
+// teamResponseError returns the error message of a failed server response
+func teamResponseError(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if json.Unmarshal(data, &body) != nil || body.Error == "" {
+		body.Error = response.Status
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		return errors.New("the team server refused the API token: " + body.Error)
+	}
+	return errors.New("the team server failed: " + body.Error)
+}
+
+// teamRoutine pushes to the team server in the background, the queue is kept while the server is unreachable
+func (a *App) teamRoutine() {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			a.withDb(func() {
+				config, err := a.getTeamConfig()
+				if err != nil || config.ServerURL == "" {
+					return
+				}
+				if err := a.pushToTeam(); err != nil {
+					Logger.Println("Team push failed:", err)
+				}
+			})
+		}
+	}()
+}
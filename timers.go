@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Overlapping time
+//
+// The main timer and any parallel timers each record their full time to their own project, so project
+// totals, and the hours billed per project or client, count overlapping time once per project. Organization
+// and daily totals are sums of project totals and can therefore exceed the time actually spent.
+// Overlaps are never silently merged, GetTimerOverlaps and the monthly exports list them instead.
+
+const maxTimerStack = 20
+
+// TimerFrame is a timer that was switched away from and can be returned to
+type TimerFrame struct {
+	Organization Organization `json:"organization"`
+	Project      Project      `json:"project"`
+	Task         Task         `json:"task"`
+	SwitchedAt   time.Time    `json:"switched_at"`
+}
+
+// parallelTimer runs next to the main timer when parallel timers are enabled
+type parallelTimer struct {
+	organization Organization
+	project      Project
+	task         Task
+	startTime    time.Time
+	location     *time.Location
+	session      WorkSession
+	cancel       context.CancelFunc
+}
+
+// ParallelTimer is the state of a parallel timer as shown to the frontend
+type ParallelTimer struct {
+	Organization Organization `json:"organization"`
+	Project      Project      `json:"project"`
+	Task         Task         `json:"task"`
+	StartedAt    time.Time    `json:"started_at"`
+	TimeElapsed  int          `json:"timeElapsed"`
+}
+
+// TimerOverlap is a stretch of time tracked on more than one project at once
+type TimerOverlap struct {
+	Date      string    `json:"date"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Seconds   int       `json:"seconds"`
+	Projects  []string  `json:"projects"`
+}
+
+// timerTarget resolves the organization, project and task a timer should track, taskID 0 tracks no task
+func (a *App) timerTarget(projectID, taskID uint) (Organization, Project, Task, error) {
+	project, err := a.getProject(projectID)
+	if err != nil {
+		return Organization{}, Project{}, Task{}, err
+	}
+	if project.ArchivedAt != nil {
+		return Organization{}, Project{}, Task{}, conflictError(fmt.Sprintf("%s is archived, unarchive it first", project.Name))
+	}
+	organization, err := a.getOrganization(project.OrganizationID)
+	if err != nil {
+		return Organization{}, Project{}, Task{}, err
+	}
+
+	var task Task
+	if taskID != 0 {
+		if task, err = a.getTask(taskID); err != nil {
+			return Organization{}, Project{}, Task{}, err
+		}
+		if task.ProjectID != project.ID {
+			return Organization{}, Project{}, Task{}, validationError(fmt.Sprintf("%s doesn't belong to %s", task.Name, project.Name))
+		}
+	}
+	return organization, project, task, nil
+}
+
+// startMainTimer replaces what the main timer tracks and starts it, saving the time of the previous one
+func (a *App) startMainTimer(organization Organization, project Project, task Task) error {
+	if a.isParallel(project.ID) {
+		return conflictError(fmt.Sprintf("a parallel timer is running for %s, stop it first", project.Name))
+	}
+	if err := a.timerUnlocked(organization.ID); err != nil {
+		return err
+	}
+	if err := a.StopTimer(); err != nil {
+		return err
+	}
+	a.task = task
+	if err := a.StartTimer(organization, project); err != nil {
+		return err
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "timer-switched", a.GetActiveTimer())
+	}
+	return nil
+}
+
+// SwitchTimer moves the main timer to another project and remembers the current one, see ReturnToPreviousTimer
+func (a *App) SwitchTimer(projectID, taskID uint) (ActiveTimer, error) {
+	organization, project, task, err := a.timerTarget(projectID, taskID)
+	if err != nil {
+		return ActiveTimer{}, err
+	}
+	if a.isRunning && a.project.ID == project.ID && a.task.ID == task.ID {
+		return a.GetActiveTimer(), nil
+	}
+
+	previous := TimerFrame{Organization: a.organization, Project: a.project, Task: a.task, SwitchedAt: time.Now().UTC()}
+	if err := a.startMainTimer(organization, project, task); err != nil {
+		return ActiveTimer{}, err
+	}
+	if previous.Project.ID != 0 {
+		a.timerStack = append(a.timerStack, previous)
+		if len(a.timerStack) > maxTimerStack {
+			a.timerStack = a.timerStack[len(a.timerStack)-maxTimerStack:]
+		}
+	}
+	return a.GetActiveTimer(), nil
+}
+
+// ReturnToPreviousTimer switches the main timer back to the project it tracked before the last switch.
+// Entries whose project was deleted or archived since are skipped
+func (a *App) ReturnToPreviousTimer() (ActiveTimer, error) {
+	for len(a.timerStack) > 0 {
+		frame := a.timerStack[len(a.timerStack)-1]
+		a.timerStack = a.timerStack[:len(a.timerStack)-1]
+
+		organization, project, task, err := a.timerTarget(frame.Project.ID, frame.Task.ID)
+		if err != nil {
+			Logger.Println("Skipping previous timer:", err)
+			continue
+		}
+		if err := a.startMainTimer(organization, project, task); err != nil {
+			return ActiveTimer{}, err
+		}
+		return a.GetActiveTimer(), nil
+	}
+	return ActiveTimer{}, conflictError("there is no previous timer to return to")
+}
+
+// GetTimerStack returns the timers that can be returned to, the most recent last
+func (a *App) GetTimerStack() []TimerFrame {
+	return append([]TimerFrame{}, a.timerStack...)
+}
+
+// isParallel reports whether a parallel timer is running for the project
+func (a *App) isParallel(projectID uint) bool {
+	a.parallelMu.Lock()
+	defer a.parallelMu.Unlock()
+	_, ok := a.parallel[projectID]
+	return ok
+}
+
+// isTimed reports whether the main timer or a parallel timer is running for the project
+func (a *App) isTimed(projectID uint) bool {
+	return (a.isRunning && a.project.ID == projectID) || a.isParallel(projectID)
+}
+
+// isTimedOrganization reports whether any running timer tracks one of the organization's projects
+func (a *App) isTimedOrganization(organizationID uint) bool {
+	if a.isRunning && a.organization.ID == organizationID {
+		return true
+	}
+	a.parallelMu.Lock()
+	defer a.parallelMu.Unlock()
+	for _, timer := range a.parallel {
+		if timer.organization.ID == organizationID {
+			return true
+		}
+	}
+	return false
+}
+
+// isRunningSession reports whether a running timer is still writing to the session
+func (a *App) isRunningSession(workSessionID uint) bool {
+	if a.isRunning && a.session.ID == workSessionID {
+		return true
+	}
+	a.parallelMu.Lock()
+	defer a.parallelMu.Unlock()
+	for _, timer := range a.parallel {
+		if timer.session.ID == workSessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// isTimedTask reports whether a running timer tracks the task
+func (a *App) isTimedTask(taskID uint) bool {
+	if a.isRunning && a.task.ID == taskID {
+		return true
+	}
+	a.parallelMu.Lock()
+	defer a.parallelMu.Unlock()
+	for _, timer := range a.parallel {
+		if timer.task.ID == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// StartParallelTimer starts a timer for the project next to the main timer, parallel timers have to be enabled in the settings
+func (a *App) StartParallelTimer(projectID, taskID uint) (ParallelTimer, error) {
+	if !a.settings.AllowParallelTimers {
+		return ParallelTimer{}, conflictError("parallel timers are disabled in the settings")
+	}
+	organization, project, task, err := a.timerTarget(projectID, taskID)
+	if err != nil {
+		return ParallelTimer{}, err
+	}
+	if a.isTimed(project.ID) {
+		return ParallelTimer{}, conflictError(fmt.Sprintf("a timer is already running for %s", project.Name))
+	}
+	if err := a.timerUnlocked(organization.ID); err != nil {
+		return ParallelTimer{}, err
+	}
+
+	timer := &parallelTimer{
+		organization: organization,
+		project:      project,
+		task:         task,
+		startTime:    time.Now(),
+		location:     a.reportingLocation(organization.ID),
+	}
+	a.runParallelTimer(timer)
+	return timer.state(), nil
+}
+
+func (a *App) runParallelTimer(timer *parallelTimer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	timer.cancel = cancel
+
+	a.parallelMu.Lock()
+	if a.parallel == nil {
+		a.parallel = make(map[uint]*parallelTimer)
+	}
+	a.parallel[timer.project.ID] = timer
+	a.parallelMu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-time.After(1 * time.Minute):
+				a.withDb(func() {
+					if ctx.Err() != nil {
+						return // stopped while waiting, e.g. to switch the database
+					}
+					if err := a.saveParallelTimer(timer); err != nil {
+						Logger.Println(err)
+						runtime.EventsEmit(a.ctx, "timer-error", toAppError(err))
+					}
+				})
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (a *App) saveParallelTimer(timer *parallelTimer) error {
+	taskID := (*uint)(nil)
+	if timer.task.ID != 0 {
+		taskID = &timer.task.ID
+	}
+	_, err := a.saveSession(timer.project.ID, taskID, timer.startTime, timer.location, &timer.session, true)
+	return err
+}
+
+// StopParallelTimer stops the project's parallel timer, the timer is stopped even if the final save fails
+func (a *App) StopParallelTimer(projectID uint) error {
+	a.parallelMu.Lock()
+	timer, ok := a.parallel[projectID]
+	delete(a.parallel, projectID)
+	a.parallelMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	timer.cancel()
+	return a.saveParallelTimer(timer)
+}
+
+// stopParallelTimers stops all parallel timers, used on shutdown
+func (a *App) stopParallelTimers() {
+	for _, timer := range a.GetParallelTimers() {
+		if err := a.StopParallelTimer(timer.Project.ID); err != nil {
+			Logger.Println(err)
+		}
+	}
+}
+
+// GetParallelTimers returns the running parallel timers
+func (a *App) GetParallelTimers() []ParallelTimer {
+	a.parallelMu.Lock()
+	defer a.parallelMu.Unlock()
+	timers := []ParallelTimer{}
+	for _, timer := range a.parallel {
+		timers = append(timers, timer.state())
+	}
+	sort.Slice(timers, func(i, j int) bool {
+		return timers[i].StartedAt.Before(timers[j].StartedAt)
+	})
+	return timers
+}
+
+func (t *parallelTimer) state() ParallelTimer {
+	return ParallelTimer{
+		Organization: t.organization,
+		Project:      t.project,
+		Task:         t.task,
+		StartedAt:    t.startTime.UTC(),
+		TimeElapsed:  int(time.Since(t.startTime).Seconds()),
+	}
+}
+
+// splitParallelTimersAtMidnight restarts the parallel timers whose day ended so sessions don't span days
+func (a *App) splitParallelTimersAtMidnight() {
+	a.parallelMu.Lock()
+	var ended []*parallelTimer
+	for _, timer := range a.parallel {
+		if dateIn(time.Now(), timer.location) != dateIn(timer.startTime, timer.location) {
+			ended = append(ended, timer)
+		}
+	}
+	a.parallelMu.Unlock()
+
+	for _, timer := range ended {
+		if err := a.StopParallelTimer(timer.project.ID); err != nil {
+			Logger.Println(err)
+			runtime.EventsEmit(a.ctx, "timer-error", toAppError(err))
+		}
+		if err := a.timerUnlocked(timer.organization.ID); err != nil {
+			runtime.EventsEmit(a.ctx, "timer-error", err)
+			continue
+		}
+		a.runParallelTimer(&parallelTimer{
+			organization: timer.organization,
+			project:      timer.project,
+			task:         timer.task,
+			startTime:    time.Now(),
+			location:     timer.location,
+		})
+	}
+}
+
+// overlapCells returns the from, to, projects and hours cells of an overlap in export tables
+func overlapCells(overlap TimerOverlap) []string {
+	return append([]string{
+		overlap.StartedAt.In(time.Local).Format("15:04"),
+		overlap.EndedAt.In(time.Local).Format("15:04"),
+		strings.Join(overlap.Projects, ", "),
+	}, hoursCells(overlap.Seconds)...)
+}
+
+type overlapSession struct {
+	Label     string
+	Date      string
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// findOverlaps returns the stretches where sessions overlap, adjacent stretches with the same projects are merged
+func findOverlaps(sessions []overlapSession) []TimerOverlap {
+	// Sessions are compared to the second, the few milliseconds between switching timers aren't an overlap
+	var timed []overlapSession
+	for _, session := range sessions {
+		session.StartedAt = session.StartedAt.Truncate(time.Second)
+		session.EndedAt = session.EndedAt.Truncate(time.Second)
+		if session.EndedAt.After(session.StartedAt) {
+			timed = append(timed, session)
+		}
+	}
+	sessions = timed
+
+	var boundaries []time.Time
+	for _, session := range sessions {
+		boundaries = append(boundaries, session.StartedAt, session.EndedAt)
+	}
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+
+	var overlaps []TimerOverlap
+	for i := 0; i+1 < len(boundaries); i++ {
+		from, to := boundaries[i], boundaries[i+1]
+		if !from.Before(to) {
+			continue
+		}
+
+		var active []overlapSession
+		for _, session := range sessions {
+			if !session.StartedAt.After(from) && !session.EndedAt.Before(to) {
+				active = append(active, session)
+			}
+		}
+		if len(active) < 2 {
+			continue
+		}
+
+		projects := make(map[string]bool)
+		for _, session := range active {
+			projects[session.Label] = true
+		}
+		var labels []string
+		for label := range projects {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		if last := len(overlaps) - 1; last >= 0 && overlaps[last].EndedAt.Equal(from) &&
+			strings.Join(overlaps[last].Projects, "\x00") == strings.Join(labels, "\x00") {
+			overlaps[last].EndedAt = to
+			overlaps[last].Seconds = int(to.Sub(overlaps[last].StartedAt).Seconds())
+			continue
+		}
+		overlaps = append(overlaps, TimerOverlap{
+			Date:      active[0].Date,
+			StartedAt: from,
+			EndedAt:   to,
+			Seconds:   int(to.Sub(from).Seconds()),
+			Projects:  labels,
+		})
+	}
+	return overlaps
+}
+
+// getOverlaps returns the overlapping sessions of the scope's projects between the dates
+func (a *App) getOverlaps(scope reportScope, startDate, endDate string) ([]TimerOverlap, error) {
+	var sessions []overlapSession
+	err := a.db.Table("work_sessions").
+		Select(scope.label+" AS label, work_sessions.date, work_sessions.started_at, work_sessions.ended_at").
+		Joins("JOIN projects ON projects.id = work_sessions.project_id").
+		Where("projects.deleted_at IS NULL AND work_sessions.deleted_at IS NULL"). // Ignore deleted projects and sessions
+		Scopes(scope.filter).
+		Where("work_sessions.date >= ? AND work_sessions.date <= ?", startDate, endDate).
+		Order("work_sessions.started_at").
+		Scan(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return findOverlaps(sessions), nil
+}
+
+// GetTimerOverlaps returns the time the organization's projects were tracked at the same time between the dates
+func (a *App) GetTimerOverlaps(startDate, endDate string, organizationID uint) ([]TimerOverlap, error) {
+	organization, err := a.getOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := a.organizationScope(organization.Name)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	overlaps, err := a.getOverlaps(scope, startDate, endDate)
+	if err != nil {
+		return nil, toAppError(err)
+	}
+	return overlaps, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// localTimezone returns the IANA name of the machine's timezone.
+// Falls back to "Local" when the name can't be resolved (e.g. on Windows)
+func localTimezone() string {
+	if name := time.Local.String(); name != "" && name != "Local" {
+		return name
+	}
+	if tz := os.Getenv("TZ"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	// /etc/localtime is usually a symlink into the zoneinfo database
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx != -1 {
+			return target[idx+len("zoneinfo/"):]
+		}
+	}
+	return "Local"
+}
+
+// loadLocation resolves a stored timezone name, treating empty or unknown names as the machine's zone
+func loadLocation(name string) *time.Location {
+	if name == "" || name == "Local" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		Logger.Println(err)
+		return time.Local
+	}
+	return loc
+}
+
+// dateIn returns the day bucket (YYYY-MM-DD) of an instant in the given location
+func dateIn(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+// reportingLocation returns the timezone used to bucket the organization's time into days
+func (a *App) reportingLocation(organizationID uint) *time.Location {
+	var organization Organization
+	if err := a.db.Unscoped().Where(&Organization{ID: organizationID}).First(&organization).Error; err != nil {
+		Logger.Println(err)
+		return time.Local
+	}
+	return loadLocation(organization.Timezone)
+}
+
+// projectLocation returns the reporting timezone of the organization owning the project
+func (a *App) projectLocation(projectID uint) *time.Location {
+	var project Project
+	if err := a.db.Unscoped().Where(&Project{ID: projectID}).First(&project).Error; err != nil {
+		Logger.Println(err)
+		return time.Local
+	}
+	return a.reportingLocation(project.OrganizationID)
+}
+
+// GetToday returns the current date in the organization's reporting timezone
+func (a *App) GetToday(organizationID uint) string {
+	return dateIn(time.Now(), a.reportingLocation(organizationID))
+}
+
+// SetOrganizationTimezone changes the timezone used to bucket the organization's time into days.
+// Existing WorkHours keep the day they were recorded under
+func (a *App) SetOrganizationTimezone(organizationID uint, timezone string) (Organization, error) {
+	if timezone == "" {
+		return Organization{}, validationError("timezone is empty")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return Organization{}, invalid(err)
+	}
+
+	organization, err := a.getOrganization(organizationID)
+	if err != nil {
+		return Organization{}, err
+	}
+
+	before := organization
+	organization.Timezone = timezone
+	if err := a.saveAudited(before, &organization); err != nil {
+		return Organization{}, err
+	}
+
+	if a.isRunning && a.organization.ID == organization.ID {
+		a.organization.Timezone = timezone
+		a.location = loadLocation(timezone)
+	}
+	return organization, nil
+}
+
+// migrateTimezones fills in timezone information for data recorded before it was tracked.
+// Existing data is assumed to have been recorded in the machine's current timezone
+func migrateTimezones(db *gorm.DB) {
+	zone := localTimezone()
+
+	err := db.Unscoped().Model(&Organization{}).
+		Where("timezone IS NULL OR timezone = ''").
+		UpdateColumn("timezone", zone).Error
+	handleDBError(err)
+
+	var workSessions []WorkSession
+	err = db.Unscoped().Where("started_at IS NULL").Find(&workSessions).Error
+	handleDBError(err)
+
+	for _, workSession := range workSessions {
+		// Sessions used to be saved when the timer stopped, so CreatedAt is the end of the session
+		endedAt := workSession.CreatedAt.UTC()
+		err := db.Unscoped().Model(&workSession).UpdateColumns(map[string]interface{}{
+			"started_at": endedAt.Add(-time.Duration(workSession.Seconds) * time.Second),
+			"ended_at":   endedAt,
+			"timezone":   zone,
+		}).Error
+		handleDBError(err)
+	}
+	if len(workSessions) > 0 {
+		Logger.Printf("Migrated %d work sessions to timezone %s\n", len(workSessions), zone)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Workspaces
+//
+// A workspace is a separate set of data with its own database, settings and export tree, e.g. "personal" and
+// "agency". The default workspace lives in the save directory itself so existing data stays where it is,
+// the others in workspaces/<name>. The active workspace is remembered in workspaces.json.
+
+const (
+	defaultWorkspace   = "default"
+	workspacesDirName  = "workspaces"
+	workspacesFileName = "workspaces.json"
+)
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _-]{0,39}$`)
+
+// Workspace is a named set of data
+type Workspace struct {
+	Name   string `json:"name"`
+	Dir    string `json:"dir"`
+	Active bool   `json:"active"`
+}
+
+type workspacesFile struct {
+	Active string `json:"active"`
+}
+
+func workspaceDir(saveDir, name string) string {
+	if name == defaultWorkspace {
+		return saveDir
+	}
+	return filepath.Join(saveDir, workspacesDirName, name)
+}
+
+// activeWorkspace returns the workspace used last, the default one when it is gone
+func activeWorkspace(saveDir string) string {
+	data, err := os.ReadFile(filepath.Join(saveDir, workspacesFileName))
+	if err != nil {
+		return defaultWorkspace
+	}
+	var file workspacesFile
+	if json.Unmarshal(data, &file) != nil || file.Active == "" {
+		return defaultWorkspace
+	}
+	if _, err := os.Stat(workspaceDir(saveDir, file.Active)); err != nil {
+		return defaultWorkspace
+	}
+	return file.Active
+}
+
+func saveActiveWorkspace(saveDir, name string) error {
+	data, err := json.MarshalIndent(workspacesFile{Active: name}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(saveDir, workspacesFileName), data)
+}
+
+func (a *App) workspace(name string) Workspace {
+	return Workspace{Name: name, Dir: workspaceDir(a.saveDir, name), Active: name == a.workspaceName}
+}
+
+// GetWorkspaces returns the default workspace followed by the others by name
+func (a *App) GetWorkspaces() ([]Workspace, error) {
+	workspaces := []Workspace{a.workspace(defaultWorkspace)}
+	entries, err := os.ReadDir(filepath.Join(a.saveDir, workspacesDirName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, toAppError(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && workspaceNamePattern.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		workspaces = append(workspaces, a.workspace(name))
+	}
+	return workspaces, nil
+}
+
+// CreateWorkspace adds an empty workspace, it is set up when first switched to
+func (a *App) CreateWorkspace(name string) (Workspace, error) {
+	if !workspaceNamePattern.MatchString(name) || name == defaultWorkspace {
+		return Workspace{}, validationError("a workspace name has up to 40 letters, digits, spaces, dashes or underscores")
+	}
+	dir := workspaceDir(a.saveDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return Workspace{}, conflictError(fmt.Sprintf("workspace %q already exists", name))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Workspace{}, toAppError(err)
+	}
+	return a.workspace(name), nil
+}
+
+// SwitchWorkspace stops the timers, closes the current database and opens the workspace's one.
+// An encrypted workspace is locked until it is unlocked
+func (a *App) SwitchWorkspace(name string) (EncryptionStatus, error) {
+	if name == a.workspaceName {
+		return a.GetEncryptionStatus(), nil
+	}
+	dir := workspaceDir(a.saveDir, name)
+	if _, err := os.Stat(dir); err != nil {
+		return EncryptionStatus{}, notFoundWorkspace(name)
+	}
+
+	a.dbMu.Lock()
+	if err := a.releaseDatabase(); err != nil {
+		a.dbMu.Unlock()
+		return EncryptionStatus{}, toAppError(err)
+	}
+	a.workspaceName, a.dbDir = name, dir
+	a.openErr = nil
+	if err := saveActiveWorkspace(a.saveDir, name); err != nil {
+		Logger.Println("Failed to remember the active workspace:", err)
+	}
+	err := a.openDatabase("")
+	a.dbMu.Unlock()
+	if err != nil {
+		a.openErr = err
+	} else if a.ctx != nil {
+		a.startRoutines()
+	}
+	return a.GetEncryptionStatus(), nil
+}
+
+// releaseDatabase stops the timers and closes the database, before another database takes its place
+func (a *App) releaseDatabase() error {
+	if a.db != nil {
+		if a.isRunning {
+			if err := a.StopTimer(); err != nil {
+				return err
+			}
+		}
+		a.stopParallelTimers()
+		a.closeDatabase()
+	}
+	a.organization, a.project, a.task = Organization{}, Project{}, Task{}
+	a.timerStack = nil
+	a.complianceWarned = nil
+	return nil
+}
+
+// DeleteWorkspace removes a workspace with all its data, the default and the active workspace can't be deleted
+func (a *App) DeleteWorkspace(name string) error {
+	if name == defaultWorkspace || name == a.workspaceName {
+		return conflictError("the default and the active workspace can't be deleted")
+	}
+	dir := workspaceDir(a.saveDir, name)
+	if _, err := os.Stat(dir); err != nil || !workspaceNamePattern.MatchString(name) {
+		return notFoundWorkspace(name)
+	}
+	if err := keyringDelete(dir); err != nil {
+		Logger.Println("Failed to remove the workspace key from the keyring:", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return toAppError(err)
+	}
+	return nil
+}
+
+func notFoundWorkspace(name string) *AppError {
+	return &AppError{Code: ErrNotFound, Message: fmt.Sprintf("workspace %q not found", name)}
+}
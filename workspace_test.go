@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSwitchWorkspaceWhileRoutinesRun(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.CreateWorkspace("Client"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ticks of the background routines run alongside the switches, run with -race to check the handle
+	var ticks atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				a.withDb(func() {
+					var count int64
+					if err := a.db.Model(&Organization{}).Count(&count).Error; err != nil {
+						t.Error(err)
+					}
+					ticks.Add(1)
+				})
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		name := "Client"
+		if i%2 == 1 {
+			name = defaultWorkspace
+		}
+		if _, err := a.SwitchWorkspace(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if ticks.Load() == 0 {
+		t.Error("no tick ran")
+	}
+	if a.workspaceName != defaultWorkspace || a.db == nil {
+		t.Errorf("workspace %q, database open %v", a.workspaceName, a.db != nil)
+	}
+}
+
+func TestSwitchWorkspaceToBrokenDatabase(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.CreateWorkspace("Broken"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(workspaceDir(a.saveDir, "Broken"), dbFileName)
+	if err := os.WriteFile(path, []byte("this is not a database, it can't be migrated"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The app stays up and reports why the workspace couldn't be opened
+	status, err := a.SwitchWorkspace("Broken")
+	if err != nil || status.Error == "" || a.db != nil {
+		t.Fatalf("switching to a broken database: %+v, %v", status, err)
+	}
+	if status, err := a.SwitchWorkspace(defaultWorkspace); err != nil || status.Error != "" || a.db == nil {
+		t.Errorf("switching back: %+v, %v", status, err)
+	}
+}